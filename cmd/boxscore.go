@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"nba-scanner/internal/logic"
+
+	"github.com/spf13/cobra"
+)
+
+var boxscoreCmd = &cobra.Command{
+	Use:   "boxscore <gameId>",
+	Short: "顯示單場比賽的各節比分與球員數據",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a := newApp()
+		defer a.close()
+
+		ctx, cancel := a.cliContext()
+		defer cancel()
+		return cliError(ctx, logic.ShowBoxscore(ctx, a.src, args[0], a.format))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(boxscoreCmd)
+}
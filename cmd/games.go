@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"nba-scanner/internal/logic"
+
+	"github.com/spf13/cobra"
+)
+
+var gamesQuery logic.GamesQuery
+
+var gamesCmd = &cobra.Command{
+	Use:   "games",
+	Short: "顯示比賽日的比賽（比分、盤口、結算、傷兵）",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a := newApp()
+		defer a.close()
+
+		ctx, cancel := a.cliContext()
+		defer cancel()
+		q := gamesQuery
+		q.Season = seasonFlag
+		return cliError(ctx, logic.ShowGames(ctx, a.src, a.rs, a.injuries, q, a.loc, a.format))
+	},
+}
+
+func init() {
+	gamesCmd.Flags().StringVarP(&gamesQuery.Date, "date", "d", "", "比賽日（美東日期 YYYY-MM-DD，空字串表示目前比賽日）")
+	gamesCmd.Flags().StringVarP(&gamesQuery.Team, "team", "t", "", "只顯示指定球隊（三碼縮寫、英文或中文隊名）")
+	gamesCmd.Flags().StringVarP(&gamesQuery.Status, "status", "", "", "只顯示指定狀態的比賽（live、final、upcoming）")
+	rootCmd.AddCommand(gamesCmd)
+}
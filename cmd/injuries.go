@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"nba-scanner/internal/logic"
+
+	"github.com/spf13/cobra"
+)
+
+var injuriesCmd = &cobra.Command{
+	Use:   "injuries [team]",
+	Short: "顯示傷兵名單（不指定球隊時顯示所有球隊）",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a := newApp()
+		defer a.close()
+
+		ctx, cancel := a.cliContext()
+		defer cancel()
+		team := ""
+		if len(args) > 0 {
+			team = args[0]
+		}
+		return cliError(ctx, logic.ShowInjuries(ctx, a.src, a.injuries, team, a.format))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(injuriesCmd)
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"nba-scanner/internal/logic"
+
+	"github.com/spf13/cobra"
+)
+
+var oddsBook string
+
+var oddsCmd = &cobra.Command{
+	Use:   "odds",
+	Short: "顯示目前比賽日各家莊家的盤口",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a := newApp()
+		defer a.close()
+
+		ctx, cancel := a.cliContext()
+		defer cancel()
+		return cliError(ctx, logic.ShowOdds(ctx, a.src, a.rs, a.injuries, oddsBook, a.loc, a.format))
+	},
+}
+
+func init() {
+	oddsCmd.Flags().StringVarP(&oddsBook, "book", "b", "", "只顯示指定莊家（莊家 ID 或名稱，不分大小寫）")
+	rootCmd.AddCommand(oddsCmd)
+}
@@ -1,43 +1,252 @@
-// ============ cmd/root.go ============
 package cmd
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"nba-scanner/internal/crawler"
+	"nba-scanner/internal/gametime"
 	"nba-scanner/internal/logic"
+	"nba-scanner/internal/render"
+	"nba-scanner/internal/season"
 	"nba-scanner/internal/server"
+	"nba-scanner/internal/slate"
+	"nba-scanner/internal/store"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
 var (
-	startTime  string
-	serverMode bool
-	port       int
+	startTime   string
+	serverMode  bool
+	port        int
+	fixturesDir string
+	recordDir   string
+	dbPath      string
+	seasonFlag  string
+	tzFlag      string
+	slateDate   string
+	slateHold   time.Duration
+	outputFlag  string
+
+	upstreamTimeout time.Duration
+	upstreamRetries int
+	upstreamPerHost int
+	requestTimeout  time.Duration
+
+	cacheLiveTTL     time.Duration
+	cacheUpcomingTTL time.Duration
+	cacheFinalTTL    time.Duration
+
+	oddsInterval   time.Duration
+	injuryInterval time.Duration
+	steamThreshold float64
+	steamWindow    time.Duration
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "nba-scan",
 	Short: "NBA 資訊掃描工具",
-	Run: func(cmd *cobra.Command, args []string) {
+	Long:  "NBA 資訊掃描工具：不指定子指令時顯示目前比賽日的所有比賽（可用 --time 篩選開賽時間）",
+	// 執行期間的錯誤（抓取失敗、找不到球隊等）不需要顯示用法
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a := newApp()
+		defer a.close()
+
 		if serverMode {
-			// 啟動 Web Server
-			if err := server.Start(port); err != nil {
-				log.Fatalf("啟動 server 失敗: %v", err)
-			}
-		} else {
-			// CLI 模式
-			if startTime != "" {
-				logic.PKTeamOnStartTime(startTime)
-			} else {
-				logic.PKTeam()
-			}
+			return a.serve()
 		}
+
+		ctx, cancel := a.cliContext()
+		defer cancel()
+		if startTime != "" {
+			return cliError(ctx, logic.PKTeamOnStartTime(ctx, a.src, a.rs, a.injuries, startTime, a.loc, a.format))
+		}
+		return cliError(ctx, logic.PKTeam(ctx, a.src, a.rs, a.injuries, a.loc, a.format))
 	},
 }
 
+// app 各子指令共用的執行環境（依全域 flag 建立）
+type app struct {
+	ctx  context.Context
+	stop context.CancelFunc
+	src  *crawler.Sources
+	db   *store.Store
+	rs   *slate.Resolver
+	loc  *time.Location
+
+	injuries *logic.InjuryTracker // 傷兵名單變動（有資料庫時保存在資料庫）
+
+	format render.Format // --output
+}
+
+// newApp 檢查全域 flag 並建立資料來源、資料庫與比賽日判斷器
+func newApp() *app {
+	if seasonFlag != "" {
+		if _, err := season.Parse(seasonFlag); err != nil {
+			log.Fatalf("--season: %v", err)
+		}
+	}
+
+	loc, err := gametime.LoadZone(tzFlag)
+	if err != nil {
+		log.Fatalf("--tz: %v", err)
+	}
+
+	format, err := render.ParseFormat(outputFlag)
+	if err != nil {
+		log.Fatalf("--output: %v", err)
+	}
+
+	// Ctrl+C / SIGTERM 時取消進行中的抓取（server 模式則關閉 server）
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	a := &app{ctx: ctx, stop: stop, src: newSources(), loc: loc, format: format}
+
+	// 保存抓到的資料（錄製資料重播時不寫入，避免影響重播結果）
+	if dbPath != "" && fixturesDir == "" {
+		db, err := store.Open(dbPath)
+		if err != nil {
+			log.Printf("資料庫無法使用，改為不保存資料: %v", err)
+		} else {
+			a.db = db
+			a.src = store.Persist(a.src, db)
+		}
+	}
+
+	a.injuries = logic.NewInjuryTracker(a.db)
+
+	// 目前比賽日的判斷（CLI 與 server 共用）
+	a.rs, err = logic.NewSlateResolver(a.src, slate.Config{Hold: slateHold, Override: slateDate})
+	if err != nil {
+		a.close()
+		log.Fatalf("--slate-date: %v", err)
+	}
+	return a
+}
+
+// close 關閉資料庫並停止接收中斷訊號
+func (a *app) close() {
+	if a.db != nil {
+		a.db.Close()
+	}
+	a.stop()
+}
+
+// cliContext CLI 模式整次執行套用請求期限
+func (a *app) cliContext() (context.Context, context.CancelFunc) {
+	if requestTimeout > 0 {
+		return context.WithTimeout(a.ctx, requestTimeout)
+	}
+	return context.WithCancel(a.ctx)
+}
+
+// serve 啟動 Web Server，直到收到中斷訊號
+func (a *app) serve() error {
+	cfg := server.Config{
+		Port:             port,
+		Store:            a.db,
+		OddsPollInterval: oddsInterval,
+		InjuryInterval:   injuryInterval,
+		Injuries:         a.injuries,
+		Steam: logic.SteamConfig{
+			Threshold: steamThreshold,
+			Window:    steamWindow,
+		},
+		Season:         seasonFlag,
+		Location:       a.loc,
+		Slate:          a.rs,
+		RequestTimeout: requestTimeout,
+		Cache: server.CacheConfig{
+			LiveTTL:     cacheLiveTTL,
+			UpcomingTTL: cacheUpcomingTTL,
+			FinalTTL:    cacheFinalTTL,
+		},
+	}
+	if err := server.Start(a.ctx, cfg, a.src); err != nil {
+		return fmt.Errorf("啟動 server 失敗: %w", err)
+	}
+	return nil
+}
+
+// cliError 將子指令的錯誤轉為 CLI 訊息（使用者中斷或逾時時顯示「已取消」）
+func cliError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("已取消： %w", ctxErr)
+	}
+	return err
+}
+
+// newSources 根據 flag 決定使用即時 API、錄製模式或錄製資料
+func newSources() *crawler.Sources {
+	if fixturesDir != "" && recordDir != "" {
+		log.Fatal("--fixtures 與 --record 不能同時使用")
+	}
+	if fixturesDir != "" {
+		log.Printf("使用錄製資料: %s", fixturesDir)
+		return crawler.NewFixtureSources(fixturesDir)
+	}
+	if recordDir != "" {
+		src, err := crawler.NewRecordingSources(recordDir, upstreamConfig())
+		if err != nil {
+			log.Fatalf("啟動錄製模式失敗: %v", err)
+		}
+		log.Printf("錄製上游回應至: %s", recordDir)
+		return src
+	}
+	return crawler.NewLiveSources(upstreamConfig())
+}
+
+// upstreamConfig 上游 HTTP 連線設定
+func upstreamConfig() crawler.UpstreamConfig {
+	retries := upstreamRetries
+	if retries == 0 {
+		retries = -1 // --upstream-retries 0 表示不重試（UpstreamConfig 的 0 代表預設值）
+	}
+	perHost := upstreamPerHost
+	if perHost == 0 {
+		perHost = -1 // --upstream-max-per-host 0 表示不限制
+	}
+	return crawler.UpstreamConfig{
+		Timeout:    upstreamTimeout,
+		Retries:    retries,
+		MaxPerHost: perHost,
+	}
+}
+
 func Execute() {
-	rootCmd.PersistentFlags().StringVarP(&startTime, "time", "", "", "指定時間 (格式: 15:04)")
-	rootCmd.PersistentFlags().BoolVarP(&serverMode, "server", "s", false, "啟動 Web Server 模式")
+	rootCmd.Flags().StringVarP(&startTime, "time", "", "", "指定時間 (格式: 15:04)")
+	rootCmd.Flags().BoolVarP(&serverMode, "server", "s", false, "啟動 Web Server 模式")
+	rootCmd.Flags().MarkDeprecated("server", "請改用 serve 子指令")
 	rootCmd.PersistentFlags().IntVarP(&port, "port", "p", 8080, "Web Server 埠號")
-	rootCmd.Execute()
+	rootCmd.PersistentFlags().StringVarP(&fixturesDir, "fixtures", "", "", "從指定目錄讀取錄製的上游資料（離線模式）")
+	rootCmd.PersistentFlags().StringVarP(&recordDir, "record", "", "", "將所有上游回應錄製到指定目錄（可用 --fixtures 重播）")
+	rootCmd.PersistentFlags().StringVarP(&dbPath, "db", "", "", "資料庫檔案路徑（保存賽程、比分、賠率快照、傷兵與盤口，未指定時不保存）")
+	rootCmd.PersistentFlags().StringVarP(&seasonFlag, "season", "", "", "預設賽季（如 2024-25，空字串表示依日期自動判斷）")
+	rootCmd.PersistentFlags().StringVarP(&tzFlag, "tz", "", gametime.DefaultZone, "顯示時區（IANA 名稱，如 America/New_York；server 模式可用 ?tz= 覆寫）")
+	rootCmd.PersistentFlags().StringVarP(&slateDate, "slate-date", "", "", "固定目前比賽日（美東日期 YYYY-MM-DD，空字串表示依賽程自動判斷）")
+	rootCmd.PersistentFlags().StringVarP(&outputFlag, "output", "o", string(render.FormatText), "輸出格式：text、json（與 /api/games 相同）、csv、markdown、table")
+	rootCmd.PersistentFlags().DurationVarP(&slateHold, "slate-hold", "", slate.DefaultHold, "前一個比賽日最後一場打完後，繼續顯示的時間")
+	rootCmd.PersistentFlags().DurationVarP(&upstreamTimeout, "upstream-timeout", "", 0, "上游請求逾時（0 表示依來源使用預設值：賽程 20s、titan007/傷兵 10s、賠率/boxscore 8s）")
+	rootCmd.PersistentFlags().IntVarP(&upstreamRetries, "upstream-retries", "", crawler.DefaultRetries, "上游 5xx 或逾時的重試次數")
+	rootCmd.PersistentFlags().IntVarP(&upstreamPerHost, "upstream-max-per-host", "", crawler.DefaultMaxPerHost, "同一上游主機同時進行的請求數（0 表示不限制）")
+	rootCmd.PersistentFlags().DurationVarP(&requestTimeout, "request-timeout", "", time.Minute, "整體請求期限（CLI 為單次執行，server 為每次 /api/games 請求；0 表示不限制）")
+	rootCmd.PersistentFlags().DurationVarP(&cacheLiveTTL, "cache-live-ttl", "", server.DefaultLiveTTL, "Server 模式 /api/games 在比賽進行中（或有來源失敗）時的快取時間（負數表示不快取）")
+	rootCmd.PersistentFlags().DurationVarP(&cacheUpcomingTTL, "cache-upcoming-ttl", "", server.DefaultUpcomingTTL, "Server 模式 /api/games 在還有比賽未開打時的快取時間")
+	rootCmd.PersistentFlags().DurationVarP(&cacheFinalTTL, "cache-final-ttl", "", server.DefaultFinalTTL, "Server 模式 /api/games 在比賽日全部打完時的快取時間")
+	rootCmd.PersistentFlags().DurationVarP(&oddsInterval, "odds-interval", "", 2*time.Minute, "Server 模式的賠率輪詢間隔（0 表示不輪詢）")
+	rootCmd.PersistentFlags().DurationVarP(&injuryInterval, "injury-interval", "", 5*time.Minute, "Server 模式的傷兵名單輪詢間隔（0 表示不輪詢，只在請求時比對）")
+	rootCmd.PersistentFlags().Float64VarP(&steamThreshold, "steam-threshold", "", logic.DefaultSteamConfig.Threshold, "急速變盤門檻（分）")
+	rootCmd.PersistentFlags().DurationVarP(&steamWindow, "steam-window", "", logic.DefaultSteamConfig.Window, "急速變盤的時間窗")
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
 }
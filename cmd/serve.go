@@ -0,0 +1,18 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "啟動 Web Server（/api/games 與網頁）",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a := newApp()
+		defer a.close()
+		return a.serve()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"nba-scanner/internal/logic"
+
+	"github.com/spf13/cobra"
+)
+
+var teamGames int
+
+var teamCmd = &cobra.Command{
+	Use:   "team <name|tricode>",
+	Short: "顯示球隊近期戰績與過盤紀錄",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a := newApp()
+		defer a.close()
+
+		ctx, cancel := a.cliContext()
+		defer cancel()
+		return cliError(ctx, logic.ShowTeam(ctx, a.src, a.rs, args[0], seasonFlag, teamGames, a.loc, a.format))
+	},
+}
+
+func init() {
+	teamCmd.Flags().IntVarP(&teamGames, "games", "n", 10, "顯示最近幾場比賽")
+	rootCmd.AddCommand(teamCmd)
+}
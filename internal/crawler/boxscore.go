@@ -1,32 +1,33 @@
 package crawler
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"nba-scanner/internal/models"
 )
 
-// FetchBoxscore 抓取比賽的 boxscore 數據
-func FetchBoxscore(gameID string) (*models.BoxscoreResponse, error) {
-	url := fmt.Sprintf("https://cdn.nba.com/static/json/liveData/boxscore/boxscore_%s.json", gameID)
-
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch boxscore: %w", err)
-	}
-	defer resp.Body.Close()
+// boxscoreURL 取得單場 boxscore 的 URL
+func boxscoreURL(gameID string) string {
+	return fmt.Sprintf("https://cdn.nba.com/static/json/liveData/boxscore/boxscore_%s.json", gameID)
+}
 
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("boxscore API returned status %d", resp.StatusCode)
-	}
+// httpBoxscoreSource 從 NBA CDN 抓取 boxscore
+type httpBoxscoreSource struct {
+	up *upstream
+}
 
-	body, err := io.ReadAll(resp.Body)
+// FetchBoxscore 抓取比賽的 boxscore 數據
+func (s *httpBoxscoreSource) FetchBoxscore(ctx context.Context, gameID string) (*models.BoxscoreResponse, error) {
+	body, err := s.up.fetchBody(ctx, upstreamRequest{source: SourceBoxscore, label: "boxscore", url: boxscoreURL(gameID)})
 	if err != nil {
-		return nil, fmt.Errorf("failed to read boxscore response: %w", err)
+		return nil, err
 	}
+	return parseBoxscore(body)
+}
 
+// parseBoxscore 解析 boxscore JSON
+func parseBoxscore(body []byte) (*models.BoxscoreResponse, error) {
 	var boxscore models.BoxscoreResponse
 	if err := json.Unmarshal(body, &boxscore); err != nil {
 		return nil, fmt.Errorf("failed to parse boxscore JSON: %w", err)
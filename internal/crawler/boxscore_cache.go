@@ -0,0 +1,170 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"nba-scanner/internal/models"
+	"sync"
+	"time"
+)
+
+const (
+	// liveBoxscoreTTL 進行中比賽的 boxscore 快取時間（比分每幾秒才會更新）
+	liveBoxscoreTTL = 10 * time.Second
+	// maxFinalBoxscores 記憶體中保留的已結束比賽 boxscore 數量（更早的由資料庫保存）
+	maxFinalBoxscores = 500
+)
+
+// boxscoreEntry 快取的 boxscore
+type boxscoreEntry struct {
+	boxscore *models.BoxscoreResponse
+	expires  time.Time // 零值表示已結束的比賽，不會過期
+}
+
+// boxscoreCall 進行中的 boxscore 抓取（同一場比賽同時只抓一次）
+type boxscoreCall struct {
+	done     chan struct{}
+	boxscore *models.BoxscoreResponse
+	err      error
+}
+
+// boxscoreCache 包裝 boxscore 來源
+// 已結束的比賽 boxscore 不會再變動，永久保留；進行中的比賽只快取幾秒
+type boxscoreCache struct {
+	BoxscoreSource
+
+	mu       sync.Mutex
+	entries  map[string]boxscoreEntry
+	finals   []string // 已結束比賽的加入順序（超過上限時移除最舊的）
+	inflight map[string]*boxscoreCall
+}
+
+// newBoxscoreCache 建立 boxscore 快取
+func newBoxscoreCache(src BoxscoreSource) *boxscoreCache {
+	return &boxscoreCache{
+		BoxscoreSource: src,
+		entries:        make(map[string]boxscoreEntry),
+		inflight:       make(map[string]*boxscoreCall),
+	}
+}
+
+// WrapBoxscoreUpstream 在 boxscore 快取之下加上一層包裝（只有快取未命中、真正向上游抓取時才會經過）
+// src 不是快取時直接包裝
+func WrapBoxscoreUpstream(src BoxscoreSource, wrap func(BoxscoreSource) BoxscoreSource) BoxscoreSource {
+	if c, ok := src.(*boxscoreCache); ok {
+		return newBoxscoreCache(wrap(c.BoxscoreSource))
+	}
+	return wrap(src)
+}
+
+// FetchBoxscore 取得 boxscore：同一次請求內重複使用，其次使用快取，都沒有時才向上游抓取
+func (c *boxscoreCache) FetchBoxscore(ctx context.Context, gameID string) (*models.BoxscoreResponse, error) {
+	memo := boxscoreMemoFrom(ctx)
+	if boxscore, ok := memo.get(gameID); ok {
+		return boxscore, nil
+	}
+
+	for {
+		c.mu.Lock()
+		if entry, ok := c.entries[gameID]; ok && (entry.expires.IsZero() || time.Now().Before(entry.expires)) {
+			c.mu.Unlock()
+			memo.set(gameID, entry.boxscore)
+			return entry.boxscore, nil
+		}
+		call, ok := c.inflight[gameID]
+		if !ok {
+			call = &boxscoreCall{done: make(chan struct{})}
+			c.inflight[gameID] = call
+			c.mu.Unlock()
+
+			c.fetch(ctx, gameID, call)
+			memo.set(gameID, call.boxscore)
+			return call.boxscore, call.err
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		// 負責抓取的請求被取消時，由目前的請求重新抓取
+		if call.err != nil && (errors.Is(call.err, context.Canceled) || errors.Is(call.err, context.DeadlineExceeded)) && ctx.Err() == nil {
+			continue
+		}
+		memo.set(gameID, call.boxscore)
+		return call.boxscore, call.err
+	}
+}
+
+// fetch 向上游抓取並寫入快取
+func (c *boxscoreCache) fetch(ctx context.Context, gameID string, call *boxscoreCall) {
+	defer close(call.done)
+
+	call.boxscore, call.err = c.BoxscoreSource.FetchBoxscore(ctx, gameID)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.inflight, gameID)
+	if call.err != nil {
+		return
+	}
+
+	if call.boxscore.Game.GameStatus != 3 {
+		c.entries[gameID] = boxscoreEntry{boxscore: call.boxscore, expires: time.Now().Add(liveBoxscoreTTL)}
+		return
+	}
+
+	if old, ok := c.entries[gameID]; !ok || !old.expires.IsZero() {
+		c.finals = append(c.finals, gameID)
+	}
+	c.entries[gameID] = boxscoreEntry{boxscore: call.boxscore}
+	for len(c.finals) > maxFinalBoxscores {
+		delete(c.entries, c.finals[0])
+		c.finals = c.finals[1:]
+	}
+}
+
+// boxscoreMemo 單次請求內已取得的 boxscore（賽程與比賽資訊都會用到同一場的 boxscore）
+type boxscoreMemo struct {
+	mu        sync.Mutex
+	boxscores map[string]*models.BoxscoreResponse
+}
+
+type boxscoreMemoKey struct{}
+
+// WithBoxscoreMemo 回傳帶有單次請求 boxscore 紀錄的 context
+// 同一個 context 內每場比賽的 boxscore 只會抓取一次（即使進行中比賽的快取在請求途中過期）
+func WithBoxscoreMemo(ctx context.Context) context.Context {
+	if boxscoreMemoFrom(ctx) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, boxscoreMemoKey{}, &boxscoreMemo{boxscores: make(map[string]*models.BoxscoreResponse)})
+}
+
+// boxscoreMemoFrom 取得 context 中的 boxscore 紀錄（沒有時回傳 nil）
+func boxscoreMemoFrom(ctx context.Context) *boxscoreMemo {
+	memo, _ := ctx.Value(boxscoreMemoKey{}).(*boxscoreMemo)
+	return memo
+}
+
+// get 取得已紀錄的 boxscore（m 為 nil 時視為沒有）
+func (m *boxscoreMemo) get(gameID string) (*models.BoxscoreResponse, bool) {
+	if m == nil {
+		return nil, false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	boxscore, ok := m.boxscores[gameID]
+	return boxscore, ok
+}
+
+// set 紀錄 boxscore（m 為 nil 或 boxscore 為 nil 時不紀錄）
+func (m *boxscoreMemo) set(gameID string, boxscore *models.BoxscoreResponse) {
+	if m == nil || boxscore == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.boxscores[gameID] = boxscore
+}
@@ -0,0 +1,71 @@
+package crawler
+
+import (
+	"context"
+	"nba-scanner/internal/models"
+	"sync"
+	"testing"
+)
+
+// countingBoxscore 記錄每場比賽被抓取的次數
+type countingBoxscore struct {
+	mu     sync.Mutex
+	status int
+	calls  map[string]int
+}
+
+func (c *countingBoxscore) FetchBoxscore(ctx context.Context, gameID string) (*models.BoxscoreResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.calls == nil {
+		c.calls = make(map[string]int)
+	}
+	c.calls[gameID]++
+	boxscore := &models.BoxscoreResponse{}
+	boxscore.Game.GameID = gameID
+	boxscore.Game.GameStatus = c.status
+	return boxscore, nil
+}
+
+func TestWrapBoxscoreUpstreamOnlySeesCacheMisses(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		wantCalls int
+	}{
+		{"進行中比賽在快取時間內只抓一次", 2, 1},
+		{"已結束比賽只抓一次", 3, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := &countingBoxscore{status: tt.status}
+			wrapped := &countingBoxscore{status: tt.status}
+			src := WrapBoxscoreUpstream(newBoxscoreCache(raw), func(upstream BoxscoreSource) BoxscoreSource {
+				return &passThroughBoxscore{BoxscoreSource: upstream, seen: wrapped}
+			})
+
+			for i := 0; i < 3; i++ {
+				if _, err := src.FetchBoxscore(context.Background(), "0022500510"); err != nil {
+					t.Fatalf("抓取失敗: %v", err)
+				}
+			}
+			if got := wrapped.calls["0022500510"]; got != tt.wantCalls {
+				t.Errorf("包裝層被呼叫 %d 次，預期 %d", got, tt.wantCalls)
+			}
+			if got := raw.calls["0022500510"]; got != tt.wantCalls {
+				t.Errorf("上游被呼叫 %d 次，預期 %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// passThroughBoxscore 記錄經過的請求後交給內層
+type passThroughBoxscore struct {
+	BoxscoreSource
+	seen *countingBoxscore
+}
+
+func (p *passThroughBoxscore) FetchBoxscore(ctx context.Context, gameID string) (*models.BoxscoreResponse, error) {
+	p.seen.FetchBoxscore(ctx, gameID)
+	return p.BoxscoreSource.FetchBoxscore(ctx, gameID)
+}
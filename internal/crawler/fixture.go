@@ -0,0 +1,170 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"nba-scanner/internal/models"
+	"nba-scanner/internal/season"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// 錄製資料的檔名（與上游 URL 的檔名一致）
+//
+//	DIR/todaysScoreboard_00.json
+//	DIR/scheduleLeagueV2_9.json
+//	DIR/scheduleLeagueV2_<season>.json（過去賽季，如 scheduleLeagueV2_2024-25.json）
+//	DIR/odds_todaysGames.json
+//	DIR/boxscore_<gameId>.json
+//	DIR/injuries.html
+//	DIR/HandicapDetail_<titan007TeamId>.html
+//	DIR/l1.js
+const (
+	fixtureTodaysScoreboard = "todaysScoreboard_00.json"
+	fixtureFullSchedule     = "scheduleLeagueV2_9.json"
+	fixtureOdds             = "odds_todaysGames.json"
+	fixtureInjuries         = "injuries.html"
+	fixtureLetGoal          = "l1.js"
+)
+
+// fixtureBoxscore 單場 boxscore 的檔名
+func fixtureBoxscore(gameID string) string {
+	return fmt.Sprintf("boxscore_%s.json", gameID)
+}
+
+// fixtureSeasonSchedule 指定賽季完整賽程的檔名
+func fixtureSeasonSchedule(s season.Season) string {
+	return fmt.Sprintf("scheduleLeagueV2_%s.json", s)
+}
+
+// fixtureHandicapDetail 球隊 HandicapDetail 頁面的檔名
+func fixtureHandicapDetail(teamID int) string {
+	return fmt.Sprintf("HandicapDetail_%d.html", teamID)
+}
+
+// fixtureDir 錄製資料目錄
+type fixtureDir struct {
+	dir string
+}
+
+// read 讀取目錄中的檔案
+func (f *fixtureDir) read(name string) ([]byte, error) {
+	body, err := os.ReadFile(filepath.Join(f.dir, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture %s: %w", name, err)
+	}
+	return body, nil
+}
+
+// fixtureScheduleSource 從錄製資料讀取賽程
+type fixtureScheduleSource struct {
+	*fixtureDir
+}
+
+// FetchTodayScoreboard 讀取今日記分板
+func (s *fixtureScheduleSource) FetchTodayScoreboard(ctx context.Context) (*models.NBAScoreboard, error) {
+	body, err := s.read(fixtureTodaysScoreboard)
+	if err != nil {
+		return nil, err
+	}
+	return parseScoreboard(body)
+}
+
+// FetchFullSchedule 讀取完整賽季賽程
+func (s *fixtureScheduleSource) FetchFullSchedule(ctx context.Context) (*models.FullSchedule, error) {
+	body, err := s.read(fixtureFullSchedule)
+	if err != nil {
+		return nil, err
+	}
+	return parseFullSchedule(body)
+}
+
+// FetchSeasonSchedule 讀取指定賽季的完整賽程
+// 沒有該賽季的檔案時，若 scheduleLeagueV2_9.json 正好是該賽季則使用它
+func (s *fixtureScheduleSource) FetchSeasonSchedule(ctx context.Context, sn season.Season) (*models.FullSchedule, error) {
+	body, err := s.read(fixtureSeasonSchedule(sn))
+	if err == nil {
+		return parseFullSchedule(body)
+	}
+
+	schedule, fullErr := s.FetchFullSchedule(ctx)
+	if fullErr != nil {
+		return nil, err
+	}
+	if got, ok := scheduleSeason(schedule); ok && got == sn {
+		return schedule, nil
+	}
+	return nil, err
+}
+
+// fixtureOddsSource 從錄製資料讀取賠率
+type fixtureOddsSource struct {
+	*fixtureDir
+}
+
+// FetchOdds 讀取今日賠率
+func (s *fixtureOddsSource) FetchOdds(ctx context.Context) (*models.NBAOdds, error) {
+	body, err := s.read(fixtureOdds)
+	if err != nil {
+		return nil, err
+	}
+	return parseOdds(body)
+}
+
+// fixtureBoxscoreSource 從錄製資料讀取 boxscore
+type fixtureBoxscoreSource struct {
+	*fixtureDir
+}
+
+// FetchBoxscore 讀取單場 boxscore
+func (s *fixtureBoxscoreSource) FetchBoxscore(ctx context.Context, gameID string) (*models.BoxscoreResponse, error) {
+	body, err := s.read(fixtureBoxscore(gameID))
+	if err != nil {
+		return nil, err
+	}
+	return parseBoxscore(body)
+}
+
+// fixtureInjurySource 從錄製資料讀取傷兵名單
+type fixtureInjurySource struct {
+	*fixtureDir
+}
+
+// FetchInjuryMap 讀取 ESPN 傷兵頁面
+func (s *fixtureInjurySource) FetchInjuryMap(ctx context.Context) (map[string][]models.Injury, error) {
+	body, err := s.read(fixtureInjuries)
+	if err != nil {
+		return nil, err
+	}
+	// 以錄製時間（檔案修改時間）推算日期年份，重播舊資料時才不會跑到隔年
+	recordedAt := time.Now()
+	if info, err := os.Stat(filepath.Join(s.dir, fixtureInjuries)); err == nil {
+		recordedAt = info.ModTime()
+	}
+	return parseInjuryMap(bytes.NewReader(body), recordedAt)
+}
+
+// fixtureHandicapSource 從錄製資料讀取 titan007 盤口
+type fixtureHandicapSource struct {
+	*fixtureDir
+}
+
+// FetchHandicapDetail 讀取球隊 HandicapDetail 頁面（錄製資料只保存一個賽季，忽略 season）
+func (s *fixtureHandicapSource) FetchHandicapDetail(ctx context.Context, teamID int, _ season.Season) ([]HandicapGame, error) {
+	body, err := s.read(fixtureHandicapDetail(teamID))
+	if err != nil {
+		return nil, err
+	}
+	return parseHandicapDetail(string(body))
+}
+
+// FetchLetGoal 讀取 l1.js（錄製資料只保存一個賽季，忽略 season）
+func (s *fixtureHandicapSource) FetchLetGoal(ctx context.Context, _ season.Season) (map[string][]models.BetResult, error) {
+	body, err := s.read(fixtureLetGoal)
+	if err != nil {
+		return nil, err
+	}
+	return parseTitan007Spreads(string(body))
+}
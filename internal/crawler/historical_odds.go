@@ -1,8 +1,9 @@
 package crawler
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
 	"strconv"
 	"strings"
 )
@@ -10,24 +11,23 @@ import (
 // FetchHistoricalSpread 取得歷史比賽的盤口資料
 // 目前從 NBA odds API 取得（只有當天或最近的比賽）
 // TODO: 整合 titan007 或其他資料源以取得更早期的歷史盤口
-func FetchHistoricalSpread(gameID string) (homeSpread float64, hasSpread bool) {
+func FetchHistoricalSpread(ctx context.Context, src OddsSource, gameID string) (homeSpread float64, hasSpread bool, err error) {
 	// 嘗試從 NBA odds API 取得
-	odds, err := FetchOdds()
+	odds, err := src.FetchOdds(ctx)
 	if err != nil {
-		log.Printf("無法取得賠率資料: %v", err)
-		return 0, false
+		return 0, false, fmt.Errorf("無法取得賠率資料: %w", err)
 	}
 
 	// 建立盤口查找表
 	oddsMap := BuildOddsMap(odds)
 
 	// 查找該場比賽的盤口
-	if spreadInfo, ok := oddsMap[gameID]; ok && spreadInfo.Found {
+	if spreadInfo, ok := oddsMap[gameID]; ok && spreadInfo.Found && spreadInfo.HomeOpeningSpread != nil {
 		// 使用開盤盤口（opening spread）來判斷過盤
-		return spreadInfo.HomeOpeningSpread, true
+		return *spreadInfo.HomeOpeningSpread, true, nil
 	}
 
-	return 0, false
+	return 0, false, nil
 }
 
 // CalculateSpreadResult 計算是否過盤
@@ -88,12 +88,12 @@ func ParseGameIDToScheduleID(gameID string) (int, error) {
 
 // FetchTitan007HistoricalSpread 從 titan007 取得歷史盤口
 // TODO: 實作從 titan007 API 取得歷史比賽盤口
-func FetchTitan007HistoricalSpread(gameDate string, homeTeam string, awayTeam string) (homeSpread float64, hasSpread bool) {
+func FetchTitan007HistoricalSpread(ctx context.Context, gameDate string, homeTeam string, awayTeam string) (homeSpread float64, hasSpread bool, err error) {
 	// 這裡需要：
 	// 1. 根據日期和球隊名稱查詢 titan007
 	// 2. 解析 JS 資料取得盤口
 	// 3. 返回盤口值
 
 	// 暫時返回無盤口
-	return 0, false
+	return 0, false, errors.New("titan007 歷史盤口尚未實作")
 }
@@ -1,212 +1,143 @@
 package crawler
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
+	"nba-scanner/internal/gametime"
 	"nba-scanner/internal/models"
+	"nba-scanner/internal/season"
+	"nba-scanner/internal/teams"
 	"sort"
-	"time"
 )
 
-// convertGameDateTime 將 ISO 時間轉換為台北時間 "2025/10/02 08:00:00"
-func convertGameDateTime(isoTime string) string {
-	// NBA API 的 gameDateTimeEst 名為 EST，帶 Z 結尾但實際是 EST 時區的時間值
-	// 例如: "2025-10-07T21:30:00Z" 表示 EST 21:30（不是 UTC 21:30）
-	// 需要先解析，然後加上 EST->UTC 的偏移（+4 夏令時或 +5 標準時間），再轉台北
-
-	// 移除 Z，當成無時區的時間解析
-	isoTimeNoZ := isoTime
-	if len(isoTime) > 0 && isoTime[len(isoTime)-1] == 'Z' {
-		isoTimeNoZ = isoTime[:len(isoTime)-1]
-	}
-
-	// 解析為無時區的時間
-	t, err := time.Parse("2006-01-02T15:04:05", isoTimeNoZ)
-	if err != nil {
-		return isoTime // 解析失敗，返回原始字串
-	}
-
-	// 將這個時間視為 EST 時區（UTC-5 標準時間，UTC-4 夏令時）
-	// 10月通常還在夏令時（EDT），使用 UTC-4
-	estLocation := time.FixedZone("EST", -4*60*60)
-	tEST := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, estLocation)
-
-	// 轉換為台北時區 (UTC+8)
-	loc, err := time.LoadLocation("Asia/Taipei")
+// gameStartUTC 將 gameDateTimeEst（美東當地時間）轉為 UTC 時間字串，解析失敗時回傳空字串
+func gameStartUTC(game models.ScheduledGame) string {
+	t, err := gametime.GameStart(game.GameDateTimeUTC, game.GameDateTimeEst)
 	if err != nil {
-		loc = time.FixedZone("CST", 8*60*60)
+		return ""
 	}
-	tTaipei := tEST.In(loc)
-
-	// 格式化為 "2025/10/02 08:00:00"
-	return tTaipei.Format("2006/01/02 15:04:05")
+	return gametime.FormatUTC(t)
 }
 
-// FetchTeamHistory 抓取球隊近期戰績
-func FetchTeamHistory(teamID int, limit int) (*models.TeamHistory, error) {
-	// 抓取完整賽季賽程
-	url := "https://cdn.nba.com/static/json/staticData/scheduleLeagueV2_9.json"
-
-	resp, err := http.Get(url)
+// FetchTeamHistory 抓取球隊在指定賽季的近期戰績
+func FetchTeamHistory(ctx context.Context, src *Sources, sn season.Season, teamID int, limit int) (*models.TeamHistory, error) {
+	// 從共用賽程快取取得該球隊整季的比賽
+	teamGames, err := src.ScheduleFor(ctx, sn).TeamGames(ctx, teamID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch schedule: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("schedule API returned status %d", resp.StatusCode)
+	// 從 titan007 HandicapDetail 頁面獲取近5場過盤結果（含盤口數值）
+	// titan007 失敗不影響戰績本身，只是沒有過盤資料
+	titan007Spreads, err := GetTeamHandicapSpreadsWithValues(ctx, src.Handicap, sn, teamID, limit)
+	if ctx.Err() != nil {
+		// 因取消而缺少過盤資料的戰績不可被快取
+		return nil, ctx.Err()
 	}
-
-	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	var schedule models.FullSchedule
-	if err := json.Unmarshal(body, &schedule); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: %w", err)
-	}
-
-	// 先找出球隊英文名稱（用於查詢 titan007）
-	var teamNameEN string
-	for _, gameDate := range schedule.LeagueSchedule.GameDates {
-		for _, game := range gameDate.Games {
-			if game.HomeTeam.TeamID == teamID {
-				teamNameEN = game.HomeTeam.TeamCity + " " + game.HomeTeam.TeamName
-				break
-			} else if game.AwayTeam.TeamID == teamID {
-				teamNameEN = game.AwayTeam.TeamCity + " " + game.AwayTeam.TeamName
-				break
-			}
-		}
-		if teamNameEN != "" {
-			break
-		}
-	}
-
-	// 從 titan007 HandicapDetail 頁面獲取近5場過盤結果（含盤口數值）
-	titan007Spreads, hasTitan007 := GetTeamHandicapSpreadsWithValues(teamNameEN, limit)
-	if !hasTitan007 {
-		log.Printf("警告：未從 titan007 獲取到 %s 的過盤資料", teamNameEN)
+		log.Printf("警告：未從 titan007 獲取到 %d 的過盤資料: %v", teamID, err)
 	}
+	hasTitan007 := len(titan007Spreads) > 0
 
 	// 收集該球隊的所有比賽
 	var games []models.GameResult
-	for _, gameDate := range schedule.LeagueSchedule.GameDates {
-		for _, game := range gameDate.Games {
-			// 只處理已結束的比賽
-			if game.GameStatus != 3 {
-				continue
-			}
-
-			// 檢查是否為該球隊的比賽
-			var isHome bool
-			var opponent string
-			var score string
-
-			if game.HomeTeam.TeamID == teamID {
-				// 主場比賽（查詢球隊是主隊）
-				isHome = true
-				opponent = game.AwayTeam.TeamCity + " " + game.AwayTeam.TeamName
-				// 顯示格式：客隊分數-主隊分數（標準格式）
-				// 查詢球隊是主隊，所以：對手(客隊)分數 - 查詢球隊(主隊)分數
-				score = fmt.Sprintf("%d-%d", game.AwayTeam.Score, game.HomeTeam.Score)
-
-				// 計算實際勝負
-				var gameResult string
-				if game.HomeTeam.Score > game.AwayTeam.Score {
-					gameResult = "W"
-				} else {
-					gameResult = "L"
-				}
-
-				// 轉換為中文隊名
-				opponentCN := models.TeamMap[opponent]
-				if opponentCN == "" {
-					opponentCN = opponent
-				}
-
-				// 分離日期和時間
-				gameDateTime := convertGameDateTime(game.GameDateTimeEst)
-				dateTimeParts := splitDateTime(gameDateTime)
-
-				// 提取 NBA 原始日期（用於跳轉查詢）
-				nbaGameDate := extractNBAGameDate(game.GameDateTimeEst)
-
-				games = append(games, models.GameResult{
-					GameID:      game.GameID,
-					GameDate:    nbaGameDate,
-					Date:        dateTimeParts[0],
-					Time:        dateTimeParts[1],
-					Opponent:    opponentCN,
-					VsIndicator: "vs",
-					IsHome:      isHome,
-					Score:       score,
-					GameResult:  gameResult,
-					// 過盤結果稍後補上（排序後）
-					SpreadResult: "",
-					Result:       "",
-					Spread:       "",
-					HasSpread:    false,
-				})
-			} else if game.AwayTeam.TeamID == teamID {
-				// 客場比賽（查詢球隊是客隊）
-				isHome = false
-				opponent = game.HomeTeam.TeamCity + " " + game.HomeTeam.TeamName
-				// 顯示格式：客隊分數-主隊分數（標準格式）
-				// 查詢球隊是客隊，所以：查詢球隊(客隊)分數 - 對手(主隊)分數
-				score = fmt.Sprintf("%d-%d", game.AwayTeam.Score, game.HomeTeam.Score)
-
-				// 計算實際勝負
-				var gameResult string
-				if game.AwayTeam.Score > game.HomeTeam.Score {
-					gameResult = "W"
-				} else {
-					gameResult = "L"
-				}
-
-				// 轉換為中文隊名
-				opponentCN := models.TeamMap[opponent]
-				if opponentCN == "" {
-					opponentCN = opponent
-				}
-
-				// 分離日期和時間
-				gameDateTime := convertGameDateTime(game.GameDateTimeEst)
-				dateTimeParts := splitDateTime(gameDateTime)
+	for _, game := range teamGames {
+		// 只處理已結束的比賽
+		if game.GameStatus != 3 {
+			continue
+		}
 
-				// 提取 NBA 原始日期（用於跳轉查詢）
-				nbaGameDate := extractNBAGameDate(game.GameDateTimeEst)
+		// 檢查是否為該球隊的比賽
+		var isHome bool
+		var opponent string
+		var score string
+
+		if game.HomeTeam.TeamID == teamID {
+			// 主場比賽（查詢球隊是主隊）
+			isHome = true
+			opponent = game.AwayTeam.TeamCity + " " + game.AwayTeam.TeamName
+			// 顯示格式：客隊分數-主隊分數（標準格式）
+			// 查詢球隊是主隊，所以：對手(客隊)分數 - 查詢球隊(主隊)分數
+			score = fmt.Sprintf("%d-%d", game.AwayTeam.Score, game.HomeTeam.Score)
+
+			// 計算實際勝負
+			var gameResult string
+			if game.HomeTeam.Score > game.AwayTeam.Score {
+				gameResult = "W"
+			} else {
+				gameResult = "L"
+			}
 
-				games = append(games, models.GameResult{
-					GameID:      game.GameID,
-					GameDate:    nbaGameDate,
-					Date:        dateTimeParts[0],
-					Time:        dateTimeParts[1],
-					Opponent:    opponentCN,
-					VsIndicator: "@",
-					IsHome:      isHome,
-					Score:       score,
-					GameResult:  gameResult,
-					// 過盤結果稍後補上（排序後）
-					SpreadResult: "",
-					Result:       "",
-					Spread:       "",
-					HasSpread:    false,
-				})
+			// 轉換為中文隊名
+			opponentCN := teams.NameTW(game.AwayTeam.TeamID, opponent)
+
+			// 提取 NBA 原始日期（用於跳轉查詢）
+			nbaGameDate := extractNBAGameDate(game.GameDateTimeEst)
+
+			games = append(games, models.GameResult{
+				GameID:      game.GameID,
+				GameDate:    nbaGameDate,
+				GameTimeUTC: gameStartUTC(game),
+				Opponent:    opponentCN,
+				VsIndicator: "vs",
+				IsHome:      isHome,
+				Score:       score,
+				Points:      game.HomeTeam.Score + game.AwayTeam.Score,
+				GameResult:  gameResult,
+				// 過盤結果稍後補上（排序後）
+				SpreadResult: "",
+				Result:       "",
+				Spread:       "",
+				HasSpread:    false,
+			})
+		} else if game.AwayTeam.TeamID == teamID {
+			// 客場比賽（查詢球隊是客隊）
+			isHome = false
+			opponent = game.HomeTeam.TeamCity + " " + game.HomeTeam.TeamName
+			// 顯示格式：客隊分數-主隊分數（標準格式）
+			// 查詢球隊是客隊，所以：查詢球隊(客隊)分數 - 對手(主隊)分數
+			score = fmt.Sprintf("%d-%d", game.AwayTeam.Score, game.HomeTeam.Score)
+
+			// 計算實際勝負
+			var gameResult string
+			if game.AwayTeam.Score > game.HomeTeam.Score {
+				gameResult = "W"
+			} else {
+				gameResult = "L"
 			}
+
+			// 轉換為中文隊名
+			opponentCN := teams.NameTW(game.HomeTeam.TeamID, opponent)
+
+			// 提取 NBA 原始日期（用於跳轉查詢）
+			nbaGameDate := extractNBAGameDate(game.GameDateTimeEst)
+
+			games = append(games, models.GameResult{
+				GameID:      game.GameID,
+				GameDate:    nbaGameDate,
+				GameTimeUTC: gameStartUTC(game),
+				Opponent:    opponentCN,
+				VsIndicator: "@",
+				IsHome:      isHome,
+				Score:       score,
+				Points:      game.HomeTeam.Score + game.AwayTeam.Score,
+				GameResult:  gameResult,
+				// 過盤結果稍後補上（排序後）
+				SpreadResult: "",
+				Result:       "",
+				Spread:       "",
+				HasSpread:    false,
+			})
 		}
 	}
 
-	// 按日期排序（最新的在前）
+	// 按開賽時間排序（最新的在前；UTC 時間字串可直接比較，解析失敗時改用比賽日）
 	sort.Slice(games, func(i, j int) bool {
-		// Date 欄位格式為 "2025/10/02"，不包含時間
-		dateI, _ := time.Parse("2006/01/02", games[i].Date)
-		dateJ, _ := time.Parse("2006/01/02", games[j].Date)
-		return dateI.After(dateJ)
+		if games[i].GameDate != games[j].GameDate {
+			return games[i].GameDate > games[j].GameDate
+		}
+		return games[i].GameTimeUTC > games[j].GameTimeUTC
 	})
 
 	// 只取最近 N 場
@@ -223,60 +154,72 @@ func FetchTeamHistory(teamID int, limit int) (*models.TeamHistory, error) {
 			games[i].Result = spread.Result
 			games[i].HasSpread = true
 
-			// 格式化盤口數值顯示
+			// 格式化盤口數值顯示（四分之一盤口保留兩位小數）
 			if spread.SpreadValue > 0 {
-				games[i].Spread = fmt.Sprintf("+%.1f", spread.SpreadValue)
+				games[i].Spread = "+" + models.FormatLine(spread.SpreadValue)
 			} else {
-				games[i].Spread = fmt.Sprintf("%.1f", spread.SpreadValue)
+				games[i].Spread = models.FormatLine(spread.SpreadValue)
 			}
 		} else {
-			games[i].SpreadResult = games[i].GameResult
+			// 沒有盤口時不以勝負代替過盤結果
+			games[i].SpreadResult = ""
 			games[i].Spread = "無盤口"
 			games[i].HasSpread = false
-			games[i].Result = games[i].GameResult
+			games[i].Result = ""
 		}
 	}
 
-	// 計算勝負場數
-	wins := 0
-	losses := 0
-	for _, game := range games {
-		if game.Result == "W" {
-			wins++
-		} else {
-			losses++
+	// 補上大小分盤口與結果（需要保存過的賠率快照）
+	closing, hasClosing := src.Odds.(ClosingTotalSource)
+	for i := range games {
+		if !hasClosing {
+			break
 		}
+		total, ok := closing.ClosingTotal(games[i].GameID)
+		if !ok {
+			continue
+		}
+		games[i].Total = models.FormatLine(total)
+		games[i].TotalResult = models.OverUnderResult(games[i].Points, total)
+		games[i].HasTotal = true
 	}
 
-	return &models.TeamHistory{
+	// 顯示日期與時間使用預設時區，API 會依請求的時區重新換算
+	for i := range games {
+		games[i].Localize(gametime.Default())
+	}
+
+	// 計算過盤場數與大小分場數（只計入有盤口的比賽）
+	// 贏半/輸半計入贏/輸，走盤不計入勝負
+	history := &models.TeamHistory{
 		TeamID:      teamID,
 		RecentGames: games,
-		WinCount:    wins,
-		LossCount:   losses,
-	}, nil
-}
-
-// splitDateTime 分離日期和時間
-// 輸入: "2025/10/02 08:00:00"
-// 輸出: ["2025/10/02", "08:00"]
-func splitDateTime(datetime string) []string {
-	parts := []string{"", ""}
-
-	// 使用空格分割日期和時間
-	if len(datetime) > 10 {
-		parts[0] = datetime[:10] // 日期部分
-		if len(datetime) > 11 {
-			timePart := datetime[11:]
-			// 只取 HH:MM 部分
-			if len(timePart) >= 5 {
-				parts[1] = timePart[:5]
+	}
+	for _, game := range games {
+		if game.HasSpread {
+			switch game.SpreadResult {
+			case models.BetWin, models.BetHalfWin:
+				history.ATSWins++
+			case models.BetLoss, models.BetHalfLoss:
+				history.ATSLosses++
+			case models.BetPush:
+				history.ATSPushes++
 			}
 		}
-	} else {
-		parts[0] = datetime
+
+		switch game.TotalResult {
+		case models.TotalOver:
+			history.OverCount++
+		case models.TotalUnder:
+			history.UnderCount++
+		case models.TotalPush:
+			history.TotalPushCount++
+		}
 	}
+	// winCount/lossCount 是舊的 JSON 欄位，由過盤場數填入
+	history.WinCount, history.LossCount = history.ATSWins, history.ATSLosses
 
-	return parts
+	return history, nil
 }
 
 // GetTeamIDFromName 從球隊名稱獲取 TeamID（簡化版，實際應該從 API 獲取）
@@ -1,16 +1,30 @@
 package crawler
 
 import (
+	"context"
 	"nba-scanner/internal/models"
+	"nba-scanner/internal/season"
 	"sync"
 	"time"
 )
 
+// historyKey 戰績快取的 key（同一球隊取不同場數的結果分開快取）
+type historyKey struct {
+	season season.Season
+	teamID int
+	limit  int
+}
+
+// historyEntry 快取的戰績與寫入時間（每筆各自過期）
+type historyEntry struct {
+	history  *models.TeamHistory
+	cachedAt time.Time
+}
+
 // HistoryCache 戰績快取
 type HistoryCache struct {
-	data      map[int]*models.TeamHistory // teamID -> 戰績
-	mu        sync.RWMutex
-	lastUpdate time.Time
+	data          map[historyKey]historyEntry // 賽季 + teamID + 場數 -> 戰績
+	mu            sync.RWMutex
 	cacheDuration time.Duration
 }
 
@@ -23,7 +37,7 @@ var (
 func GetHistoryCache() *HistoryCache {
 	once.Do(func() {
 		historyCache = &HistoryCache{
-			data:          make(map[int]*models.TeamHistory),
+			data:          make(map[historyKey]historyEntry),
 			cacheDuration: 1 * time.Hour, // 快取 1 小時
 		}
 	})
@@ -31,26 +45,30 @@ func GetHistoryCache() *HistoryCache {
 }
 
 // Get 從快取取得球隊戰績
-func (c *HistoryCache) Get(teamID int) (*models.TeamHistory, bool) {
+func (c *HistoryCache) Get(sn season.Season, teamID int, limit int) (*models.TeamHistory, bool) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
+	entry, exists := c.data[historyKey{sn, teamID, limit}]
 	// 檢查快取是否過期
-	if time.Since(c.lastUpdate) > c.cacheDuration {
+	if !exists || time.Since(entry.cachedAt) > c.cacheDuration {
 		return nil, false
 	}
-
-	history, exists := c.data[teamID]
-	return history, exists
+	return entry.history, true
 }
 
-// Set 設定球隊戰績到快取
-func (c *HistoryCache) Set(teamID int, history *models.TeamHistory) {
+// Set 設定球隊戰績到快取（順便移除已過期的資料）
+func (c *HistoryCache) Set(sn season.Season, teamID int, limit int, history *models.TeamHistory) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.data[teamID] = history
-	c.lastUpdate = time.Now()
+	now := time.Now()
+	for key, entry := range c.data {
+		if now.Sub(entry.cachedAt) > c.cacheDuration {
+			delete(c.data, key)
+		}
+	}
+	c.data[historyKey{sn, teamID, limit}] = historyEntry{history: history, cachedAt: now}
 }
 
 // Clear 清除所有快取
@@ -58,34 +76,26 @@ func (c *HistoryCache) Clear() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.data = make(map[int]*models.TeamHistory)
-}
-
-// IsExpired 檢查快取是否過期
-func (c *HistoryCache) IsExpired() bool {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	return time.Since(c.lastUpdate) > c.cacheDuration
+	c.data = make(map[historyKey]historyEntry)
 }
 
 // FetchTeamHistoryWithCache 使用快取的版本
-func FetchTeamHistoryWithCache(teamID int, limit int) (*models.TeamHistory, error) {
+func FetchTeamHistoryWithCache(ctx context.Context, src *Sources, sn season.Season, teamID int, limit int) (*models.TeamHistory, error) {
 	cache := GetHistoryCache()
 
 	// 先嘗試從快取取得
-	if history, exists := cache.Get(teamID); exists {
+	if history, exists := cache.Get(sn, teamID, limit); exists {
 		return history, nil
 	}
 
 	// 快取未命中，抓取新資料
-	history, err := FetchTeamHistory(teamID, limit)
+	history, err := FetchTeamHistory(ctx, src, sn, teamID, limit)
 	if err != nil {
 		return nil, err
 	}
 
 	// 存入快取
-	cache.Set(teamID, history)
+	cache.Set(sn, teamID, limit, history)
 
 	return history, nil
 }
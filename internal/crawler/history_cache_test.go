@@ -0,0 +1,63 @@
+package crawler
+
+import (
+	"nba-scanner/internal/models"
+	"nba-scanner/internal/season"
+	"testing"
+	"time"
+)
+
+func TestHistoryCache(t *testing.T) {
+	sn, _ := season.Parse("2025-26")
+	lakers := 1610612747
+
+	tests := []struct {
+		name  string
+		age   time.Duration // 已快取的資料寫入多久
+		sn    season.Season
+		team  int
+		limit int
+		want  bool
+	}{
+		{"相同 key 命中", 0, sn, lakers, 10, true},
+		{"快取時間內命中", 59 * time.Minute, sn, lakers, 10, true},
+		{"過期不命中", 61 * time.Minute, sn, lakers, 10, false},
+		{"不同場數不命中", 0, sn, lakers, 5, false},
+		{"不同賽季不命中", 0, sn.Previous(), lakers, 10, false},
+		{"不同球隊不命中", 0, sn, 1610612744, 10, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &HistoryCache{data: make(map[historyKey]historyEntry), cacheDuration: time.Hour}
+			history := &models.TeamHistory{}
+			c.Set(sn, lakers, 10, history)
+			c.data[historyKey{sn, lakers, 10}] = historyEntry{history: history, cachedAt: time.Now().Add(-tt.age)}
+
+			got, ok := c.Get(tt.sn, tt.team, tt.limit)
+			if ok != tt.want || (ok && got != history) {
+				t.Errorf("Get = %v, %v，預期命中 %v", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistoryCacheExpiresEntriesIndividually(t *testing.T) {
+	sn, _ := season.Parse("2025-26")
+	c := &HistoryCache{data: make(map[historyKey]historyEntry), cacheDuration: time.Hour}
+
+	old := &models.TeamHistory{}
+	c.Set(sn, 1, 10, old)
+	c.data[historyKey{sn, 1, 10}] = historyEntry{history: old, cachedAt: time.Now().Add(-2 * time.Hour)}
+
+	// 寫入新資料不會延長舊資料的期限，且順便移除過期的資料
+	c.Set(sn, 2, 10, &models.TeamHistory{})
+	if _, ok := c.Get(sn, 1, 10); ok {
+		t.Error("過期的資料不應因為其他球隊寫入而繼續命中")
+	}
+	if _, exists := c.data[historyKey{sn, 1, 10}]; exists {
+		t.Error("寫入時應移除已過期的資料")
+	}
+	if _, ok := c.Get(sn, 2, 10); !ok {
+		t.Error("新寫入的資料應命中")
+	}
+}
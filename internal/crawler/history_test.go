@@ -0,0 +1,64 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"nba-scanner/internal/models"
+	"nba-scanner/internal/season"
+	"testing"
+)
+
+// failingHandicap titan007 無法取得盤口
+type failingHandicap struct{}
+
+func (failingHandicap) FetchHandicapDetail(ctx context.Context, teamID int, sn season.Season) ([]HandicapGame, error) {
+	return nil, errors.New("titan007 unavailable")
+}
+
+func (failingHandicap) FetchLetGoal(ctx context.Context, sn season.Season) (map[string][]models.BetResult, error) {
+	return nil, errors.New("titan007 unavailable")
+}
+
+// finalsSchedule 湖人兩場已結束比賽（一勝一敗）的賽程
+type finalsSchedule struct {
+	stubSchedule
+}
+
+func (s *finalsSchedule) FetchFullSchedule(ctx context.Context) (*models.FullSchedule, error) {
+	lakers := models.ScheduledTeam{TeamID: 1610612747, TeamName: "Lakers", TeamCity: "Los Angeles"}
+	warriors := models.ScheduledTeam{TeamID: 1610612744, TeamName: "Warriors", TeamCity: "Golden State"}
+	game := func(id, date string, home, away int) models.ScheduledGame {
+		h, a := lakers, warriors
+		h.Score, a.Score = home, away
+		return models.ScheduledGame{GameID: id, GameStatus: 3, GameDateTimeEst: date + "T22:30:00Z", HomeTeam: h, AwayTeam: a}
+	}
+
+	schedule := &models.FullSchedule{}
+	schedule.LeagueSchedule.GameDates = []models.GameDate{
+		{GameDate: "11/01/2025 00:00:00", Games: []models.ScheduledGame{game("0022500101", "2025-11-01", 112, 108)}},
+		{GameDate: "11/03/2025 00:00:00", Games: []models.ScheduledGame{game("0022500121", "2025-11-03", 99, 104)}},
+	}
+	return schedule, nil
+}
+
+func TestFetchTeamHistoryWithoutLinesSkipsATS(t *testing.T) {
+	sn, _ := season.Parse("2025-26")
+	schedule := &finalsSchedule{}
+	src := &Sources{Schedule: schedule, Handicap: failingHandicap{}, Season: NewScheduleStore(schedule, 0)}
+
+	history, err := FetchTeamHistory(context.Background(), src, sn, 1610612747, 5)
+	if err != nil {
+		t.Fatalf("取得戰績失敗: %v", err)
+	}
+	if len(history.RecentGames) != 2 {
+		t.Fatalf("近期比賽 %d 場，預期 2 場", len(history.RecentGames))
+	}
+	for _, game := range history.RecentGames {
+		if game.HasSpread || game.SpreadResult != "" || game.Result != "" {
+			t.Errorf("%s 沒有盤口時不應有過盤結果，得到 %q", game.GameID, game.SpreadResult)
+		}
+	}
+	if history.WinCount+history.LossCount+history.ATSWins+history.ATSLosses+history.ATSPushes != 0 {
+		t.Errorf("沒有盤口的比賽不應計入過盤：%+v", history)
+	}
+}
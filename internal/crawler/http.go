@@ -0,0 +1,400 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// 預設值
+const (
+	DefaultRetries          = 2                      // 5xx 或逾時的重試次數
+	DefaultBreakerThreshold = 5                      // 同一主機連續失敗幾次後斷路
+	DefaultBreakerCooldown  = 30 * time.Second       // 斷路後多久放行一次試探請求
+	DefaultMaxPerHost       = 6                      // 同一主機同時進行的請求數
+	retryBaseBackoff        = 300 * time.Millisecond // 第一次重試前的等待時間（之後加倍並加上隨機抖動）
+	retryMaxBackoff         = 3 * time.Second
+)
+
+// defaultTimeouts 各資料來源單次請求的逾時
+var defaultTimeouts = map[string]time.Duration{
+	SourceSchedule: 20 * time.Second, // 整季賽程約數 MB
+	SourceOdds:     8 * time.Second,
+	SourceInjuries: 10 * time.Second,
+	SourceBoxscore: 8 * time.Second,
+	SourceTitan007: 10 * time.Second, // titan007 回應較慢
+}
+
+// UpstreamConfig 上游 HTTP 連線設定
+type UpstreamConfig struct {
+	Transport        http.RoundTripper        // nil 使用共用連線池（錄製模式傳入 Recorder）
+	Timeouts         map[string]time.Duration // 各資料來源的逾時（未指定的來源使用預設值）
+	Timeout          time.Duration            // 所有來源共用的逾時（不為 0 時覆寫 Timeouts 與預設值）
+	Retries          int                      // 重試次數（負數表示不重試，0 使用預設值）
+	BreakerThreshold int                      // 連續失敗幾次後斷路（0 使用預設值）
+	BreakerCooldown  time.Duration            // 斷路冷卻時間（0 使用預設值）
+	MaxPerHost       int                      // 同一主機同時進行的請求數（負數表示不限制，0 使用預設值）
+}
+
+// ErrCircuitOpen 上游主機斷路中，請求直接失敗
+var ErrCircuitOpen = errors.New("上游暫時停止連線（斷路中）")
+
+// StaleError 上游斷路中，回傳的是之前成功抓到的舊資料
+type StaleError struct {
+	Err       error     // 斷路前最後一次的錯誤
+	FetchedAt time.Time // 舊資料的抓取時間
+}
+
+func (e *StaleError) Error() string {
+	return fmt.Sprintf("使用 %s 的舊資料: %v", e.FetchedAt.Format(time.RFC3339), e.Err)
+}
+
+func (e *StaleError) Unwrap() error {
+	return e.Err
+}
+
+// IsStale 錯誤是否代表資料來自斷路期間的舊資料（資料本身仍可使用）
+func IsStale(err error) bool {
+	var stale *StaleError
+	return errors.As(err, &stale)
+}
+
+// upstreamRequest 單次上游請求
+type upstreamRequest struct {
+	source string      // 資料來源名稱（決定逾時）
+	label  string      // 錯誤訊息用的名稱（例如 "odds"、"boxscore"）
+	url    string      // 請求網址
+	header http.Header // 額外的 request headers
+	// staleKey 斷路時取用舊資料的 key，空字串表示不保留舊資料
+	// 只有固定網址的來源（賽程、賠率、傷兵）才指定，避免每場 boxscore、每個 titan007 頁面都留一份
+	staleKey string
+}
+
+// upstreamResponse 上游回應（只有 2xx 與 304 視為成功）
+type upstreamResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// staleEntry 斷路時可回傳的舊資料
+type staleEntry struct {
+	resp      upstreamResponse
+	fetchedAt time.Time
+}
+
+// upstream 所有上游共用的 HTTP client
+// 逾時、5xx/逾時重試（指數退避加抖動）、每個主機的斷路器、gzip 與連線重用
+type upstream struct {
+	client   *http.Client
+	timeouts map[string]time.Duration
+	retries  int
+
+	threshold int
+	cooldown  time.Duration
+	perHost   int
+
+	mu       sync.Mutex
+	breakers map[string]*breaker      // key 為主機名稱
+	slots    map[string]chan struct{} // 每個主機的並行名額，key 為主機名稱
+	stale    map[string]staleEntry    // key 為 staleKey
+}
+
+// newTransport 建立上游共用的連線池
+// 每個上游主機保留較多閒置連線，同一批請求（boxscore、titan007）可重用連線
+// 未自行設定 Accept-Encoding 時，Transport 會自動要求並解開 gzip
+func newTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConnsPerHost = 16
+	t.IdleConnTimeout = 90 * time.Second
+	return t
+}
+
+// newUpstream 建立上游 client
+func newUpstream(cfg UpstreamConfig) *upstream {
+	transport := cfg.Transport
+	if transport == nil {
+		transport = newTransport()
+	}
+
+	u := &upstream{
+		client:    &http.Client{Transport: transport},
+		timeouts:  make(map[string]time.Duration),
+		retries:   cfg.Retries,
+		threshold: cfg.BreakerThreshold,
+		cooldown:  cfg.BreakerCooldown,
+		perHost:   cfg.MaxPerHost,
+		breakers:  make(map[string]*breaker),
+		slots:     make(map[string]chan struct{}),
+		stale:     make(map[string]staleEntry),
+	}
+	for source, timeout := range defaultTimeouts {
+		u.timeouts[source] = timeout
+	}
+	for source, timeout := range cfg.Timeouts {
+		u.timeouts[source] = timeout
+	}
+	if cfg.Timeout > 0 {
+		for source := range u.timeouts {
+			u.timeouts[source] = cfg.Timeout
+		}
+	}
+	switch {
+	case u.retries == 0:
+		u.retries = DefaultRetries
+	case u.retries < 0:
+		u.retries = 0
+	}
+	if u.threshold <= 0 {
+		u.threshold = DefaultBreakerThreshold
+	}
+	if u.cooldown <= 0 {
+		u.cooldown = DefaultBreakerCooldown
+	}
+	if u.perHost == 0 {
+		u.perHost = DefaultMaxPerHost
+	}
+	return u
+}
+
+// fetchBody 發送 GET 請求並讀取完整回應內容（只接受 200）
+func (u *upstream) fetchBody(ctx context.Context, r upstreamRequest) ([]byte, error) {
+	resp, err := u.fetch(ctx, r)
+	if resp.StatusCode != 0 && resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s API returned status %d", r.label, resp.StatusCode)
+	}
+	if err != nil && !IsStale(err) {
+		return nil, err
+	}
+	return resp.Body, err
+}
+
+// fetch 發送請求，依設定重試並經過斷路器
+// 斷路中且有舊資料時，回傳舊資料與 *StaleError
+func (u *upstream) fetch(ctx context.Context, r upstreamRequest) (upstreamResponse, error) {
+	parsed, err := url.Parse(r.url)
+	if err != nil {
+		return upstreamResponse{}, fmt.Errorf("failed to build %s request: %w", r.label, err)
+	}
+	host := parsed.Host
+
+	b := u.breaker(host)
+	allowed, probe := b.allow(time.Now())
+	if !allowed {
+		return u.serveStale(r.staleKey, fmt.Errorf("%s: %w", host, ErrCircuitOpen))
+	}
+	if probe {
+		// 試探請求被呼叫端取消時沒有成功或失敗的結果，需要放行下一次試探
+		defer b.endProbe()
+	}
+
+	var resp upstreamResponse
+	for attempt := 0; ; attempt++ {
+		resp, err = u.do(ctx, host, r)
+		if err == nil || attempt >= u.retries || !retryable(resp.StatusCode, err) || ctx.Err() != nil {
+			break
+		}
+
+		wait := backoff(attempt)
+		log.Printf("%s 抓取失敗，%v 後重試（第 %d 次）: %v", r.label, wait.Round(time.Millisecond), attempt+1, err)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return upstreamResponse{}, fmt.Errorf("failed to fetch %s: %w", r.label, ctx.Err())
+		}
+	}
+
+	if err != nil {
+		// 4xx 代表主機正常回應（例如尚未發布的 boxscore），不計入斷路，直接回傳
+		if !retryable(resp.StatusCode, err) {
+			if resp.StatusCode != 0 {
+				b.success()
+			}
+			return resp, err
+		}
+		// 呼叫端取消不算上游失敗
+		if ctx.Err() == nil && b.failure(time.Now(), u.threshold, u.cooldown) {
+			log.Printf("⚡ %s 連續失敗 %d 次，暫停連線 %v", host, u.threshold, u.cooldown)
+		}
+		return resp, err
+	}
+
+	b.success()
+	if resp.StatusCode == http.StatusOK && r.staleKey != "" {
+		u.mu.Lock()
+		u.stale[r.staleKey] = staleEntry{resp: resp, fetchedAt: time.Now()}
+		u.mu.Unlock()
+	}
+	return resp, nil
+}
+
+// do 發送單次請求（等待主機並行名額的時間不計入逾時）
+func (u *upstream) do(ctx context.Context, host string, r upstreamRequest) (upstreamResponse, error) {
+	release, err := u.acquire(ctx, host)
+	if err != nil {
+		return upstreamResponse{}, fmt.Errorf("failed to fetch %s: %w", r.label, err)
+	}
+	defer release()
+
+	if timeout := u.timeouts[r.source]; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", r.url, nil)
+	if err != nil {
+		return upstreamResponse{}, fmt.Errorf("failed to build %s request: %w", r.label, err)
+	}
+	for key, values := range r.header {
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return upstreamResponse{}, fmt.Errorf("failed to fetch %s: %w", r.label, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return upstreamResponse{}, fmt.Errorf("failed to read %s response: %w", r.label, err)
+	}
+
+	result := upstreamResponse{StatusCode: resp.StatusCode, Header: resp.Header, Body: body}
+	if resp.StatusCode/100 != 2 && resp.StatusCode != http.StatusNotModified {
+		return result, fmt.Errorf("%s API returned status %d", r.label, resp.StatusCode)
+	}
+	return result, nil
+}
+
+// acquire 取得主機的並行名額，回傳釋放函式（ctx 取消時放棄等待）
+func (u *upstream) acquire(ctx context.Context, host string) (func(), error) {
+	if u.perHost < 0 {
+		return func() {}, nil
+	}
+
+	u.mu.Lock()
+	slots, ok := u.slots[host]
+	if !ok {
+		slots = make(chan struct{}, u.perHost)
+		u.slots[host] = slots
+	}
+	u.mu.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// serveStale 斷路中回傳舊資料，沒有舊資料時直接失敗
+func (u *upstream) serveStale(key string, err error) (upstreamResponse, error) {
+	u.mu.Lock()
+	entry, ok := u.stale[key]
+	u.mu.Unlock()
+	if key == "" || !ok {
+		return upstreamResponse{}, err
+	}
+	return entry.resp, &StaleError{Err: err, FetchedAt: entry.fetchedAt}
+}
+
+// breaker 取得主機的斷路器
+func (u *upstream) breaker(host string) *breaker {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	b, ok := u.breakers[host]
+	if !ok {
+		b = &breaker{}
+		u.breakers[host] = b
+	}
+	return b
+}
+
+// retryable 是否值得重試：連線錯誤、逾時與 5xx（4xx 重試也不會成功）
+func retryable(status int, err error) bool {
+	if status >= 500 {
+		return true
+	}
+	if status != 0 {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// backoff 第 attempt 次重試前的等待時間：指數退避，並在 50%~100% 之間隨機抖動避免同時重試
+func backoff(attempt int) time.Duration {
+	d := retryBaseBackoff << attempt
+	if d > retryMaxBackoff || d <= 0 {
+		d = retryMaxBackoff
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// breaker 單一主機的斷路器
+// 連續失敗（5xx、逾時與連線錯誤）達門檻後斷路，冷卻時間過後放行一次試探請求，成功即恢復
+type breaker struct {
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool // 冷卻後的試探請求進行中
+}
+
+// allow 是否允許發送請求，probe 表示這次是冷卻後的試探請求（結束時需呼叫 endProbe）
+func (b *breaker) allow(now time.Time) (allowed, probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.openUntil.IsZero() {
+		return true, false
+	}
+	if now.Before(b.openUntil) || b.probing {
+		return false, false
+	}
+	b.probing = true
+	return true, true
+}
+
+// endProbe 試探請求結束：已由 success/failure 處理時不做任何事，
+// 被取消而沒有結果時維持斷路狀態，冷卻時間已過所以下一個請求會再試探一次
+func (b *breaker) endProbe() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// success 請求成功，關閉斷路器
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.openUntil = time.Time{}
+	b.probing = false
+}
+
+// failure 記錄失敗，回傳是否因此斷路
+func (b *breaker) failure(now time.Time, threshold int, cooldown time.Duration) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.probing || (b.openUntil.IsZero() && b.failures >= threshold) {
+		b.openUntil = now.Add(cooldown)
+		b.probing = false
+		return true
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+package crawler
+
+import (
+	"context"
+	"io"
+	"nba-scanner/internal/models"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stubTransport 依序回傳設定的狀態碼（用完後重複最後一個），記錄請求次數
+type stubTransport struct {
+	mu       sync.Mutex
+	statuses []int
+	body     string
+	calls    int
+}
+
+func (t *stubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	status := t.statuses[min(t.calls, len(t.statuses)-1)]
+	t.calls++
+	t.mu.Unlock()
+
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(t.body)),
+		Request:    req,
+	}, nil
+}
+
+// newTestUpstream 不重試、連續失敗 2 次即斷路的上游 client
+func newTestUpstream(rt http.RoundTripper) *upstream {
+	return newUpstream(UpstreamConfig{
+		Transport:        rt,
+		Retries:          -1,
+		BreakerThreshold: 2,
+		BreakerCooldown:  time.Hour,
+	})
+}
+
+const testOddsBody = `{"games":[{"gameId":"0022500510","homeTeamId":"1610612747","awayTeamId":"1610612746"}]}`
+
+func TestFetchOddsServesStaleWhenBreakerOpen(t *testing.T) {
+	rt := &stubTransport{statuses: []int{200, 500}, body: testOddsBody}
+	src := &httpOddsSource{up: newTestUpstream(rt)}
+	ctx := context.Background()
+
+	if _, err := src.FetchOdds(ctx); err != nil {
+		t.Fatalf("第一次抓取失敗: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := src.FetchOdds(ctx); err == nil {
+			t.Fatalf("第 %d 次 500 應該失敗", i+1)
+		}
+	}
+
+	calls := rt.calls
+	odds, err := src.FetchOdds(ctx)
+	if !IsStale(err) {
+		t.Fatalf("斷路中應回傳 *StaleError，得到 %v", err)
+	}
+	if odds == nil || len(odds.Games) != 1 || odds.Games[0].GameID != "0022500510" {
+		t.Fatalf("斷路中應回傳舊資料，得到 %+v", odds)
+	}
+	if rt.calls != calls {
+		t.Errorf("斷路中不應再請求上游（多了 %d 次）", rt.calls-calls)
+	}
+
+	// monitor 記錄 stale 狀態後把舊資料當成正常資料往上傳
+	m := NewSourceMonitor()
+	monitored := &monitorOdds{OddsSource: src, monitor: m}
+	odds, err = monitored.FetchOdds(ctx)
+	if err != nil || odds == nil {
+		t.Fatalf("monitor 應回傳舊資料且不回傳錯誤，得到 %v, %v", odds, err)
+	}
+	for _, status := range m.Statuses() {
+		if status.Name == SourceOdds && status.Status != models.SourceStale {
+			t.Errorf("odds 狀態 = %s，預期 stale", status.Status)
+		}
+	}
+}
+
+func TestFetchWithoutStaleKeyFailsWhenBreakerOpen(t *testing.T) {
+	rt := &stubTransport{statuses: []int{200, 500}, body: "{}"}
+	up := newTestUpstream(rt)
+	ctx := context.Background()
+	req := upstreamRequest{source: SourceBoxscore, label: "boxscore", url: "https://cdn.example.com/boxscore.json"}
+
+	for i := 0; i < 3; i++ {
+		up.fetchBody(ctx, req)
+	}
+	body, err := up.fetchBody(ctx, req)
+	if err == nil || IsStale(err) || body != nil {
+		t.Fatalf("沒有 staleKey 的請求斷路中應直接失敗，得到 %q, %v", body, err)
+	}
+}
+
+func TestCancelledProbeDoesNotBlockHost(t *testing.T) {
+	rt := &stubTransport{statuses: []int{500, 500, 200}, body: testOddsBody}
+	up := newUpstream(UpstreamConfig{
+		Transport:        rt,
+		Retries:          -1,
+		BreakerThreshold: 2,
+		BreakerCooldown:  time.Millisecond,
+	})
+	req := upstreamRequest{source: SourceOdds, label: "odds", url: oddsURL}
+
+	for i := 0; i < 2; i++ {
+		up.fetchBody(context.Background(), req)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	// 冷卻後的試探請求被呼叫端取消（例如連線中斷或 /api/games 逾時）
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := up.fetchBody(ctx, req); err == nil {
+		t.Fatal("已取消的請求應該失敗")
+	}
+
+	// 下一個請求仍應放行試探，成功後關閉斷路器
+	if _, err := up.fetchBody(context.Background(), req); err != nil {
+		t.Fatalf("取消的試探請求之後主機仍被擋住: %v", err)
+	}
+	if _, err := up.fetchBody(context.Background(), req); err != nil {
+		t.Fatalf("試探成功後應恢復連線: %v", err)
+	}
+}
+
+func TestBreakerCountsOnlyUpstreamFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		wantOpen bool
+	}{
+		{"404 不斷路", []int{404}, false},
+		{"403 不斷路", []int{403}, false},
+		{"500 斷路", []int{500}, true},
+		{"503 斷路", []int{503}, true},
+		{"成功後重新計算", []int{500, 200, 500, 200}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := &stubTransport{statuses: tt.statuses, body: "{}"}
+			up := newTestUpstream(rt)
+			req := upstreamRequest{source: SourceBoxscore, label: "boxscore", url: "https://cdn.example.com/boxscore.json"}
+
+			for i := 0; i < 4; i++ {
+				up.fetchBody(context.Background(), req)
+			}
+			allowed, _ := up.breaker("cdn.example.com").allow(time.Now())
+			if allowed == tt.wantOpen {
+				t.Errorf("斷路 = %v，預期 %v", !allowed, tt.wantOpen)
+			}
+		})
+	}
+}
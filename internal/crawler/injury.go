@@ -1,43 +1,125 @@
 package crawler
 
 import (
-	"log"
-	"net/http"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"nba-scanner/internal/gametime"
+	"nba-scanner/internal/models"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
 )
 
-func FetchInjuryMap() map[string][]string {
-	result := make(map[string][]string)
+const injuriesURL = "https://www.espn.com/nba/injuries"
 
-	res, err := http.Get("https://www.espn.com/nba/injuries")
-	if err != nil {
-		log.Fatal(err)
+var (
+	// espnPlayerIDPattern ESPN 球員頁面網址中的球員 ID（/nba/player/_/id/4351852/...）
+	espnPlayerIDPattern = regexp.MustCompile(`/id/(\d+)`)
+	// injuryReportDatePattern 傷情說明開頭的更新日期（"Jan 14: ..."）
+	injuryReportDatePattern = regexp.MustCompile(`^([A-Z][a-z]{2}) (\d{1,2}):\s*`)
+)
+
+// httpInjurySource 從 ESPN 抓取傷兵名單
+type httpInjurySource struct {
+	up *upstream
+}
+
+// FetchInjuryMap 抓取各隊傷兵名單
+func (s *httpInjurySource) FetchInjuryMap(ctx context.Context) (map[string][]models.Injury, error) {
+	body, err := s.up.fetchBody(ctx, upstreamRequest{source: SourceInjuries, label: "injuries", url: injuriesURL, staleKey: injuriesURL})
+	if err != nil && !IsStale(err) {
+		return nil, err
+	}
+	// 斷路中回傳舊資料與 *StaleError，由 monitor 記錄狀態
+	injuryMap, parseErr := parseInjuryMap(bytes.NewReader(body), time.Now())
+	if parseErr != nil {
+		return nil, parseErr
 	}
-	defer res.Body.Close()
+	return injuryMap, err
+}
+
+// parseInjuryMap 解析 ESPN 傷兵頁面（now 用於推算只有月日的日期屬於哪一年）
+func parseInjuryMap(r io.Reader, now time.Time) (map[string][]models.Injury, error) {
+	result := make(map[string][]models.Injury)
 
-	doc, err := goquery.NewDocumentFromReader(res.Body)
+	doc, err := goquery.NewDocumentFromReader(r)
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("failed to parse injuries HTML: %w", err)
 	}
 
 	doc.Find(".Table__league-injuries").Each(func(i int, s *goquery.Selection) {
 		team := s.Find(".injuries__teamName").Text()
-		var injuries []string
+		var injuries []models.Injury
 
 		s.Find(".Table__even").Each(func(j int, g *goquery.Selection) {
-			name := strings.TrimSpace(g.Find(".AnchorLink").Text())
+			link := g.Find(".AnchorLink")
+			name := strings.TrimSpace(link.Text())
 			status := strings.TrimSpace(g.Find(".col-stat").Text())
-			comment := strings.TrimSpace(g.Find(".col-desc").Text())
+			if name == "" || status == "" {
+				return
+			}
 
-			if name != "" && status != "" {
-				injuries = append(injuries, name+" "+status+" "+comment)
+			injury := models.Injury{
+				PlayerName:  name,
+				Position:    strings.TrimSpace(g.Find(".col-pos").Text()),
+				Status:      models.ParseInjuryStatus(status),
+				StatusText:  status,
+				Description: strings.TrimSpace(g.Find(".col-desc").Text()),
+			}
+			if href, ok := link.Attr("href"); ok {
+				injury.ESPNURL = href
+				if m := espnPlayerIDPattern.FindStringSubmatch(href); m != nil {
+					injury.ESPNID = m[1]
+				}
+			}
+			// 預計復出日期通常在未來
+			if day, ok := parseMonthDay(strings.TrimSpace(g.Find(".col-date").Text()), now, false); ok {
+				injury.ReturnDate = day
 			}
+			// 說明開頭的日期為傷情更新日，一定不晚於現在
+			if m := injuryReportDatePattern.FindStringSubmatch(injury.Description); m != nil {
+				if day, ok := parseMonthDay(m[1]+" "+m[2], now, true); ok {
+					injury.ReportDate = day
+					injury.Description = strings.TrimSpace(injury.Description[len(m[0]):])
+				}
+			}
+
+			injuries = append(injuries, injury)
 		})
 
 		result[team] = injuries
 	})
 
-	return result
+	return result, nil
+}
+
+// parseMonthDay 將 "Jan 20" 轉為 "2026-01-20"；past 為 true 時取不晚於 now 的年份，否則取最接近 now 的年份
+func parseMonthDay(s string, now time.Time, past bool) (string, bool) {
+	md, err := time.Parse("Jan 2", s)
+	if err != nil {
+		return "", false
+	}
+
+	now = now.In(gametime.Eastern)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, gametime.Eastern)
+	day := time.Date(now.Year(), md.Month(), md.Day(), 0, 0, 0, 0, gametime.Eastern)
+
+	if past {
+		if day.After(today) {
+			day = day.AddDate(-1, 0, 0)
+		}
+		return day.Format("2006-01-02"), true
+	}
+
+	// 跨年時（如 12 月看到 "Jan 5"）取距離較近的年份
+	if next := day.AddDate(1, 0, 0); next.Sub(today) < today.Sub(day) {
+		day = next
+	} else if prev := day.AddDate(-1, 0, 0); today.Sub(prev) < day.Sub(today) {
+		day = prev
+	}
+	return day.Format("2006-01-02"), true
 }
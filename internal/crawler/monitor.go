@@ -0,0 +1,291 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"nba-scanner/internal/gametime"
+	"nba-scanner/internal/models"
+	"nba-scanner/internal/season"
+	"sync"
+	"time"
+)
+
+// 上游資料來源名稱（models.SourceStatus.Name）
+const (
+	SourceSchedule = "schedule"
+	SourceOdds     = "odds"
+	SourceInjuries = "injuries"
+	SourceBoxscore = "boxscore"
+	SourceTitan007 = "titan007"
+)
+
+// sourceNames 回應中資料來源的顯示順序
+var sourceNames = []string{SourceSchedule, SourceOdds, SourceInjuries, SourceBoxscore, SourceTitan007}
+
+// sourceBatchWindow 同一批抓取的時間範圍：最近一次抓取前這段時間內的結果一起判斷狀態
+// （boxscore、titan007 每次請求會抓多場比賽或多支球隊，只看最後一次會掩蓋其他失敗）
+const sourceBatchWindow = 2 * time.Minute
+
+// fetchResult 單一資料項目（比賽、球隊）最近一次的抓取結果
+type fetchResult struct {
+	latency time.Duration
+	attempt time.Time
+	err     error
+}
+
+// sourceState 單一資料來源的抓取結果
+type sourceState struct {
+	results     map[string]fetchResult // key 為比賽 ID、球隊 ID、賽程種類等（單一資料的來源為空字串），只保留最近一批
+	lastSuccess time.Time
+}
+
+// SourceMonitor 記錄各上游資料來源最近一次抓取的狀態、耗時與錯誤
+type SourceMonitor struct {
+	mu     sync.RWMutex
+	states map[string]*sourceState
+}
+
+// NewSourceMonitor 建立資料來源狀態紀錄
+func NewSourceMonitor() *SourceMonitor {
+	return &SourceMonitor{states: make(map[string]*sourceState)}
+}
+
+// record 記錄一次抓取結果（呼叫端取消的請求不代表上游狀態，不記錄）
+func (m *SourceMonitor) record(ctx context.Context, name string, key string, start time.Time, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+	now := time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	state, ok := m.states[name]
+	if !ok {
+		state = &sourceState{results: make(map[string]fetchResult)}
+		m.states[name] = state
+	}
+	state.results[key] = fetchResult{latency: now.Sub(start), attempt: now, err: err}
+	// 狀態只看最新一筆前 sourceBatchWindow 內的結果，更早的不再需要（長時間執行的 server 不會一直累積）
+	for k, r := range state.results {
+		if r.attempt.Before(now.Add(-sourceBatchWindow)) {
+			delete(state.results, k)
+		}
+	}
+	if err == nil { // 斷路中的舊資料不算成功
+		state.lastSuccess = now
+	}
+}
+
+// Statuses 取得所有資料來源的狀態（依固定順序，m 為 nil 時回傳空列表）
+func (m *SourceMonitor) Statuses() []models.SourceStatus {
+	if m == nil {
+		return []models.SourceStatus{}
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	statuses := make([]models.SourceStatus, 0, len(sourceNames))
+	for _, name := range sourceNames {
+		status := models.SourceStatus{Name: name, Status: models.SourceIdle}
+		if state, ok := m.states[name]; ok {
+			status = state.status(name)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// status 彙整最近一批抓取的結果：其中任一項失敗即視為失敗，否則有舊資料即視為 stale
+func (s *sourceState) status(name string) models.SourceStatus {
+	var latest, latestErr fetchResult
+	for _, r := range s.results {
+		if r.attempt.After(latest.attempt) {
+			latest = r
+		}
+	}
+
+	failed, stale := 0, 0
+	for _, r := range s.results {
+		if r.err == nil || r.attempt.Before(latest.attempt.Add(-sourceBatchWindow)) {
+			continue
+		}
+		if IsStale(r.err) {
+			stale++
+		} else {
+			failed++
+		}
+		// 顯示最近一筆錯誤，真正失敗的優先於舊資料
+		switch {
+		case latestErr.err == nil:
+			latestErr = r
+		case IsStale(latestErr.err) != IsStale(r.err):
+			if !IsStale(r.err) {
+				latestErr = r
+			}
+		case r.attempt.After(latestErr.attempt):
+			latestErr = r
+		}
+	}
+
+	status := models.SourceStatus{
+		Name:        name,
+		Status:      models.SourceOK,
+		LatencyMs:   latest.latency.Milliseconds(),
+		LastAttempt: gametime.FormatUTC(latest.attempt),
+	}
+	if !s.lastSuccess.IsZero() {
+		status.LastSuccess = gametime.FormatUTC(s.lastSuccess)
+	}
+	switch {
+	case failed > 0:
+		status.Status = models.SourceError
+		status.Error = latestErr.err.Error()
+		if failed > 1 {
+			status.Error += fmt.Sprintf("（共 %d 筆失敗）", failed)
+		}
+	case stale > 0:
+		status.Status = models.SourceStale
+		status.Error = latestErr.err.Error()
+	}
+	return status
+}
+
+// dropStale 斷路中回傳的舊資料仍可使用：記錄狀態後不再當成錯誤往上傳
+func dropStale(err error) error {
+	if IsStale(err) {
+		return nil
+	}
+	return err
+}
+
+// monitor 包裝資料來源，記錄每次向上游抓取的結果
+// 包在最內層（直接包住上游），資料庫的備援資料不會掩蓋上游失敗；boxscore 快取在外層，命中快取不算一次抓取
+// 抓到的 boxscore 同時記錄兩隊球員名單（Roster）
+func monitor(src *Sources) *Sources {
+	m := NewSourceMonitor()
+	rosters := NewRosters()
+	schedule := &monitorSchedule{ScheduleSource: src.Schedule, monitor: m}
+	monitored := &Sources{
+		Schedule: schedule,
+		Odds:     &monitorOdds{OddsSource: src.Odds, monitor: m},
+		Boxscore: newBoxscoreCache(&rosterBoxscore{
+			BoxscoreSource: &monitorBoxscore{BoxscoreSource: src.Boxscore, monitor: m},
+			rosters:        rosters,
+		}),
+		Injury:   &monitorInjury{InjurySource: src.Injury, monitor: m},
+		Handicap: &monitorHandicap{HandicapSource: src.Handicap, monitor: m},
+		Roster:   rosters,
+		Monitor:  m,
+	}
+	monitored.CarrySeasons(src)
+	return monitored
+}
+
+// monitorSchedule 記錄賽程抓取狀態
+type monitorSchedule struct {
+	ScheduleSource
+	monitor *SourceMonitor
+}
+
+// FetchTodayScoreboard 抓取今日記分板
+func (s *monitorSchedule) FetchTodayScoreboard(ctx context.Context) (*models.NBAScoreboard, error) {
+	start := time.Now()
+	scoreboard, err := s.ScheduleSource.FetchTodayScoreboard(ctx)
+	s.monitor.record(ctx, SourceSchedule, "today", start, err)
+	return scoreboard, dropStale(err)
+}
+
+// FetchFullSchedule 抓取整季賽程
+func (s *monitorSchedule) FetchFullSchedule(ctx context.Context) (*models.FullSchedule, error) {
+	start := time.Now()
+	schedule, err := s.ScheduleSource.FetchFullSchedule(ctx)
+	s.monitor.record(ctx, SourceSchedule, "full", start, err)
+	return schedule, dropStale(err)
+}
+
+// FetchSeasonSchedule 抓取指定賽季的賽程
+func (s *monitorSchedule) FetchSeasonSchedule(ctx context.Context, sn season.Season) (*models.FullSchedule, error) {
+	start := time.Now()
+	schedule, err := s.ScheduleSource.FetchSeasonSchedule(ctx, sn)
+	s.monitor.record(ctx, SourceSchedule, "season/"+sn.String(), start, err)
+	return schedule, dropStale(err)
+}
+
+// FetchFullScheduleConditional 上游支援條件式請求時沿用，否則退回完整抓取
+func (s *monitorSchedule) FetchFullScheduleConditional(ctx context.Context, v CacheValidators) (*models.FullSchedule, CacheValidators, error) {
+	cs, ok := s.ScheduleSource.(ConditionalScheduleSource)
+	if !ok {
+		schedule, err := s.FetchFullSchedule(ctx)
+		return schedule, v, err
+	}
+
+	start := time.Now()
+	schedule, v, err := cs.FetchFullScheduleConditional(ctx, v)
+	s.monitor.record(ctx, SourceSchedule, "full", start, err) // 與完整抓取是同一份賽程
+	return schedule, v, dropStale(err)
+}
+
+// monitorOdds 記錄賠率抓取狀態
+type monitorOdds struct {
+	OddsSource
+	monitor *SourceMonitor
+}
+
+// FetchOdds 抓取今日賠率
+func (s *monitorOdds) FetchOdds(ctx context.Context) (*models.NBAOdds, error) {
+	start := time.Now()
+	odds, err := s.OddsSource.FetchOdds(ctx)
+	s.monitor.record(ctx, SourceOdds, "", start, err)
+	return odds, dropStale(err)
+}
+
+// monitorBoxscore 記錄 boxscore 抓取狀態
+type monitorBoxscore struct {
+	BoxscoreSource
+	monitor *SourceMonitor
+}
+
+// FetchBoxscore 抓取單場比賽的 boxscore
+func (s *monitorBoxscore) FetchBoxscore(ctx context.Context, gameID string) (*models.BoxscoreResponse, error) {
+	start := time.Now()
+	boxscore, err := s.BoxscoreSource.FetchBoxscore(ctx, gameID)
+	s.monitor.record(ctx, SourceBoxscore, gameID, start, err)
+	return boxscore, dropStale(err)
+}
+
+// monitorInjury 記錄傷兵抓取狀態
+type monitorInjury struct {
+	InjurySource
+	monitor *SourceMonitor
+}
+
+// FetchInjuryMap 抓取傷兵名單
+func (s *monitorInjury) FetchInjuryMap(ctx context.Context) (map[string][]models.Injury, error) {
+	start := time.Now()
+	injuryMap, err := s.InjurySource.FetchInjuryMap(ctx)
+	s.monitor.record(ctx, SourceInjuries, "", start, err)
+	return injuryMap, dropStale(err)
+}
+
+// monitorHandicap 記錄 titan007 抓取狀態
+type monitorHandicap struct {
+	HandicapSource
+	monitor *SourceMonitor
+}
+
+// FetchHandicapDetail 抓取球隊盤口戰績
+func (s *monitorHandicap) FetchHandicapDetail(ctx context.Context, teamID int, sn season.Season) ([]HandicapGame, error) {
+	start := time.Now()
+	games, err := s.HandicapSource.FetchHandicapDetail(ctx, teamID, sn)
+	s.monitor.record(ctx, SourceTitan007, fmt.Sprintf("HandicapDetail/%d/%s", teamID, sn), start, err)
+	return games, dropStale(err)
+}
+
+// FetchLetGoal 抓取整季過盤資料
+func (s *monitorHandicap) FetchLetGoal(ctx context.Context, sn season.Season) (map[string][]models.BetResult, error) {
+	start := time.Now()
+	spreads, err := s.HandicapSource.FetchLetGoal(ctx, sn)
+	s.monitor.record(ctx, SourceTitan007, "l1/"+sn.String(), start, err)
+	return spreads, dropStale(err)
+}
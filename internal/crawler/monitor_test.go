@@ -0,0 +1,25 @@
+package crawler
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSourceMonitorDropsResultsOutsideBatch(t *testing.T) {
+	m := NewSourceMonitor()
+	ctx := context.Background()
+	m.record(ctx, SourceBoxscore, "0022500001", time.Now(), nil)
+
+	// 模擬很久以前抓取過的比賽
+	m.states[SourceBoxscore].results["0022400001"] = fetchResult{attempt: time.Now().Add(-24 * time.Hour)}
+	m.record(ctx, SourceBoxscore, "0022500002", time.Now(), nil)
+
+	results := m.states[SourceBoxscore].results
+	if _, ok := results["0022400001"]; ok {
+		t.Error("超過一批時間的結果應被移除")
+	}
+	if len(results) != 2 {
+		t.Errorf("保留 %d 筆結果，預期 2 筆（同一批的比賽）", len(results))
+	}
+}
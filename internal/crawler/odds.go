@@ -1,32 +1,35 @@
 package crawler
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"nba-scanner/internal/models"
 )
 
-// FetchOdds 抓取今日賠率
-func FetchOdds() (*models.NBAOdds, error) {
-	url := "https://cdn.nba.com/static/json/liveData/odds/odds_todaysGames.json"
+const oddsURL = "https://cdn.nba.com/static/json/liveData/odds/odds_todaysGames.json"
 
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch odds: %w", err)
-	}
-	defer resp.Body.Close()
+// httpOddsSource 從 NBA CDN 抓取賠率
+type httpOddsSource struct {
+	up *upstream
+}
 
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("odds API returned status %d", resp.StatusCode)
+// FetchOdds 抓取今日賠率
+func (s *httpOddsSource) FetchOdds(ctx context.Context) (*models.NBAOdds, error) {
+	body, err := s.up.fetchBody(ctx, upstreamRequest{source: SourceOdds, label: "odds", url: oddsURL, staleKey: oddsURL})
+	if err != nil && !IsStale(err) {
+		return nil, err
 	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read odds response: %w", err)
+	// 斷路中回傳舊資料與 *StaleError，由 monitor 記錄狀態
+	odds, parseErr := parseOdds(body)
+	if parseErr != nil {
+		return nil, parseErr
 	}
+	return odds, err
+}
 
+// parseOdds 解析賠率 JSON
+func parseOdds(body []byte) (*models.NBAOdds, error) {
 	var odds models.NBAOdds
 	if err := json.Unmarshal(body, &odds); err != nil {
 		return nil, fmt.Errorf("failed to parse odds JSON: %w", err)
@@ -36,13 +39,28 @@ func FetchOdds() (*models.NBAOdds, error) {
 }
 
 // BuildOddsMap 建立 gameId -> SpreadInfo 的 map
+// 優先使用 Supermatch，沒有時改用第一個有讓分盤的莊家
 func BuildOddsMap(odds *models.NBAOdds) map[string]models.SpreadInfo {
 	oddsMap := make(map[string]models.SpreadInfo)
 
 	for _, game := range odds.Games {
-		spreadInfo := game.GetSupermatchSpread()
+		spreadInfo := game.GetPreferredSpread(models.SupermatchBookID)
 		oddsMap[game.GameID] = spreadInfo
 	}
 
 	return oddsMap
 }
+
+// BuildOddsGameMap 建立 gameId -> 完整賠率（所有莊家、所有市場）的 map
+func BuildOddsGameMap(odds *models.NBAOdds) map[string]models.OddsGame {
+	oddsMap := make(map[string]models.OddsGame)
+	if odds == nil {
+		return oddsMap
+	}
+
+	for _, game := range odds.Games {
+		oddsMap[game.GameID] = game
+	}
+
+	return oddsMap
+}
@@ -0,0 +1,115 @@
+package crawler
+
+import (
+	"context"
+	"nba-scanner/internal/models"
+	"strings"
+	"sync"
+)
+
+// accentFolder 將常見的拉丁字母變音符號轉為基本字母（NBA 使用 "Jokić"，ESPN 使用 "Jokic"）
+var accentFolder = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ä", "a", "ã", "a", "å", "a", "ā", "a",
+	"č", "c", "ć", "c", "ç", "c",
+	"ď", "d", "đ", "d",
+	"é", "e", "è", "e", "ê", "e", "ë", "e", "ē", "e", "ě", "e",
+	"ğ", "g", "ģ", "g",
+	"í", "i", "ì", "i", "î", "i", "ï", "i", "ī", "i", "ı", "i",
+	"ķ", "k", "ł", "l", "ļ", "l",
+	"ñ", "n", "ń", "n", "ň", "n", "ņ", "n",
+	"ó", "o", "ò", "o", "ô", "o", "ö", "o", "õ", "o", "ø", "o", "ő", "o",
+	"ř", "r", "š", "s", "ś", "s", "ş", "s", "ť", "t",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u", "ū", "u", "ů", "u", "ű", "u",
+	"ý", "y", "ž", "z", "ź", "z", "ż", "z",
+)
+
+// nameSuffixes 姓名比對時忽略的字尾
+var nameSuffixes = map[string]bool{"jr": true, "sr": true, "ii": true, "iii": true, "iv": true}
+
+// normalizePlayerName 正規化球員姓名：小寫、去除變音符號、標點與 Jr./III 等字尾
+func normalizePlayerName(name string) string {
+	name = accentFolder.Replace(strings.ToLower(name))
+	name = strings.NewReplacer(".", "", "'", "", "’", "", "-", " ", ",", " ").Replace(name)
+
+	words := strings.Fields(name)
+	for len(words) > 1 && nameSuffixes[words[len(words)-1]] {
+		words = words[:len(words)-1]
+	}
+	return strings.Join(words, " ")
+}
+
+// Rosters 由抓過的 boxscore 累積的各隊球員名單（記憶體中，沒有資料庫時使用）
+type Rosters struct {
+	mu    sync.RWMutex
+	teams map[int]map[string]int // map[teamID]map[球員姓名]personId
+}
+
+// NewRosters 建立空的球員名單
+func NewRosters() *Rosters {
+	return &Rosters{teams: make(map[int]map[string]int)}
+}
+
+// Remember 記錄 boxscore 中的球隊球員名單
+func (r *Rosters) Remember(team models.BoxscoreTeam) {
+	if team.TeamID == 0 || len(team.Players) == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	roster, ok := r.teams[team.TeamID]
+	if !ok {
+		roster = make(map[string]int)
+		r.teams[team.TeamID] = roster
+	}
+	for _, p := range team.Players {
+		roster[p.Name] = p.PersonID
+	}
+}
+
+// Roster 取得球隊的球員名單（回傳複本）
+func (r *Rosters) Roster(ctx context.Context, teamID int) (map[string]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	roster := make(map[string]int, len(r.teams[teamID]))
+	for name, personID := range r.teams[teamID] {
+		roster[name] = personID
+	}
+	return roster, nil
+}
+
+// rosterBoxscore 包裝 boxscore 來源，記錄每次抓到的兩隊球員名單
+type rosterBoxscore struct {
+	BoxscoreSource
+	rosters *Rosters
+}
+
+// FetchBoxscore 抓取 boxscore 並記錄球員名單
+func (s *rosterBoxscore) FetchBoxscore(ctx context.Context, gameID string) (*models.BoxscoreResponse, error) {
+	boxscore, err := s.BoxscoreSource.FetchBoxscore(ctx, gameID)
+	if boxscore != nil {
+		s.rosters.Remember(boxscore.Game.HomeTeam)
+		s.rosters.Remember(boxscore.Game.AwayTeam)
+	}
+	return boxscore, err
+}
+
+// LinkInjuries 依球員名單填入傷兵的 NBA personId（回傳新的 slice，不修改傳入的名單）
+func LinkInjuries(roster map[string]int, injuries []models.Injury) []models.Injury {
+	linked := make([]models.Injury, len(injuries))
+	copy(linked, injuries)
+	if len(roster) == 0 {
+		return linked
+	}
+
+	byName := make(map[string]int, len(roster))
+	for name, personID := range roster {
+		byName[normalizePlayerName(name)] = personID
+	}
+	for i := range linked {
+		if personID, ok := byName[normalizePlayerName(linked[i].PlayerName)]; ok {
+			linked[i].PersonID = personID
+		}
+	}
+	return linked
+}
@@ -0,0 +1,206 @@
+package crawler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"nba-scanner/internal/season"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+)
+
+const (
+	recordManifestFile = "manifest.jsonl" // 每行一筆 RecordEntry
+	recordBodiesDir    = "bodies"
+)
+
+// RecordEntry 錄製清單中的單筆上游回應
+type RecordEntry struct {
+	URL        string    `json:"url"`
+	FetchedAt  time.Time `json:"fetchedAt"`
+	StatusCode int       `json:"statusCode"`
+	Size       int       `json:"size"`
+	File       string    `json:"file"`              // bodies/ 下的原始回應檔
+	Fixture    string    `json:"fixture,omitempty"` // 對應的 fixture 檔名（可直接用 --fixtures 重播）
+}
+
+// Recorder 包裝 http.RoundTripper，將每個上游回應寫入磁碟
+// 錄製清單只附加在檔案後面，不保留在記憶體中（長時間錄製時不會越來越慢）
+type Recorder struct {
+	dir  string
+	next http.RoundTripper
+	mu   sync.Mutex // 保護 fixture 檔與清單檔的寫入
+}
+
+// NewRecorder 建立錄製器，next 為 nil 時使用與即時資料來源相同的連線池
+func NewRecorder(dir string, next http.RoundTripper) (*Recorder, error) {
+	if next == nil {
+		next = newTransport()
+	}
+	if err := os.MkdirAll(filepath.Join(dir, recordBodiesDir), 0o755); err != nil {
+		return nil, fmt.Errorf("無法建立錄製目錄: %w", err)
+	}
+
+	// 清單以附加方式寫入，同一目錄可以分多次錄製
+	return &Recorder{dir: dir, next: next}, nil
+}
+
+// NewRecordingSources 建立會錄製所有上游回應的即時資料來源（cfg.Transport 會被錄製器包住）
+func NewRecordingSources(dir string, cfg UpstreamConfig) (*Sources, error) {
+	recorder, err := NewRecorder(dir, cfg.Transport)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Transport = recorder
+	return NewLiveSources(cfg), nil
+}
+
+// RoundTrip 發送請求並保存回應內容
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := r.save(req.URL, resp.StatusCode, body); err != nil {
+		// 錄製失敗不影響正常請求
+		log.Printf("錄製上游回應失敗 (%s): %v", req.URL, err)
+	}
+
+	return resp, nil
+}
+
+// save 寫入回應內容，並在清單檔案後面附加一行
+func (r *Recorder) save(u *url.URL, statusCode int, body []byte) error {
+	now := time.Now()
+	entry := RecordEntry{
+		URL:        u.String(),
+		FetchedAt:  now,
+		StatusCode: statusCode,
+		Size:       len(body),
+		File:       path.Join(recordBodiesDir, fmt.Sprintf("%s_%s", now.Format("20060102T150405.000000000"), sanitizeRecordName(u))),
+	}
+
+	if err := os.WriteFile(filepath.Join(r.dir, entry.File), body, 0o644); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// 成功的回應同時寫成 fixture 檔名（最新的一筆覆蓋舊的）
+	if statusCode == 200 {
+		if name := fixtureNameForURL(u); name != "" {
+			if err := os.WriteFile(filepath.Join(r.dir, name), body, 0o644); err != nil {
+				return err
+			}
+			entry.Fixture = name
+		}
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(r.dir, recordManifestFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Entries 讀取錄製清單（包含同一目錄先前的錄製）
+func (r *Recorder) Entries() ([]RecordEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, err := os.Open(filepath.Join(r.dir, recordManifestFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []RecordEntry
+	dec := json.NewDecoder(f)
+	for dec.More() {
+		var entry RecordEntry
+		if err := dec.Decode(&entry); err != nil {
+			return nil, fmt.Errorf("無法解析錄製清單: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+var (
+	unsafeRecordChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+	boxscoreFileName  = regexp.MustCompile(`^boxscore_\d+\.json$`)
+)
+
+// sanitizeRecordName 將 URL 轉為安全的檔名
+func sanitizeRecordName(u *url.URL) string {
+	name := u.Host + u.Path
+	if u.RawQuery != "" {
+		name += "_" + u.RawQuery
+	}
+	name = unsafeRecordChars.ReplaceAllString(name, "_")
+	if len(name) > 150 {
+		name = name[:150]
+	}
+	return name
+}
+
+// fixtureNameForURL 取得上游 URL 對應的 fixture 檔名（見 fixture.go）
+func fixtureNameForURL(u *url.URL) string {
+	base := path.Base(u.Path)
+
+	switch {
+	case u.Host == "www.espn.com" && u.Path == "/nba/injuries":
+		return fixtureInjuries
+	case base == "HandicapDetail.aspx":
+		var teamID int
+		if _, err := fmt.Sscanf(u.Query().Get("teamid"), "%d", &teamID); err != nil {
+			return ""
+		}
+		return fixtureHandicapDetail(teamID)
+	case base == "scheduleleaguev2":
+		sn, err := season.Parse(u.Query().Get("Season"))
+		if err != nil {
+			return ""
+		}
+		return fixtureSeasonSchedule(sn)
+	case base == fixtureLetGoal,
+		base == fixtureTodaysScoreboard,
+		base == fixtureFullSchedule,
+		base == fixtureOdds:
+		return base
+	case boxscoreFileName.MatchString(base):
+		return base
+	}
+
+	return ""
+}
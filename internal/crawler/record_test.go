@@ -0,0 +1,53 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecorderAppendsManifest(t *testing.T) {
+	dir := t.TempDir()
+	rt := &stubTransport{statuses: []int{200}, body: testOddsBody}
+	recorder, err := NewRecorder(dir, rt)
+	if err != nil {
+		t.Fatalf("建立錄製器失敗: %v", err)
+	}
+
+	client := &http.Client{Transport: recorder}
+	for i := 0; i < 3; i++ {
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, oddsURL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("請求失敗: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, recordManifestFile))
+	if err != nil {
+		t.Fatalf("讀取錄製清單失敗: %v", err)
+	}
+	if lines := bytes.Count(data, []byte("\n")); lines != 3 {
+		t.Errorf("錄製清單有 %d 行，預期 3", lines)
+	}
+	if fixture, err := os.ReadFile(filepath.Join(dir, fixtureOdds)); err != nil || string(fixture) != testOddsBody {
+		t.Errorf("fixture 內容 = %q, %v", fixture, err)
+	}
+
+	// 同一目錄再次錄製時沿用既有清單
+	reopened, err := NewRecorder(dir, rt)
+	if err != nil {
+		t.Fatalf("重新開啟錄製器失敗: %v", err)
+	}
+	entries, err := reopened.Entries()
+	if err != nil {
+		t.Fatalf("讀取錄製清單失敗: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Errorf("沿用的清單有 %d 筆，預期 3", len(entries))
+	}
+}
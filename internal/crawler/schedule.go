@@ -1,66 +1,138 @@
 package crawler
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
+	"nba-scanner/internal/gametime"
 	"nba-scanner/internal/models"
+	"nba-scanner/internal/season"
+	"net/http"
 	"time"
 )
 
-// FetchSchedule 抓取今日賽程
-func FetchSchedule() (*models.NBAScoreboard, error) {
-	url := "https://nba-prod-us-east-1-mediaops-stats.s3.amazonaws.com/NBA/liveData/scoreboard/todaysScoreboard_00.json"
+const (
+	todaysScoreboardURL = "https://nba-prod-us-east-1-mediaops-stats.s3.amazonaws.com/NBA/liveData/scoreboard/todaysScoreboard_00.json"
+	fullScheduleURL     = "https://cdn.nba.com/static/json/staticData/scheduleLeagueV2_9.json"
+)
+
+// seasonScheduleURL 指定賽季的完整賽程（stats.nba.com，回應格式與 scheduleLeagueV2_9.json 相同）
+func seasonScheduleURL(s season.Season) string {
+	return fmt.Sprintf("https://stats.nba.com/stats/scheduleleaguev2?LeagueID=00&Season=%s", s)
+}
+
+// statsHeader stats.nba.com 需要瀏覽器的 headers 才會回應
+func statsHeader() http.Header {
+	header := http.Header{}
+	header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	header.Set("Accept", "application/json, text/plain, */*")
+	header.Set("Origin", "https://www.nba.com")
+	header.Set("Referer", "https://www.nba.com/")
+	return header
+}
+
+// httpScheduleSource 從 NBA CDN 抓取賽程
+type httpScheduleSource struct {
+	up *upstream
+}
+
+// FetchTodayScoreboard 抓取今日賽程
+func (s *httpScheduleSource) FetchTodayScoreboard(ctx context.Context) (*models.NBAScoreboard, error) {
+	body, err := s.up.fetchBody(ctx, upstreamRequest{source: SourceSchedule, label: "schedule", url: todaysScoreboardURL, staleKey: todaysScoreboardURL})
+	if err != nil && !IsStale(err) {
+		return nil, err
+	}
+	// 斷路中回傳舊資料與 *StaleError，由 monitor 記錄狀態
+	scoreboard, parseErr := parseScoreboard(body)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	return scoreboard, err
+}
 
-	resp, err := http.Get(url)
+// FetchFullSchedule 抓取完整賽季賽程
+func (s *httpScheduleSource) FetchFullSchedule(ctx context.Context) (*models.FullSchedule, error) {
+	body, err := s.up.fetchBody(ctx, upstreamRequest{source: SourceSchedule, label: "full schedule", url: fullScheduleURL, staleKey: fullScheduleURL})
+	if err != nil && !IsStale(err) {
+		return nil, err
+	}
+	// 斷路中回傳舊資料與 *StaleError，由 monitor 記錄狀態
+	schedule, parseErr := parseFullSchedule(body)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	return schedule, err
+}
+
+// FetchSeasonSchedule 抓取指定賽季的完整賽程
+func (s *httpScheduleSource) FetchSeasonSchedule(ctx context.Context, sn season.Season) (*models.FullSchedule, error) {
+	body, err := s.up.fetchBody(ctx, upstreamRequest{source: SourceSchedule, label: "season schedule", url: seasonScheduleURL(sn), header: statsHeader()})
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch schedule: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
+	return parseFullSchedule(body)
+}
 
+// FetchFullScheduleConditional 以 ETag/If-Modified-Since 條件式抓取完整賽季賽程
+// 上游回傳 304 時 schedule 為 nil，代表沿用既有資料
+func (s *httpScheduleSource) FetchFullScheduleConditional(ctx context.Context, v CacheValidators) (*models.FullSchedule, CacheValidators, error) {
+	header := http.Header{}
+	if v.ETag != "" {
+		header.Set("If-None-Match", v.ETag)
+	}
+	if v.LastModified != "" {
+		header.Set("If-Modified-Since", v.LastModified)
+	}
+
+	resp, err := s.up.fetch(ctx, upstreamRequest{
+		source:   SourceSchedule,
+		label:    "full schedule",
+		url:      fullScheduleURL,
+		header:   header,
+		staleKey: fullScheduleURL,
+	})
+	if err != nil && !IsStale(err) {
+		return nil, v, err
+	}
+	// 斷路中沿用既有資料（ScheduleStore 已有索引時不需要舊的回應內容）
+	if resp.StatusCode == http.StatusNotModified || (IsStale(err) && v.ETag != "") {
+		return nil, v, err
+	}
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("schedule API returned status %d", resp.StatusCode)
+		return nil, v, fmt.Errorf("full schedule API returned status %d", resp.StatusCode)
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read schedule response: %w", err)
+	schedule, parseErr := parseFullSchedule(resp.Body)
+	if parseErr != nil {
+		return nil, v, parseErr
 	}
 
+	return schedule, CacheValidators{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}, err
+}
+
+// parseScoreboard 解析今日記分板 JSON
+func parseScoreboard(body []byte) (*models.NBAScoreboard, error) {
 	var scoreboard models.NBAScoreboard
 	if err := json.Unmarshal(body, &scoreboard); err != nil {
 		return nil, fmt.Errorf("failed to parse schedule JSON: %w", err)
 	}
-
 	return &scoreboard, nil
 }
 
-// ConvertUTCToLocal 將 EST 時間轉為台北時間
-// NBA API 的 GameTimeUTC 欄位名稱有誤導性，實際是 EST 時間（UTC-4）
-func ConvertUTCToLocal(estTimeStr string) (string, error) {
-	// 解析時間（移除 Z，因為這不是真正的 UTC 時間）
-	estTimeNoZ := estTimeStr
-	if len(estTimeStr) > 0 && estTimeStr[len(estTimeStr)-1] == 'Z' {
-		estTimeNoZ = estTimeStr[:len(estTimeStr)-1]
+// parseFullSchedule 解析完整賽季賽程 JSON
+func parseFullSchedule(body []byte) (*models.FullSchedule, error) {
+	var schedule models.FullSchedule
+	if err := json.Unmarshal(body, &schedule); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
 	}
+	return &schedule, nil
+}
 
-	// 解析為無時區的時間
-	t, err := time.Parse("2006-01-02T15:04:05", estTimeNoZ)
-	if err != nil {
-		return "", err
-	}
-
-	// 將這個時間視為 EST 時區（UTC-4 夏令時）
-	estLocation := time.FixedZone("EST", -4*60*60)
-	tEST := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, estLocation)
-
-	// 轉換為台北時區 (UTC+8)
-	loc, err := time.LoadLocation("Asia/Taipei")
-	if err != nil {
-		loc = time.FixedZone("CST", 8*60*60)
-	}
-	tTaipei := tEST.In(loc)
-
-	return tTaipei.Format("15:04"), nil
+// ConvertUTCToLocal 將開賽時間（UTC，如 "2025-12-08T00:30:00Z"）轉為指定時區的 "15:04"
+// models.Game.GameTimeUTC 一律為真正的 UTC 時間（整季賽程的美東時間在 FetchScheduleForDate 轉換）
+func ConvertUTCToLocal(utcTimeStr string, loc *time.Location) (string, error) {
+	return gametime.Local(utcTimeStr, loc)
 }
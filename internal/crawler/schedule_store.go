@@ -0,0 +1,309 @@
+package crawler
+
+import (
+	"context"
+	"log"
+	"nba-scanner/internal/models"
+	"nba-scanner/internal/season"
+	"sync"
+	"time"
+)
+
+// CacheValidators HTTP 條件式請求使用的 ETag / Last-Modified
+type CacheValidators struct {
+	ETag         string
+	LastModified string
+}
+
+// ConditionalScheduleSource 支援條件式請求的賽程來源（即時 API 才有）
+type ConditionalScheduleSource interface {
+	FetchFullScheduleConditional(ctx context.Context, v CacheValidators) (*models.FullSchedule, CacheValidators, error)
+}
+
+// ScheduleStore 整季賽程的共用快取
+// scheduleLeagueV2_9.json 有數 MB，整個程序只保留一份並建立索引，
+// 所有需要賽程的地方都從這裡讀取
+type ScheduleStore struct {
+	source  ScheduleSource
+	maxAge  time.Duration
+	mu      sync.RWMutex
+	loadMu  sync.Mutex // 避免同時重複下載
+	loaded  bool
+	fetched time.Time
+	valid   CacheValidators
+
+	byDate   map[string][]models.ScheduledGame // "2006-01-02"（NBA 比賽日）-> 比賽
+	byTeam   map[int][]models.ScheduledGame    // teamID -> 比賽（依日期由舊到新）
+	byGameID map[string]models.ScheduledGame
+
+	season   season.Season   // 由 GameID 判斷的賽季
+	calendar season.Calendar // 由賽程建立的聯盟行事曆
+}
+
+// NewScheduleStore 建立整季賽程快取
+// maxAge 為資料過期時間，過期後下一次讀取會重新向上游確認
+func NewScheduleStore(source ScheduleSource, maxAge time.Duration) *ScheduleStore {
+	return &ScheduleStore{
+		source: source,
+		maxAge: maxAge,
+	}
+}
+
+// rebase 建立改由 source 抓取的賽程快取，沿用原本的快取時間與已載入的賽程
+func (s *ScheduleStore) rebase(source ScheduleSource) *ScheduleStore {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return &ScheduleStore{
+		source:   source,
+		maxAge:   s.maxAge,
+		loaded:   s.loaded,
+		fetched:  s.fetched,
+		valid:    s.valid,
+		byDate:   s.byDate,
+		byTeam:   s.byTeam,
+		byGameID: s.byGameID,
+		season:   s.season,
+		calendar: s.calendar,
+	}
+}
+
+// StartRefresh 在背景定期更新賽程（server 模式使用），ctx 取消時停止
+func (s *ScheduleStore) StartRefresh(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if err := s.Refresh(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("背景更新賽程失敗: %v", err)
+			}
+		}
+	}()
+}
+
+// Refresh 向上游確認並更新賽程（支援 ETag/If-Modified-Since 時只在有變更才重新解析）
+func (s *ScheduleStore) Refresh(ctx context.Context) error {
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+
+	return s.refreshLocked(ctx)
+}
+
+// refreshLocked 實際更新賽程，呼叫前需持有 loadMu
+func (s *ScheduleStore) refreshLocked(ctx context.Context) error {
+	s.mu.RLock()
+	valid := s.valid
+	loaded := s.loaded
+	s.mu.RUnlock()
+
+	var (
+		schedule *models.FullSchedule
+		err      error
+	)
+	if cs, ok := s.source.(ConditionalScheduleSource); ok {
+		if !loaded {
+			valid = CacheValidators{}
+		}
+		schedule, valid, err = cs.FetchFullScheduleConditional(ctx, valid)
+	} else {
+		schedule, err = s.source.FetchFullSchedule(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	if schedule == nil {
+		// 304 Not Modified：沿用既有資料
+		s.mu.Lock()
+		s.fetched = time.Now()
+		s.mu.Unlock()
+		return nil
+	}
+
+	s.index(schedule, valid)
+	return nil
+}
+
+// index 建立日期、球隊、比賽 ID 索引
+func (s *ScheduleStore) index(schedule *models.FullSchedule, valid CacheValidators) {
+	byDate := make(map[string][]models.ScheduledGame)
+	byTeam := make(map[int][]models.ScheduledGame)
+	byGameID := make(map[string]models.ScheduledGame)
+
+	for _, gameDate := range schedule.LeagueSchedule.GameDates {
+		// API 日期格式: "10/21/2025 00:00:00"
+		date, err := time.Parse("01/02/2006 15:04:05", gameDate.GameDate)
+		if err != nil {
+			continue
+		}
+		key := date.Format("2006-01-02")
+
+		for _, game := range gameDate.Games {
+			byDate[key] = append(byDate[key], game)
+			byTeam[game.HomeTeam.TeamID] = append(byTeam[game.HomeTeam.TeamID], game)
+			byTeam[game.AwayTeam.TeamID] = append(byTeam[game.AwayTeam.TeamID], game)
+			byGameID[game.GameID] = game
+		}
+	}
+
+	sn, ok := scheduleSeason(schedule)
+	if !ok {
+		// 賽程是空的（例如新賽季賽程尚未公布），以目前日期推算
+		sn = season.Current()
+	}
+
+	s.mu.Lock()
+	s.byDate = byDate
+	s.byTeam = byTeam
+	s.byGameID = byGameID
+	s.season = sn
+	s.calendar = season.NewCalendar(sn, schedule)
+	s.valid = valid
+	s.loaded = true
+	s.fetched = time.Now()
+	s.mu.Unlock()
+
+	log.Printf("賽程快取已更新（%s 賽季），共 %d 個比賽日、%d 場比賽", sn, len(byDate), len(byGameID))
+}
+
+// scheduleSeason 由第一場比賽的 GameID 判斷賽程所屬的賽季
+func scheduleSeason(schedule *models.FullSchedule) (season.Season, bool) {
+	for _, gameDate := range schedule.LeagueSchedule.GameDates {
+		for _, game := range gameDate.Games {
+			if sn, ok := season.ForGameID(game.GameID); ok {
+				return sn, true
+			}
+		}
+	}
+	return season.Season{}, false
+}
+
+// ensureFresh 第一次讀取時載入賽程，資料過期時重新確認
+func (s *ScheduleStore) ensureFresh(ctx context.Context) error {
+	s.mu.RLock()
+	fresh := s.loaded && (s.maxAge <= 0 || time.Since(s.fetched) < s.maxAge)
+	s.mu.RUnlock()
+	if fresh {
+		return nil
+	}
+
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+
+	// 等待鎖的期間可能已被其他 goroutine 更新
+	s.mu.RLock()
+	fresh = s.loaded && (s.maxAge <= 0 || time.Since(s.fetched) < s.maxAge)
+	loaded := s.loaded
+	s.mu.RUnlock()
+	if fresh {
+		return nil
+	}
+
+	if err := s.refreshLocked(ctx); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if loaded {
+			// 已有舊資料時繼續使用，避免上游短暫失敗導致整個頁面失敗
+			log.Printf("更新賽程失敗，沿用舊資料: %v", err)
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// GamesOn 取得指定 NBA 比賽日（美東日期）的所有比賽
+func (s *ScheduleStore) GamesOn(ctx context.Context, date time.Time) ([]models.ScheduledGame, error) {
+	if err := s.ensureFresh(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	games := s.byDate[date.Format("2006-01-02")]
+	return append([]models.ScheduledGame(nil), games...), nil
+}
+
+// TeamGames 取得球隊整季的比賽（依日期由舊到新）
+func (s *ScheduleStore) TeamGames(ctx context.Context, teamID int) ([]models.ScheduledGame, error) {
+	if err := s.ensureFresh(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	games := s.byTeam[teamID]
+	return append([]models.ScheduledGame(nil), games...), nil
+}
+
+// Game 以比賽 ID 取得比賽
+func (s *ScheduleStore) Game(ctx context.Context, gameID string) (models.ScheduledGame, bool, error) {
+	if err := s.ensureFresh(ctx); err != nil {
+		return models.ScheduledGame{}, false, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	game, ok := s.byGameID[gameID]
+	return game, ok, nil
+}
+
+// Season 取得賽程所屬的賽季
+func (s *ScheduleStore) Season(ctx context.Context) (season.Season, error) {
+	if err := s.ensureFresh(ctx); err != nil {
+		return season.Season{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.season, nil
+}
+
+// Calendar 取得由賽程建立的聯盟行事曆
+func (s *ScheduleStore) Calendar(ctx context.Context) (season.Calendar, error) {
+	if err := s.ensureFresh(ctx); err != nil {
+		return season.Calendar{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.calendar, nil
+}
+
+// LastGameDay 取得不晚於 day 的最後一個比賽日，沒有時回傳 false
+func (s *ScheduleStore) LastGameDay(ctx context.Context, day time.Time) (time.Time, bool, error) {
+	if err := s.ensureFresh(ctx); err != nil {
+		return time.Time{}, false, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	limit := day.Format("2006-01-02")
+	last := ""
+	for key := range s.byDate {
+		if key <= limit && key > last {
+			last = key
+		}
+	}
+	if last == "" {
+		return time.Time{}, false, nil
+	}
+
+	date, err := time.Parse("2006-01-02", last)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return date, true, nil
+}
@@ -0,0 +1,161 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"nba-scanner/internal/models"
+	"nba-scanner/internal/season"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stubSchedule 以 GameID 前綴決定賽季的假賽程來源，記錄抓取次數
+type stubSchedule struct {
+	mu      sync.Mutex
+	current season.Season
+	full    int
+	seasons map[season.Season]int
+	err     error // 不為 nil 時整季賽程抓取失敗
+}
+
+func (s *stubSchedule) FetchTodayScoreboard(ctx context.Context) (*models.NBAScoreboard, error) {
+	return &models.NBAScoreboard{}, nil
+}
+
+func (s *stubSchedule) FetchFullSchedule(ctx context.Context) (*models.FullSchedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.full++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return testSchedule(s.current), nil
+}
+
+func (s *stubSchedule) FetchSeasonSchedule(ctx context.Context, sn season.Season) (*models.FullSchedule, error) {
+	s.mu.Lock()
+	if s.seasons == nil {
+		s.seasons = make(map[season.Season]int)
+	}
+	s.seasons[sn]++
+	s.mu.Unlock()
+	return testSchedule(sn), nil
+}
+
+// testSchedule 指定賽季只有一場例行賽的賽程
+func testSchedule(sn season.Season) *models.FullSchedule {
+	schedule := &models.FullSchedule{}
+	schedule.LeagueSchedule.GameDates = []models.GameDate{{
+		GameDate: "11/01/" + sn.String()[:4] + " 00:00:00",
+		Games:    []models.ScheduledGame{{GameID: "002" + sn.String()[2:4] + "00001"}},
+	}}
+	return schedule
+}
+
+func TestCarrySeasonsKeepsLoadedSchedules(t *testing.T) {
+	ctx := context.Background()
+	current, _ := season.Parse("2025-26")
+	past := current.Previous()
+	stub := &stubSchedule{current: current}
+
+	src := monitor(&Sources{Schedule: stub, Season: NewScheduleStore(stub, 0)})
+	if sn, err := src.Season.Season(ctx); err != nil || sn != current {
+		t.Fatalf("目前賽季 = %s, %v，預期 %s", sn, err, current)
+	}
+	if sn, err := src.ScheduleFor(ctx, past).Season(ctx); err != nil || sn != past {
+		t.Fatalf("過去賽季 = %s, %v，預期 %s", sn, err, past)
+	}
+
+	wrapped := &Sources{Schedule: &monitorSchedule{ScheduleSource: src.Schedule, monitor: src.Monitor}}
+	wrapped.CarrySeasons(src)
+
+	if sn, err := wrapped.Season.Season(ctx); err != nil || sn != current {
+		t.Errorf("包裝後目前賽季 = %s, %v，預期 %s", sn, err, current)
+	}
+	if sn, err := wrapped.ScheduleFor(ctx, past).Season(ctx); err != nil || sn != past {
+		t.Errorf("包裝後過去賽季 = %s, %v，預期 %s", sn, err, past)
+	}
+	if stub.full != 1 || stub.seasons[past] != 1 {
+		t.Errorf("包裝後重新下載賽程（整季 %d 次、過去賽季 %d 次），預期各 1 次", stub.full, stub.seasons[past])
+	}
+}
+
+func TestScheduleStoreSharesOneDownload(t *testing.T) {
+	ctx := context.Background()
+	current, _ := season.Parse("2025-26")
+	stub := &stubSchedule{current: current}
+	store := NewScheduleStore(stub, time.Hour)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			store.GamesOn(ctx, time.Date(2025, time.November, 1, 0, 0, 0, 0, time.UTC))
+			store.Game(ctx, "0022500001")
+		}()
+	}
+	wg.Wait()
+
+	if stub.full != 1 {
+		t.Errorf("同時查詢下載了 %d 次賽程，預期 1 次", stub.full)
+	}
+	games, err := store.GamesOn(ctx, time.Date(2025, time.November, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil || len(games) != 1 || games[0].GameID != "0022500001" {
+		t.Errorf("GamesOn = %+v, %v", games, err)
+	}
+	if _, ok, _ := store.Game(ctx, "0022500001"); !ok {
+		t.Error("Game 找不到賽程中的比賽")
+	}
+}
+
+func TestScheduleStoreExpiry(t *testing.T) {
+	current, _ := season.Parse("2025-26")
+	tests := []struct {
+		name      string
+		maxAge    time.Duration
+		failAfter bool // 第一次載入後上游失敗
+		wantFull  int
+		wantErr   bool
+	}{
+		{"不過期", 0, false, 1, false},
+		{"快取時間內不重新下載", time.Hour, false, 1, false},
+		{"過期後重新下載", time.Millisecond, false, 2, false},
+		{"過期後上游失敗沿用舊資料", time.Millisecond, true, 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			stub := &stubSchedule{current: current}
+			store := NewScheduleStore(stub, tt.maxAge)
+
+			if _, err := store.Season(ctx); err != nil {
+				t.Fatalf("載入賽程失敗: %v", err)
+			}
+			if tt.failAfter {
+				stub.err = errors.New("上游錯誤")
+			}
+			time.Sleep(5 * time.Millisecond)
+
+			sn, err := store.Season(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Season 錯誤 = %v，預期錯誤 %v", err, tt.wantErr)
+			}
+			if sn != current {
+				t.Errorf("賽季 = %s，預期 %s", sn, current)
+			}
+			if stub.full != tt.wantFull {
+				t.Errorf("下載了 %d 次賽程，預期 %d 次", stub.full, tt.wantFull)
+			}
+		})
+	}
+}
+
+func TestScheduleStoreFirstLoadError(t *testing.T) {
+	stub := &stubSchedule{err: errors.New("上游錯誤")}
+	store := NewScheduleStore(stub, 0)
+	if _, err := store.GamesOn(context.Background(), time.Now()); err == nil {
+		t.Error("沒有舊資料時應回傳上游錯誤")
+	}
+}
@@ -0,0 +1,173 @@
+package crawler
+
+import (
+	"context"
+	"nba-scanner/internal/models"
+	"nba-scanner/internal/season"
+	"sync"
+	"time"
+)
+
+// ScheduleSource 賽程資料來源
+type ScheduleSource interface {
+	// FetchTodayScoreboard 取得今日即時記分板（todaysScoreboard_00.json）
+	FetchTodayScoreboard(ctx context.Context) (*models.NBAScoreboard, error)
+	// FetchFullSchedule 取得目前賽季的完整賽程（scheduleLeagueV2_9.json）
+	FetchFullSchedule(ctx context.Context) (*models.FullSchedule, error)
+	// FetchSeasonSchedule 取得指定賽季的完整賽程（用於查詢過去賽季）
+	FetchSeasonSchedule(ctx context.Context, s season.Season) (*models.FullSchedule, error)
+}
+
+// OddsSource 賠率資料來源
+type OddsSource interface {
+	// FetchOdds 取得今日賠率（odds_todaysGames.json）
+	FetchOdds(ctx context.Context) (*models.NBAOdds, error)
+}
+
+// ClosingTotalSource 可查詢過去比賽大小分盤口的賠率來源（有資料庫時才有）
+// odds_todaysGames.json 只有當天的比賽，近期戰績的大小分需要從保存的快照取得
+type ClosingTotalSource interface {
+	// ClosingTotal 取得比賽開賽前最後的大小分盤口
+	ClosingTotal(gameID string) (float64, bool)
+}
+
+// BoxscoreSource 比賽數據資料來源
+type BoxscoreSource interface {
+	// FetchBoxscore 取得單場比賽的 boxscore
+	FetchBoxscore(ctx context.Context, gameID string) (*models.BoxscoreResponse, error)
+}
+
+// InjurySource 傷兵資料來源
+type InjurySource interface {
+	// FetchInjuryMap 取得各隊傷兵清單（key 為 ESPN 隊名）
+	FetchInjuryMap(ctx context.Context) (map[string][]models.Injury, error)
+}
+
+// HandicapSource titan007 盤口資料來源
+type HandicapSource interface {
+	// FetchHandicapDetail 取得球隊在指定賽季 HandicapDetail 頁面的盤口戰績
+	FetchHandicapDetail(ctx context.Context, teamID int, s season.Season) ([]HandicapGame, error)
+	// FetchLetGoal 取得指定賽季 l1.js 的整季過盤資料
+	FetchLetGoal(ctx context.Context, s season.Season) (map[string][]models.BetResult, error)
+}
+
+// RosterSource 球員名單來源（ESPN 傷兵對應 NBA personId）
+type RosterSource interface {
+	// Roster 取得球隊的球員名單（球員姓名 -> personId，沒有資料時為空的 map）
+	Roster(ctx context.Context, teamID int) (map[string]int, error)
+}
+
+// Sources 所有上游資料來源的集合，由呼叫端注入
+type Sources struct {
+	Schedule ScheduleSource
+	Odds     OddsSource
+	Boxscore BoxscoreSource
+	Injury   InjurySource
+	Handicap HandicapSource
+	Roster   RosterSource
+
+	// Season 目前賽季整季賽程的共用快取（建立在 Schedule 之上）
+	Season *ScheduleStore
+
+	// Monitor 各上游資料來源最近一次抓取的狀態（APIResponse.Sources）
+	Monitor *SourceMonitor
+
+	pastMu sync.Mutex
+	past   map[season.Season]*ScheduleStore // 過去賽季的賽程快取（第一次查詢時建立）
+}
+
+// ScheduleFor 取得指定賽季的賽程快取
+// 目前賽季使用 Season，其他賽季另外建立（過去賽季的賽程不會變動，不需過期）
+// 目前賽季的賽程無法取得時，以今天的日期判斷目前賽季（Season 的錯誤由之後的查詢回傳）
+func (s *Sources) ScheduleFor(ctx context.Context, sn season.Season) *ScheduleStore {
+	current, err := s.Season.Season(ctx)
+	if err != nil {
+		current = season.For(time.Now())
+	}
+	if current == sn {
+		return s.Season
+	}
+
+	s.pastMu.Lock()
+	defer s.pastMu.Unlock()
+
+	if s.past == nil {
+		s.past = make(map[season.Season]*ScheduleStore)
+	}
+	store, ok := s.past[sn]
+	if !ok {
+		store = NewScheduleStore(&seasonSchedule{ScheduleSource: s.Schedule, season: sn}, 0)
+		s.past[sn] = store
+	}
+	return store
+}
+
+// CarrySeasons 沿用 src 目前賽季與過去賽季的賽程快取，之後改由 s.Schedule 抓取
+// 包裝資料來源（monitor、store.Persist）時使用，避免重新下載已載入的賽程
+func (s *Sources) CarrySeasons(src *Sources) {
+	s.Season = src.Season.rebase(s.Schedule)
+
+	src.pastMu.Lock()
+	defer src.pastMu.Unlock()
+	s.pastMu.Lock()
+	defer s.pastMu.Unlock()
+	for sn, store := range src.past {
+		if s.past == nil {
+			s.past = make(map[season.Season]*ScheduleStore)
+		}
+		s.past[sn] = store.rebase(&seasonSchedule{ScheduleSource: s.Schedule, season: sn})
+	}
+}
+
+// SeasonFor 取得 NBA 比賽日所屬的賽季，與賽季階段使用同一份行事曆（目前賽季的整季賽程）
+// 賽程無法取得時以日期判斷（7 月起算新賽季）
+func (s *Sources) SeasonFor(ctx context.Context, day time.Time) season.Season {
+	cal, err := s.Season.Calendar(ctx)
+	if err != nil {
+		return season.For(day)
+	}
+	return cal.SeasonOf(day)
+}
+
+// seasonSchedule 把指定賽季的賽程包裝成 ScheduleSource（過去賽季的 ScheduleStore 使用）
+type seasonSchedule struct {
+	ScheduleSource
+	season season.Season
+}
+
+// FetchFullSchedule 抓取指定賽季的賽程
+func (s *seasonSchedule) FetchFullSchedule(ctx context.Context) (*models.FullSchedule, error) {
+	return s.FetchSeasonSchedule(ctx, s.season)
+}
+
+// seasonScheduleMaxAge 整季賽程的快取時間
+const seasonScheduleMaxAge = 10 * time.Minute
+
+// NewLiveSources 建立直接向上游 API 抓取資料的來源
+// 所有來源共用同一個上游 client（逾時、重試、斷路器、連線重用）
+func NewLiveSources(cfg UpstreamConfig) *Sources {
+	up := newUpstream(cfg)
+	schedule := &httpScheduleSource{up: up}
+	return monitor(&Sources{
+		Schedule: schedule,
+		Odds:     &httpOddsSource{up: up},
+		Boxscore: &httpBoxscoreSource{up: up},
+		Injury:   &httpInjurySource{up: up},
+		Handicap: &httpHandicapSource{up: up},
+		Season:   NewScheduleStore(schedule, seasonScheduleMaxAge),
+	})
+}
+
+// NewFixtureSources 建立從本機目錄讀取錄製資料的來源
+func NewFixtureSources(dir string) *Sources {
+	f := &fixtureDir{dir: dir}
+	schedule := &fixtureScheduleSource{f}
+	return monitor(&Sources{
+		Schedule: schedule,
+		Odds:     &fixtureOddsSource{f},
+		Boxscore: &fixtureBoxscoreSource{f},
+		Injury:   &fixtureInjurySource{f},
+		Handicap: &fixtureHandicapSource{f},
+		Season:   NewScheduleStore(schedule, 0), // 錄製資料不會變動，不需過期
+	})
+}
@@ -1,10 +1,14 @@
 package crawler
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
+	"nba-scanner/internal/models"
+	"nba-scanner/internal/season"
+	"nba-scanner/internal/settlement"
+	"nba-scanner/internal/teams"
 	"net/http"
 	"regexp"
 	"strings"
@@ -12,40 +16,6 @@ import (
 	"time"
 )
 
-// Titan007TeamIDMap NBA 球隊 ID 映射表 (Titan007 TeamID -> NBA 英文名稱)
-var Titan007TeamIDMap = map[int]string{
-	1:  "Los Angeles Lakers",
-	2:  "Boston Celtics",
-	3:  "Miami Heat",
-	4:  "Brooklyn Nets",
-	5:  "New York Knicks",
-	6:  "Orlando Magic",
-	7:  "Philadelphia 76ers",
-	8:  "Washington Wizards",
-	9:  "Detroit Pistons",
-	10: "Indiana Pacers",
-	11: "New Orleans Pelicans",
-	12: "Milwaukee Bucks",
-	13: "Atlanta Hawks",
-	14: "Chicago Bulls",
-	15: "Toronto Raptors",
-	16: "Cleveland Cavaliers",
-	17: "Dallas Mavericks",
-	18: "San Antonio Spurs",
-	19: "Minnesota Timberwolves",
-	20: "Utah Jazz",
-	21: "Houston Rockets",
-	22: "Memphis Grizzlies",
-	23: "Denver Nuggets",
-	24: "Sacramento Kings",
-	25: "Portland Trail Blazers",
-	26: "Phoenix Suns",
-	27: "Golden State Warriors",
-	28: "Oklahoma City Thunder",
-	29: "Los Angeles Clippers",
-	30: "Charlotte Hornets",
-}
-
 // Titan007HandicapCache 快取 titan007 盤口戰績資料
 var (
 	titan007HandicapCache      map[string][]string // map[teamName] = ["W", "L", "W", "W", "L"]
@@ -68,48 +38,36 @@ type HandicapGame struct {
 
 // HandicapResultWithSpread 盤口結果（含盤口數值）
 type HandicapResultWithSpread struct {
-	Result      string  // "W" 或 "L"
-	SpreadValue float64 // 盤口數值（從查詢球隊的角度）
+	Result      models.BetResult // W/HW/P/HL/L
+	SpreadValue float64          // 盤口數值（從查詢球隊的角度）
 }
 
-// FetchTitan007TeamHandicap 從 HandicapDetail 頁面抓取球隊盤口戰績（只返回 W/L）
-func FetchTitan007TeamHandicap(teamNameEN string, limit int) ([]string, error) {
-	results, err := FetchTitan007TeamHandicapWithSpread(teamNameEN, limit)
+// FetchTitan007TeamHandicap 從 HandicapDetail 頁面抓取球隊盤口戰績（只返回過盤結果）
+func FetchTitan007TeamHandicap(ctx context.Context, src HandicapSource, sn season.Season, nbaTeamID int, limit int) ([]models.BetResult, error) {
+	results, err := FetchTitan007TeamHandicapWithSpread(ctx, src, sn, nbaTeamID, limit)
 	if err != nil {
 		return nil, err
 	}
 
-	// 只返回 W/L 結果
-	resultStrings := make([]string, len(results))
+	// 只返回過盤結果
+	resultList := make([]models.BetResult, len(results))
 	for i, r := range results {
-		resultStrings[i] = r.Result
+		resultList[i] = r.Result
 	}
-	return resultStrings, nil
+	return resultList, nil
 }
 
 // FetchTitan007TeamHandicapWithSpread 從 HandicapDetail 頁面抓取球隊盤口戰績（含盤口數值）
-func FetchTitan007TeamHandicapWithSpread(teamNameEN string, limit int) ([]HandicapResultWithSpread, error) {
-	// 先從 letGoal API 取得 TeamID 映射
-	teamIDMap, err := fetchTeamIDMap()
-	if err != nil {
-		return nil, fmt.Errorf("無法取得球隊 ID 映射: %w", err)
-	}
-
-	// 查找球隊 ID
-	teamID := -1
-	for id, name := range teamIDMap {
-		if name == teamNameEN {
-			teamID = id
-			break
-		}
-	}
-
-	if teamID == -1 {
-		return nil, fmt.Errorf("找不到球隊: %s", teamNameEN)
+func FetchTitan007TeamHandicapWithSpread(ctx context.Context, src HandicapSource, sn season.Season, nbaTeamID int, limit int) ([]HandicapResultWithSpread, error) {
+	// 由 NBA teamId 取得 titan007 球隊 ID
+	team, ok := teams.ByID(nbaTeamID)
+	if !ok {
+		return nil, fmt.Errorf("找不到球隊: %d", nbaTeamID)
 	}
+	teamID := team.Titan007ID
 
 	// 抓取該球隊的盤口戰績頁面
-	games, err := fetchHandicapDetailPage(teamID)
+	games, err := src.FetchHandicapDetail(ctx, teamID, sn)
 	if err != nil {
 		return nil, err
 	}
@@ -148,14 +106,7 @@ func FetchTitan007TeamHandicapWithSpread(teamNameEN string, limit int) ([]Handic
 		}
 		// 如果查詢球隊是客隊，直接使用 Spread 值
 
-		var result string
-		if game.SpreadResult == 1 {
-			result = "W" // 贏盤
-		} else if game.SpreadResult == 3 {
-			result = "L" // 輸盤
-		} else {
-			result = "W" // 走盤當作平手，這裡簡化為 W
-		}
+		result := handicapResult(game, teamID, spreadValue)
 
 		temp = append(temp, HandicapResultWithSpread{
 			Result:      result,
@@ -172,55 +123,62 @@ func FetchTitan007TeamHandicapWithSpread(teamNameEN string, limit int) ([]Handic
 	return results, nil
 }
 
-// fetchTeamIDMap 取得 TeamID 映射（直接使用寫死的映射表）
-func fetchTeamIDMap() (map[int]string, error) {
-	return Titan007TeamIDMap, nil
-}
-
-// fetchHandicapDetailPage 抓取 HandicapDetail 頁面
-func fetchHandicapDetailPage(teamID int) ([]HandicapGame, error) {
-	// 構建 URL
-	season := "2025-2026" // 當前賽季，後續可以動態計算
-	url := fmt.Sprintf("https://nba.titan007.com/cn/Team/HandicapDetail.aspx?sclassid=1&teamid=%d&matchseason=%s&halfOrAll=0", teamID, season)
-
-	log.Printf("抓取 titan007 盤口戰績: %s", url)
-
-	// 建立 HTTP 請求
-	client := &http.Client{
-		Timeout: 10 * time.Second,
+// handicapResult 取得查詢球隊的過盤結果
+// titan007 的 SpreadResult 只有贏/走/輸，四分之一盤口（如 4.25）需要依比分算出贏半/輸半
+func handicapResult(game HandicapGame, teamID int, spreadValue float64) models.BetResult {
+	if !settlement.IsQuarterLine(spreadValue) {
+		switch game.SpreadResult {
+		case 1:
+			return models.BetWin // 贏盤
+		case 2:
+			return models.BetPush // 走盤
+		case 3:
+			return models.BetLoss // 輸盤
+		}
 	}
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("建立請求失敗: %w", err)
+	// 四分之一盤口或未知代碼：依比分計算
+	teamScore, oppScore := game.AwayScore, game.HomeScore
+	if game.HomeTeamID == teamID {
+		teamScore, oppScore = game.HomeScore, game.AwayScore
 	}
+	result, _ := settlement.Spread(teamScore, oppScore, spreadValue)
+	return result
+}
 
-	// 設定 headers 模擬瀏覽器
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9")
-	req.Header.Set("Accept-Language", "zh-CN,zh;q=0.9,en;q=0.8")
-	req.Header.Set("Referer", "https://nba.titan007.com/")
+// titan007Header 模擬瀏覽器的 request headers
+func titan007Header() http.Header {
+	header := http.Header{}
+	header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9")
+	header.Set("Accept-Language", "zh-CN,zh;q=0.9,en;q=0.8")
+	header.Set("Referer", "https://nba.titan007.com/")
+	return header
+}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("請求失敗: %w", err)
-	}
-	defer resp.Body.Close()
+// handicapDetailURL 取得球隊在指定賽季 HandicapDetail 頁面的 URL
+func handicapDetailURL(teamID int, sn season.Season) string {
+	return fmt.Sprintf("https://nba.titan007.com/cn/Team/HandicapDetail.aspx?sclassid=1&teamid=%d&matchseason=%s&halfOrAll=0", teamID, sn.Titan007())
+}
 
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("HTTP 狀態碼: %d", resp.StatusCode)
-	}
+// httpHandicapSource 從 titan007 抓取盤口資料
+type httpHandicapSource struct {
+	up *upstream
+}
 
-	// 讀取回應
-	body, err := io.ReadAll(resp.Body)
+// FetchHandicapDetail 抓取 HandicapDetail 頁面
+func (s *httpHandicapSource) FetchHandicapDetail(ctx context.Context, teamID int, sn season.Season) ([]HandicapGame, error) {
+	url := handicapDetailURL(teamID, sn)
+
+	log.Printf("抓取 titan007 盤口戰績: %s", url)
+
+	body, err := s.up.fetchBody(ctx, upstreamRequest{source: SourceTitan007, label: "titan007 handicap", url: url, header: titan007Header()})
 	if err != nil {
-		return nil, fmt.Errorf("讀取失敗: %w", err)
+		return nil, err
 	}
 
-	html := string(body)
-
 	// 解析 handicapDetail 陣列
-	games, err := parseHandicapDetail(html)
+	games, err := parseHandicapDetail(string(body))
 	if err != nil {
 		return nil, fmt.Errorf("解析失敗: %w", err)
 	}
@@ -274,33 +232,31 @@ func parseHandicapDetail(html string) ([]HandicapGame, error) {
 }
 
 // GetTeamHandicapSpreads 獲取指定球隊的近N場盤口結果（替換舊的 GetTeamSpreads）
-func GetTeamHandicapSpreads(teamName string, limit int) ([]string, bool) {
-	// 處理 LA Clippers 特殊情況
-	if teamName == "Los Angeles Clippers" {
-		teamName = "LA Clippers"
+// 非 NBA 球隊（季前賽的海外球隊）沒有 titan007 資料，回傳空結果
+func GetTeamHandicapSpreads(ctx context.Context, src HandicapSource, sn season.Season, nbaTeamID int, limit int) ([]models.BetResult, error) {
+	if !teams.IsNBA(nbaTeamID) {
+		return nil, nil
 	}
 
-	spreads, err := FetchTitan007TeamHandicap(teamName, limit)
+	spreads, err := FetchTitan007TeamHandicap(ctx, src, sn, nbaTeamID, limit)
 	if err != nil {
-		log.Printf("抓取 titan007 盤口戰績失敗 (%s): %v", teamName, err)
-		return nil, false
+		return nil, fmt.Errorf("抓取 titan007 盤口戰績失敗 (%d): %w", nbaTeamID, err)
 	}
 
-	return spreads, true
+	return spreads, nil
 }
 
 // GetTeamHandicapSpreadsWithValues 獲取指定球隊的近N場盤口結果（含盤口數值）
-func GetTeamHandicapSpreadsWithValues(teamName string, limit int) ([]HandicapResultWithSpread, bool) {
-	// 處理 LA Clippers 特殊情況
-	if teamName == "Los Angeles Clippers" {
-		teamName = "LA Clippers"
+// 非 NBA 球隊（季前賽的海外球隊）沒有 titan007 資料，回傳空結果
+func GetTeamHandicapSpreadsWithValues(ctx context.Context, src HandicapSource, sn season.Season, nbaTeamID int, limit int) ([]HandicapResultWithSpread, error) {
+	if !teams.IsNBA(nbaTeamID) {
+		return nil, nil
 	}
 
-	spreads, err := FetchTitan007TeamHandicapWithSpread(teamName, limit)
+	spreads, err := FetchTitan007TeamHandicapWithSpread(ctx, src, sn, nbaTeamID, limit)
 	if err != nil {
-		log.Printf("抓取 titan007 盤口戰績失敗 (%s): %v", teamName, err)
-		return nil, false
+		return nil, fmt.Errorf("抓取 titan007 盤口戰績失敗 (%d): %w", nbaTeamID, err)
 	}
 
-	return spreads, true
+	return spreads, nil
 }
@@ -1,92 +1,99 @@
 package crawler
 
 import (
+	"context"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
+	"nba-scanner/internal/models"
+	"nba-scanner/internal/season"
+	"nba-scanner/internal/teams"
 	"regexp"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
 
-// Titan007SpreadCache 快取 titan007 過盤資料
+// Titan007SpreadCache 快取 titan007 過盤資料（依賽季）
 var (
-	titan007SpreadCache      map[string][]string // map[teamName] = ["贏", "輸", "贏", "贏", "輸"]
+	titan007SpreadCache      = make(map[season.Season]titan007SpreadEntry)
 	titan007SpreadCacheMutex sync.RWMutex
-	titan007SpreadCacheTime  time.Time
 )
 
-// FetchTitan007Spreads 抓取 titan007 整季的過盤資料
-func FetchTitan007Spreads() (map[string][]string, error) {
-	// 檢查快取（1小時有效）
-	titan007SpreadCacheMutex.RLock()
-	if titan007SpreadCache != nil && time.Since(titan007SpreadCacheTime) < 1*time.Hour {
-		defer titan007SpreadCacheMutex.RUnlock()
-		return titan007SpreadCache, nil
-	}
-	titan007SpreadCacheMutex.RUnlock()
-
-	// 計算當前賽季
-	// NBA 賽季從10月開始，但 titan007 的資料可能會延遲
-	// 在新賽季剛開始時（10月初），先使用上一個賽季的資料
-	now := time.Now()
-	var season string
-	month := int(now.Month())
-	day := now.Day()
-
-	if month < 7 {
-		// 1-6月：使用上一年的賽季
-		season = fmt.Sprintf("%02d-%02d", (now.Year()-1)%100, now.Year()%100)
-	} else if month >= 10 && day < 20 {
-		// 10月前20天：還是使用上一個賽季（季前賽/賽季初）
-		season = fmt.Sprintf("%02d-%02d", (now.Year()-1)%100, now.Year()%100)
-	} else {
-		// 其他時間：使用當前賽季
-		season = fmt.Sprintf("%02d-%02d", now.Year()%100, (now.Year()+1)%100)
-	}
+// titan007SpreadEntry 單一賽季的過盤資料快取
+type titan007SpreadEntry struct {
+	spreads   map[string][]models.BetResult // map[teamName] = ["W", "L", "P", "W", "L"]
+	fetchedAt time.Time
+}
 
+// letGoalURL 取得 titan007 整季過盤資料 l1.js 的 URL
+func letGoalURL(s season.Season, now time.Time) string {
 	version := now.Format("2006010215")
-	url := fmt.Sprintf("https://nba.titan007.com/jsData/letGoal/%s/l1.js?version=%s", season, version)
+	return fmt.Sprintf("https://nba.titan007.com/jsData/letGoal/%s/l1.js?version=%s", s.LetGoal(), version)
+}
+
+// FetchLetGoal 抓取並解析 titan007 的 l1.js
+func (s *httpHandicapSource) FetchLetGoal(ctx context.Context, sn season.Season) (map[string][]models.BetResult, error) {
+	url := letGoalURL(sn, time.Now())
 
 	log.Printf("抓取 titan007 過盤資料: %s", url)
 
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("titan007 請求失敗: %w", err)
+	body, err := s.up.fetchBody(ctx, upstreamRequest{
+		source:   SourceTitan007,
+		label:    "titan007 letGoal",
+		url:      url,
+		staleKey: "letGoal/" + sn.LetGoal(), // 網址的 version 參數每小時變動
+	})
+	if err != nil && !IsStale(err) {
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("titan007 返回狀態碼: %d", resp.StatusCode)
+	// 解析 titan007 的 JS 資料（斷路中為舊資料，連同 *StaleError 一起回傳）
+	spreadMap, parseErr := parseTitan007Spreads(string(body))
+	if parseErr != nil {
+		return nil, fmt.Errorf("解析 titan007 資料失敗: %w", parseErr)
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("讀取 titan007 回應失敗: %w", err)
+	return spreadMap, err
+}
+
+// LetGoalSeason 取得指定比賽日應查詢的 l1.js 賽季
+// 例行賽開打前（季前賽、休賽期）titan007 還沒有新賽季的過盤資料，使用上一個賽季
+func LetGoalSeason(cal season.Calendar, day time.Time) season.Season {
+	if !cal.HasRegularSeasonGames(day) {
+		return cal.Season.Previous()
 	}
+	return cal.Season
+}
 
-	// 解析 titan007 的 JS 資料
-	spreadMap, err := parseTitan007Spreads(string(body))
+// FetchTitan007Spreads 抓取 titan007 指定賽季的過盤資料
+func FetchTitan007Spreads(ctx context.Context, src HandicapSource, sn season.Season) (map[string][]models.BetResult, error) {
+	// 檢查快取（1小時有效）
+	titan007SpreadCacheMutex.RLock()
+	entry, ok := titan007SpreadCache[sn]
+	titan007SpreadCacheMutex.RUnlock()
+	if ok && time.Since(entry.fetchedAt) < 1*time.Hour {
+		return entry.spreads, nil
+	}
+
+	spreadMap, err := src.FetchLetGoal(ctx, sn)
 	if err != nil {
-		return nil, fmt.Errorf("解析 titan007 資料失敗: %w", err)
+		return nil, err
 	}
 
 	// 更新快取
 	titan007SpreadCacheMutex.Lock()
-	titan007SpreadCache = spreadMap
-	titan007SpreadCacheTime = time.Now()
+	titan007SpreadCache[sn] = titan007SpreadEntry{spreads: spreadMap, fetchedAt: time.Now()}
 	titan007SpreadCacheMutex.Unlock()
 
-	log.Printf("成功快取 titan007 過盤資料，共 %d 支球隊", len(spreadMap))
+	log.Printf("成功快取 titan007 過盤資料（%s），共 %d 支球隊", sn, len(spreadMap))
 
 	return spreadMap, nil
 }
 
 // parseTitan007Spreads 解析 titan007 的 JS 資料
-func parseTitan007Spreads(jsData string) (map[string][]string, error) {
-	result := make(map[string][]string)
+func parseTitan007Spreads(jsData string) (map[string][]models.BetResult, error) {
+	result := make(map[string][]models.BetResult)
 
 	// 找出分號位置（用於定位資料區塊）
 	r1 := regexp.MustCompile(";")
@@ -150,15 +157,17 @@ func parseTitan007Spreads(jsData string) (map[string][]string, error) {
 		if teamName, ok := matchMap[TeamNumber]; ok {
 			// 近5場過盤結果在 index 12-16
 			// 資料格式: [TeamNum, ..., value12, value13, value14, value15, value16]
-			// "0" = 沒過盤（輸）, "2" = 過盤（贏）
-			spreads := make([]string, 5)
+			// "0" = 沒過盤（輸）, "1" = 走盤, "2" = 過盤（贏），其他值表示尚無資料
+			spreads := make([]models.BetResult, 0, 5)
 
 			for j := 0; j < 5; j++ {
-				spreadValue := parts[12+j]
-				if spreadValue == "2" {
-					spreads[j] = "W" // 過盤
-				} else {
-					spreads[j] = "L" // 沒過盤（0 或其他值）
+				switch strings.Trim(parts[12+j], "' ") {
+				case "2":
+					spreads = append(spreads, models.BetWin) // 過盤
+				case "1":
+					spreads = append(spreads, models.BetPush) // 走盤
+				case "0":
+					spreads = append(spreads, models.BetLoss) // 沒過盤
 				}
 			}
 
@@ -169,22 +178,14 @@ func parseTitan007Spreads(jsData string) (map[string][]string, error) {
 	return result, nil
 }
 
-// GetTeamSpreads 獲取指定球隊的近5場過盤結果
-func GetTeamSpreads(teamName string) ([]string, bool) {
-	spreadMap, err := FetchTitan007Spreads()
+// GetTeamSpreads 獲取指定球隊（NBA teamId）的近5場過盤結果
+// 資料中沒有該球隊時回傳空結果
+func GetTeamSpreads(ctx context.Context, src HandicapSource, sn season.Season, nbaTeamID int) ([]models.BetResult, error) {
+	spreadMap, err := FetchTitan007Spreads(ctx, src, sn)
 	if err != nil {
-		log.Printf("抓取 titan007 過盤資料失敗: %v", err)
-		return nil, false
-	}
-
-	// 處理 LA Clippers 特殊情況
-	if teamName == "Los Angeles Clippers" {
-		teamName = "LA Clippers"
-	}
-
-	if spreads, ok := spreadMap[teamName]; ok {
-		return spreads, true
+		return nil, fmt.Errorf("抓取 titan007 過盤資料失敗: %w", err)
 	}
 
-	return nil, false
+	spreads, _ := teams.Find(spreadMap, nbaTeamID)
+	return spreads, nil
 }
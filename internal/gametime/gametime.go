@@ -0,0 +1,104 @@
+// Package gametime 解析 NBA API 的時間欄位，並轉換為顯示用的時區
+//
+// NBA API 的時間欄位有兩種：
+//   - gameTimeUTC / gameDateTimeUTC：真正的 UTC 時間
+//   - gameEt / gameDateTimeEst：美東當地時間，雖然帶 Z 結尾但不是 UTC，
+//     需依日期套用 EDT（UTC-4）或 EST（UTC-5）
+package gametime
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultZone 預設的顯示時區
+const DefaultZone = "Asia/Taipei"
+
+// Eastern NBA 賽程使用的美東時區（含夏令時間）
+var Eastern = loadLocation("America/New_York", time.FixedZone("EST", -5*60*60))
+
+func loadLocation(name string, fallback *time.Location) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return fallback
+	}
+	return loc
+}
+
+// NBA API 時間欄位可能出現的格式（去掉 Z 之後）
+var wallClockLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+}
+
+// ParseEastern 解析美東當地時間欄位（gameEt、gameDateTimeEst）
+// 例如 "2025-12-07T19:30:00Z" 為美東 19:30（EST，UTC 00:30）
+func ParseEastern(s string) (time.Time, error) {
+	return parseWallClock(s, Eastern)
+}
+
+// ParseUTC 解析 UTC 時間欄位（gameTimeUTC、gameDateTimeUTC）
+func ParseUTC(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC(), nil
+	}
+	return parseWallClock(s, time.UTC)
+}
+
+// parseWallClock 將不帶時區的時間字串視為指定時區的當地時間
+func parseWallClock(s string, loc *time.Location) (time.Time, error) {
+	wall := strings.TrimSuffix(strings.TrimSpace(s), "Z")
+	for _, layout := range wallClockLayouts {
+		if t, err := time.ParseInLocation(layout, wall, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("無法解析時間: %q", s)
+}
+
+// GameStart 取得開賽時間，有 UTC 欄位時優先使用，否則由美東時間換算
+func GameStart(utc, eastern string) (time.Time, error) {
+	if utc != "" {
+		if t, err := ParseUTC(utc); err == nil {
+			return t, nil
+		}
+	}
+	return ParseEastern(eastern)
+}
+
+// FormatUTC 輸出 API 使用的 UTC 時間字串 "2025-12-08T00:30:00Z"
+func FormatUTC(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// LoadZone 解析顯示時區（IANA 名稱，如 "Asia/Taipei"、"America/Los_Angeles"），空字串使用預設時區
+func LoadZone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = DefaultZone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		if name == DefaultZone {
+			return time.FixedZone("CST", 8*60*60), nil
+		}
+		return nil, fmt.Errorf("無效的時區: %q", name)
+	}
+	return loc, nil
+}
+
+// Default 預設顯示時區
+func Default() *time.Location {
+	loc, _ := LoadZone(DefaultZone)
+	return loc
+}
+
+// Local 將 UTC 時間字串轉為指定時區的 "15:04"
+func Local(utc string, loc *time.Location) (string, error) {
+	t, err := ParseUTC(utc)
+	if err != nil {
+		return "", err
+	}
+	return t.In(loc).Format("15:04"), nil
+}
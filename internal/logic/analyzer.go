@@ -1,273 +1,119 @@
 package logic
 
 import (
+	"context"
 	"fmt"
 	"nba-scanner/internal/crawler"
 	"nba-scanner/internal/models"
-	"sync"
+	"nba-scanner/internal/render"
+	"nba-scanner/internal/slate"
+	"os"
 	"time"
 )
 
-// PKTeam 主要功能：抓取並顯示今日所有比賽資訊
-func PKTeam() {
+// PKTeam 主要功能：抓取並輸出目前比賽日所有比賽資訊（開賽時間以 loc 時區顯示）
+func PKTeam(ctx context.Context, src *crawler.Sources, rs *slate.Resolver, injuries *InjuryTracker, loc *time.Location, format render.Format) error {
 	start := time.Now()
 
-	// 平行抓取三個資料源
-	var (
-		scoreboard *models.NBAScoreboard
-		odds       *models.NBAOdds
-		injuryMap  map[string][]string
-		wg         sync.WaitGroup
-		mu         sync.Mutex
-		errors     []error
-	)
-
-	wg.Add(3)
-
-	// 1. 抓取賽程
-	go func() {
-		defer wg.Done()
-		sb, err := crawler.FetchSchedule()
-		if err != nil {
-			mu.Lock()
-			errors = append(errors, fmt.Errorf("賽程錯誤: %w", err))
-			mu.Unlock()
-			return
-		}
-		mu.Lock()
-		scoreboard = sb
-		mu.Unlock()
-	}()
-
-	// 2. 抓取賠率
-	go func() {
-		defer wg.Done()
-		od, err := crawler.FetchOdds()
-		if err != nil {
-			mu.Lock()
-			errors = append(errors, fmt.Errorf("賠率錯誤: %w", err))
-			mu.Unlock()
-			return
-		}
-		mu.Lock()
-		odds = od
-		mu.Unlock()
-	}()
-
-	// 3. 抓取傷兵
-	go func() {
-		defer wg.Done()
-		im := crawler.FetchInjuryMap()
-		mu.Lock()
-		injuryMap = im
-		mu.Unlock()
-	}()
-
-	wg.Wait()
-
-	// 檢查錯誤
-	if len(errors) > 0 {
-		fmt.Println("抓取資料時發生錯誤：")
-		for _, err := range errors {
-			fmt.Println("  -", err)
-		}
-		return
+	out, err := render.NewSlateRenderer(format, loc)
+	if err != nil {
+		return err
 	}
-
-	// 建立賠率查找表
-	oddsMap := crawler.BuildOddsMap(odds)
-
-	// 顯示比賽資訊
-	fmt.Printf("今天 %s 有 %d 場比賽\n\n", scoreboard.Scoreboard.GameDate, len(scoreboard.Scoreboard.Games))
-
-	for i, game := range scoreboard.Scoreboard.Games {
-		displayGame(i+1, &game, oddsMap, injuryMap)
+	response, err := buildCLISlate(ctx, src, rs, injuries, loc, "")
+	if err != nil {
+		return err
+	}
+	if err := out.RenderSlate(os.Stdout, response); err != nil {
+		return err
 	}
 
-	fmt.Printf("\n執行時間：%v\n", time.Since(start))
+	fmt.Fprintf(os.Stderr, "\n執行時間：%v\n", time.Since(start))
+	return nil
 }
 
-// PKTeamOnStartTime 根據開賽時間篩選比賽
-func PKTeamOnStartTime(st string) {
+// PKTeamOnStartTime 根據開賽時間篩選比賽（st 為 loc 時區的 15:04）
+func PKTeamOnStartTime(ctx context.Context, src *crawler.Sources, rs *slate.Resolver, injuries *InjuryTracker, st string, loc *time.Location, format render.Format) error {
 	if _, err := time.Parse("15:04", st); err != nil {
-		fmt.Println("時間格式錯誤，請用 15:04 格式")
-		return
+		return fmt.Errorf("時間格式錯誤，請用 15:04 格式")
 	}
 
 	start := time.Now()
 
-	// 平行抓取三個資料源（同上）
-	var (
-		scoreboard *models.NBAScoreboard
-		odds       *models.NBAOdds
-		injuryMap  map[string][]string
-		wg         sync.WaitGroup
-		mu         sync.Mutex
-		errors     []error
-	)
-
-	wg.Add(3)
-
-	go func() {
-		defer wg.Done()
-		sb, err := crawler.FetchSchedule()
-		if err != nil {
-			mu.Lock()
-			errors = append(errors, fmt.Errorf("賽程錯誤: %w", err))
-			mu.Unlock()
-			return
-		}
-		mu.Lock()
-		scoreboard = sb
-		mu.Unlock()
-	}()
-
-	go func() {
-		defer wg.Done()
-		od, err := crawler.FetchOdds()
-		if err != nil {
-			mu.Lock()
-			errors = append(errors, fmt.Errorf("賠率錯誤: %w", err))
-			mu.Unlock()
-			return
-		}
-		mu.Lock()
-		odds = od
-		mu.Unlock()
-	}()
-
-	go func() {
-		defer wg.Done()
-		im := crawler.FetchInjuryMap()
-		mu.Lock()
-		injuryMap = im
-		mu.Unlock()
-	}()
-
-	wg.Wait()
-
-	if len(errors) > 0 {
-		fmt.Println("抓取資料時發生錯誤：")
-		for _, err := range errors {
-			fmt.Println("  -", err)
-		}
-		return
+	out, err := render.NewSlateRenderer(format, loc)
+	if err != nil {
+		return err
+	}
+	response, err := buildCLISlate(ctx, src, rs, injuries, loc, "")
+	if err != nil {
+		return err
 	}
-
-	oddsMap := crawler.BuildOddsMap(odds)
 
 	// 篩選指定時間的比賽
-	var matchedGames []models.Game
-	for _, game := range scoreboard.Scoreboard.Games {
-		gameTime, err := crawler.ConvertUTCToLocal(game.GameTimeUTC)
-		if err != nil {
-			continue
-		}
-		if gameTime == st {
-			matchedGames = append(matchedGames, game)
-		}
+	filtered := filterGames(response, func(info models.GameInfo) bool {
+		gameTime, err := crawler.ConvertUTCToLocal(info.GameTimeUTC, loc)
+		return err == nil && gameTime == st
+	})
+	if len(filtered.Games) == 0 {
+		fmt.Fprintf(os.Stderr, "今天 %s 沒有比賽\n", st)
 	}
-
-	if len(matchedGames) == 0 {
-		fmt.Printf("今天 %s 沒有比賽\n", st)
-		return
+	if err := out.RenderSlate(os.Stdout, filtered); err != nil {
+		return err
 	}
 
-	fmt.Printf("今天 %s 有 %d 場比賽\n\n", st, len(matchedGames))
-
-	for i, game := range matchedGames {
-		displayGame(i+1, &game, oddsMap, injuryMap)
-	}
-
-	fmt.Printf("\n執行時間：%v\n", time.Since(start))
+	fmt.Fprintf(os.Stderr, "\n執行時間：%v\n", time.Since(start))
+	return nil
 }
 
-// displayGame 顯示單場比賽資訊
-func displayGame(index int, game *models.Game, oddsMap map[string]models.SpreadInfo, injuryMap map[string][]string) {
-	homeTeam := game.HomeTeam.GetFullTeamName()
-	awayTeam := game.AwayTeam.GetFullTeamName()
-
-	gameTime, _ := crawler.ConvertUTCToLocal(game.GameTimeUTC)
-
-	// 取得中文隊名，如果找不到就顯示原名
-	homeTeamCN := models.TeamMap[homeTeam]
-	if homeTeamCN == "" {
-		homeTeamCN = homeTeam
-	}
-	awayTeamCN := models.TeamMap[awayTeam]
-	if awayTeamCN == "" {
-		awayTeamCN = awayTeam
-	}
-
-	// 顯示比賽基本資訊（含戰績）
-	awayRecord := ""
-	homeRecord := ""
-	if game.AwayTeam.Wins > 0 || game.AwayTeam.Losses > 0 {
-		awayRecord = fmt.Sprintf(" (%d-%d)", game.AwayTeam.Wins, game.AwayTeam.Losses)
-	}
-	if game.HomeTeam.Wins > 0 || game.HomeTeam.Losses > 0 {
-		homeRecord = fmt.Sprintf(" (%d-%d)", game.HomeTeam.Wins, game.HomeTeam.Losses)
-	}
-
-	// 顯示比賽狀態和比分
-	statusText := getGameStatusText(game)
-	fmt.Printf("%d. %s%s  %s  %s%s(主)  %s\n",
-		index, awayTeamCN, awayRecord, gameTime, homeTeamCN, homeRecord, statusText)
-
-	// 如果比賽進行中或已結束，顯示比分
-	if game.GameStatus >= 2 { // 2=進行中, 3=結束
-		fmt.Printf("   比分：%s %d - %d %s", awayTeamCN, game.AwayTeam.Score, game.HomeTeam.Score, homeTeamCN)
-		if game.GameStatus == 2 { // 進行中
-			fmt.Printf("  [Q%d %s]", game.Period, game.GameClock)
+// filterGames 回傳只保留符合條件比賽的副本（其他欄位與原本的回應相同）
+func filterGames(response *models.APIResponse, keep func(models.GameInfo) bool) *models.APIResponse {
+	filtered := *response
+	filtered.Games = make([]models.GameInfo, 0, len(response.Games))
+	for _, info := range response.Games {
+		if keep(info) {
+			filtered.Games = append(filtered.Games, info)
 		}
-		fmt.Println()
 	}
+	return &filtered
+}
 
-	// 顯示賠率
-	if spread, ok := oddsMap[game.GameID]; ok && spread.Found {
-		fmt.Printf("   讓分盤：開盤 主隊%.1f/客隊%.1f → 目前 主隊%s/客隊%s\n",
-			spread.HomeOpeningSpread, spread.AwayOpeningSpread,
-			spread.HomeSpread, spread.AwaySpread)
-	} else {
-		fmt.Println("   讓分盤：無資料")
+// buildCLISlate 以與 /api/games 相同的 SlateBuilder 與選項建立目前比賽日的資料
+// book 不為空時只使用該莊家的盤口；賠率、傷兵等來源失敗時顯示警告後照常回傳
+// 文字輸出較精簡的內容由 render 在輸出時省略，資料本身與 /api/games 相同
+func buildCLISlate(ctx context.Context, src *crawler.Sources, rs *slate.Resolver, injuries *InjuryTracker, loc *time.Location, book string) (*models.APIResponse, error) {
+	day := rs.Today(ctx)
+	opts := slateOptions(ctx, rs, day, loc)
+	opts.Book = book
+	response, err := NewSlateBuilder(src, injuries).Build(ctx, day, opts)
+	if err != nil {
+		return nil, err
 	}
 
-	// 顯示傷兵
-	fmt.Println("\n  ---------------------------------")
-	printInjuries(awayTeam, awayTeamCN, injuryMap)
-	fmt.Println("  ---------------------------------")
-	printInjuries(homeTeam, homeTeamCN, injuryMap)
-	fmt.Println()
+	printSourceWarnings(response.Sources)
+	return response, nil
 }
 
-// getGameStatusText 取得比賽狀態文字
-func getGameStatusText(game *models.Game) string {
-	switch game.GameStatus {
-	case 1: // 未開始
-		return ""
-	case 2: // 進行中
-		return fmt.Sprintf("[進行中 Q%d]", game.Period)
-	case 3: // 已結束
-		return "[已結束]"
-	default:
-		return ""
-	}
+// sourceLabels CLI 警告訊息中的上游資料來源名稱
+var sourceLabels = map[string]string{
+	crawler.SourceSchedule: "賽程",
+	crawler.SourceOdds:     "賠率",
+	crawler.SourceInjuries: "傷兵",
+	crawler.SourceBoxscore: "球員數據",
+	crawler.SourceTitan007: "titan007 盤口",
 }
 
-// printInjuries 顯示球隊傷兵名單
-func printInjuries(teamName string, teamCN string, injuryMap map[string][]string) {
-	fmt.Printf("  %s 傷兵名單\n", teamCN)
-
-	// 處理 LA Clippers 的特殊情況
-	if teamName == "Los Angeles Clippers" {
-		teamName = "LA Clippers"
-	}
-
-	if injuries, ok := injuryMap[teamName]; ok && len(injuries) > 0 {
-		for _, injury := range injuries {
-			fmt.Printf("  %s\n", injury)
+// printSourceWarnings 顯示抓取失敗或使用舊資料的上游來源（賽程照常顯示）
+// 警告輸出到 stderr，json、csv 等輸出可以直接交給其他程式處理
+func printSourceWarnings(sources []models.SourceStatus) {
+	for _, source := range sources {
+		label := sourceLabels[source.Name]
+		if label == "" {
+			label = source.Name
+		}
+		switch source.Status {
+		case models.SourceError:
+			fmt.Fprintf(os.Stderr, "⚠️ %s資料無法取得: %s\n", label, source.Error)
+		case models.SourceStale:
+			fmt.Fprintf(os.Stderr, "⚠️ %s暫時無法連線，顯示先前的資料\n", label)
 		}
-	} else {
-		fmt.Println("  沒有傷兵")
 	}
 }
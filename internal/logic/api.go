@@ -1,243 +1,184 @@
 package logic
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"nba-scanner/internal/crawler"
 	"nba-scanner/internal/models"
+	"nba-scanner/internal/season"
+	"nba-scanner/internal/settlement"
+	"nba-scanner/internal/slate"
+	"nba-scanner/internal/teams"
+	"strconv"
 	"sync"
 	"time"
 )
 
 // GetGamesByDate 根據日期取得比賽資料
 // dateStr 格式: "2025-10-14" (YYYY-MM-DD)
+// seasonStr 格式: "2024-25"（可省略）；只指定賽季時顯示該賽季最後一個比賽日
+// rs 決定未指定日期時的比賽日；injuries 記錄並提供傷兵變動；loc 為開賽時間的顯示時區
 // 如果 dateStr 為空或為今天，使用即時 API
 // 否則使用整季賽程 API
-func GetGamesByDate(dateStr string) (*models.APIResponse, error) {
-	// 取得台北時區
-	loc, err := time.LoadLocation("Asia/Taipei")
+func GetGamesByDate(ctx context.Context, src *crawler.Sources, rs *slate.Resolver, injuries *InjuryTracker, dateStr string, seasonStr string, loc *time.Location) (*models.APIResponse, error) {
+	targetDate, err := resolveSlateDate(ctx, src, rs, dateStr, seasonStr)
 	if err != nil {
-		loc = time.FixedZone("CST", 8*60*60)
+		return nil, err
 	}
-	now := time.Now().In(loc)
 
-	// 解析請求的日期
-	var targetDate time.Time
-	if dateStr == "" {
-		// 沒有指定日期，使用當前日期判斷邏輯（14:00 之前顯示昨天）
-		if now.Hour() < 14 {
-			targetDate = now.AddDate(0, 0, -1) // 昨天
-		} else {
-			targetDate = now // 今天
-		}
-	} else {
-		// 解析使用者指定的日期
-		targetDate, err = time.Parse("2006-01-02", dateStr)
-		if err != nil {
-			return nil, fmt.Errorf("日期格式錯誤: %w", err)
-		}
-	}
-
-	// 判斷是否為昨天、今天或明天（需要查詢盤口）
-	// 因為 14:00 之前會顯示昨天，所以昨天也需要盤口
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
-	yesterday := today.AddDate(0, 0, -1)
-	tomorrow := today.AddDate(0, 0, 1)
-	targetDateOnly := time.Date(targetDate.Year(), targetDate.Month(), targetDate.Day(), 0, 0, 0, 0, loc)
-
-	needsOdds := targetDateOnly.Equal(yesterday) || targetDateOnly.Equal(today) || targetDateOnly.Equal(tomorrow)
+	return NewSlateBuilder(src, injuries).Build(ctx, targetDate, slateOptions(ctx, rs, targetDate, loc))
+}
 
-	return fetchGamesForDate(targetDate, needsOdds)
+// slateOptions /api/games 與 CLI 共用的比賽日選項，兩邊建立的資料完全相同
+// 目前比賽日的前一天、當天或隔天才需要查詢盤口
+func slateOptions(ctx context.Context, rs *slate.Resolver, day time.Time, loc *time.Location) SlateOptions {
+	return SlateOptions{
+		Location:  loc,
+		Odds:      rs.IsNear(ctx, day),
+		History:   true,
+		Boxscores: true,
+	}
 }
 
-// fetchGamesForDate 取得指定日期的比賽資料
-func fetchGamesForDate(targetDate time.Time, needsOdds bool) (*models.APIResponse, error) {
+// resolveSlateDate 解析要顯示的比賽日（/api/games 與 CLI 的 games 子指令共用）
+// dateStr 為空時使用目前比賽日；只指定其他賽季時使用該賽季最後一個比賽日
+func resolveSlateDate(ctx context.Context, src *crawler.Sources, rs *slate.Resolver, dateStr string, seasonStr string) (time.Time, error) {
+	// 解析請求的賽季
 	var (
-		scoreboard *models.NBAScoreboard
-		odds       *models.NBAOdds
-		injuryMap  map[string][]string
-		wg         sync.WaitGroup
-		mu         sync.Mutex
-		errors     []error
+		sn  season.Season
+		err error
 	)
-
-	// 根據是否需要盤口決定要抓取的資料數量
-	if needsOdds {
-		wg.Add(3) // 賽程 + 盤口 + 傷兵
-	} else {
-		wg.Add(2) // 只需賽程 + 傷兵
-	}
-
-	// 1. 抓取賽程（從完整賽季 API）
-	go func() {
-		defer wg.Done()
-		sb, err := crawler.FetchScheduleForDate(targetDate)
+	if seasonStr != "" {
+		sn, err = season.Parse(seasonStr)
 		if err != nil {
-			mu.Lock()
-			errors = append(errors, fmt.Errorf("賽程錯誤: %w", err))
-			mu.Unlock()
-			return
+			return time.Time{}, err
 		}
-		mu.Lock()
-		scoreboard = sb
-		mu.Unlock()
-	}()
-
-	// 2. 抓取賠率（只有今天/明天才需要）
-	if needsOdds {
-		go func() {
-			defer wg.Done()
-			od, err := crawler.FetchOdds()
-			if err != nil {
-				log.Printf("賠率抓取失敗（非今天比賽可忽略）: %v", err)
-				mu.Lock()
-				odds = nil // 設為 nil 表示無盤口資料
-				mu.Unlock()
-				return
-			}
-			mu.Lock()
-			odds = od
-			mu.Unlock()
-		}()
 	}
 
-	// 3. 抓取傷兵
-	go func() {
-		defer wg.Done()
-		im := crawler.FetchInjuryMap()
-		mu.Lock()
-		injuryMap = im
-		mu.Unlock()
-	}()
-
-	wg.Wait()
+	if dateStr == "" {
+		// 沒有指定日期，顯示目前的比賽日（前一個比賽日打完後才切換）
+		targetDate := rs.Today(ctx)
 
-	// 檢查關鍵錯誤（賽程必須成功）
-	if len(errors) > 0 {
-		return nil, fmt.Errorf("抓取資料失敗: %v", errors)
+		// 指定其他賽季時，改為顯示該賽季最後一個比賽日
+		if !sn.IsZero() && src.SeasonFor(ctx, targetDate) != sn {
+			day, ok, err := src.ScheduleFor(ctx, sn).LastGameDay(ctx, targetDate)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("取得 %s 賽季賽程失敗: %w", sn, err)
+			}
+			if !ok {
+				return time.Time{}, fmt.Errorf("%s 賽季目前沒有比賽", sn)
+			}
+			targetDate = day
+		}
+		return targetDate, nil
 	}
 
-	// 建立賠率查找表（如果有的話）
-	var oddsMap map[string]models.SpreadInfo
-	if odds != nil {
-		oddsMap = crawler.BuildOddsMap(odds)
-	} else {
-		oddsMap = make(map[string]models.SpreadInfo) // 空的 map
+	// 解析使用者指定的日期
+	targetDate, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("日期格式錯誤: %w", err)
 	}
-
-	// 轉換為 API 回應格式
-	response := &models.APIResponse{
-		Date:  targetDate.Format("2006-01-02"),
-		Games: make([]models.GameInfo, 0, len(scoreboard.Scoreboard.Games)),
+	if !sn.IsZero() && src.SeasonFor(ctx, targetDate) != sn {
+		return time.Time{}, fmt.Errorf("日期 %s 不屬於 %s 賽季", dateStr, sn)
 	}
+	return targetDate, nil
+}
 
-	for _, game := range scoreboard.Scoreboard.Games {
-		gameInfo := buildGameInfo(&game, oddsMap, injuryMap)
-		response.Games = append(response.Games, gameInfo)
-	}
+// NewSlateResolver 建立比賽日判斷器，以整季賽程判斷前一個比賽日何時打完
+func NewSlateResolver(src *crawler.Sources, cfg slate.Config) (*slate.Resolver, error) {
+	return slate.New(cfg, func(ctx context.Context, day time.Time) ([]models.ScheduledGame, error) {
+		return src.ScheduleFor(ctx, src.SeasonFor(ctx, day)).GamesOn(ctx, day)
+	})
+}
 
-	return response, nil
+// GetTodayGames 取得目前比賽日的比賽資料（供 API 使用）
+func GetTodayGames(ctx context.Context, src *crawler.Sources, rs *slate.Resolver, injuries *InjuryTracker, loc *time.Location) (*models.APIResponse, error) {
+	day := rs.Today(ctx)
+	return NewSlateBuilder(src, injuries).Build(ctx, day, slateOptions(ctx, rs, day, loc))
 }
 
-// GetTodayGames 取得今日比賽資料（供 API 使用）
-// 台北時間 12:00 之後會顯示明天的比賽
-func GetTodayGames() (*models.APIResponse, error) {
-	// 平行抓取三個資料源
-	var (
-		scoreboard *models.NBAScoreboard
-		odds       *models.NBAOdds
-		injuryMap  map[string][]string
-		wg         sync.WaitGroup
-		mu         sync.Mutex
-		errors     []error
-	)
+// seasonPhase 取得比賽日所處的賽季階段，賽程無法取得時使用預設行事曆
+func seasonPhase(ctx context.Context, src *crawler.Sources, sn season.Season, day time.Time) string {
+	cal, err := src.ScheduleFor(ctx, sn).Calendar(ctx)
+	if err != nil {
+		cal = season.DefaultCalendar(sn)
+	}
+	return string(cal.Phase(day))
+}
 
-	wg.Add(3)
+// enrichWorkers 同時建立比賽資訊的場數（實際向上游的並行數另受每個主機的上限控制）
+const enrichWorkers = 6
 
-	// 1. 抓取賽程（根據時間決定顯示今天還是昨天/明天）
-	go func() {
-		defer wg.Done()
+// enrichTiming 單場比賽資訊各項抓取的耗時
+type enrichTiming struct {
+	history  time.Duration // 兩隊近期戰績（含 titan007 盤口）
+	boxscore time.Duration
+}
 
-		// 判斷要顯示哪一天的比賽
-		loc, _ := time.LoadLocation("Asia/Taipei")
-		now := time.Now().In(loc)
+// buildGameInfos 以有上限的 worker pool 平行建立每場比賽的資訊，結果維持賽程順序
+// 每場比賽都要抓 boxscore 與兩隊戰績，逐場處理時冷快取下一整晚的比賽會很慢
+// games 的比分會以 boxscore 更新（每場只由一個 worker 修改）
+func buildGameInfos(ctx context.Context, src *crawler.Sources, sn season.Season, games []models.Game, oddsMap map[string]models.OddsGame, injuryMap map[string][]models.Injury, opts SlateOptions) ([]models.GameInfo, error) {
+	infos := make([]models.GameInfo, len(games))
+	jobs := make(chan int)
 
-		var targetDate time.Time
-		// 台北時間 14:00 之前顯示昨天，14:00 之後顯示今天
-		// 這樣可以確保早上還能看到昨晚/今早的比賽
-		if now.Hour() < 14 {
-			targetDate = now.AddDate(0, 0, -1) // 昨天
-		} else {
-			targetDate = now // 今天
-		}
+	var wg sync.WaitGroup
+	for w := 0; w < min(enrichWorkers, len(games)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				game := &games[i]
+				start := time.Now()
+				var timing enrichTiming
+				infos[i], timing = buildGameInfo(ctx, src, sn, game, oddsMap, injuryMap, opts)
+				log.Printf("比賽資訊 %s %s@%s 耗時 %v（戰績 %v、boxscore %v）",
+					game.GameID, game.AwayTeam.TeamTricode, game.HomeTeam.TeamTricode,
+					time.Since(start).Round(time.Millisecond), timing.history.Round(time.Millisecond), timing.boxscore.Round(time.Millisecond))
+			}
+		}()
+	}
 
-		// 從完整賽季 API 取得比賽
-		sb, err := crawler.FetchScheduleForDate(targetDate)
-		if err != nil {
-			mu.Lock()
-			errors = append(errors, fmt.Errorf("賽程錯誤: %w", err))
-			mu.Unlock()
-			return
-		}
-		mu.Lock()
-		scoreboard = sb
-		mu.Unlock()
-	}()
-
-	// 2. 抓取賠率
-	go func() {
-		defer wg.Done()
-		od, err := crawler.FetchOdds()
-		if err != nil {
-			mu.Lock()
-			errors = append(errors, fmt.Errorf("賠率錯誤: %w", err))
-			mu.Unlock()
-			return
+	// 取消後不再分派新的比賽，已開始的比賽會因上游請求取消而很快結束
+feed:
+	for i := range games {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
 		}
-		mu.Lock()
-		odds = od
-		mu.Unlock()
-	}()
-
-	// 3. 抓取傷兵
-	go func() {
-		defer wg.Done()
-		im := crawler.FetchInjuryMap()
-		mu.Lock()
-		injuryMap = im
-		mu.Unlock()
-	}()
-
+	}
+	close(jobs)
 	wg.Wait()
 
-	// 檢查錯誤
-	if len(errors) > 0 {
-		return nil, fmt.Errorf("抓取資料失敗: %v", errors)
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
+	return infos, nil
+}
 
-	// 建立賠率查找表
-	oddsMap := crawler.BuildOddsMap(odds)
-
-	// 轉換為 API 回應格式
-	// 使用台北時間的今天日期
-	taipeiLocation, _ := time.LoadLocation("Asia/Taipei")
-	todayDate := time.Now().In(taipeiLocation).Format("2006-01-02")
-
-	response := &models.APIResponse{
-		Date:  todayDate,
-		Games: make([]models.GameInfo, 0, len(scoreboard.Scoreboard.Games)),
-	}
+// buildGameInfo 建立單場比賽資訊（可平行呼叫），並回傳各項抓取的耗時
+// 進行中與已結束的比賽會先以 boxscore 更新 game
+func buildGameInfo(ctx context.Context, src *crawler.Sources, sn season.Season, game *models.Game, oddsMap map[string]models.OddsGame, injuryMap map[string][]models.Injury, opts SlateOptions) (models.GameInfo, enrichTiming) {
+	var timing enrichTiming
+	loc := opts.Location
 
-	for _, game := range scoreboard.Scoreboard.Games {
-		gameInfo := buildGameInfo(&game, oddsMap, injuryMap)
-		response.Games = append(response.Games, gameInfo)
+	// 進行中或已結束的比賽以 boxscore 更新比分與各節得分（在 worker 內抓取，受每個主機的並行上限控制）
+	// 不抓 boxscore 時只使用賽程的比分
+	var (
+		boxscore    *models.BoxscoreResponse
+		boxscoreErr error
+	)
+	if opts.Boxscores && (game.GameStatus == 2 || game.GameStatus == 3) {
+		boxscoreStart := time.Now()
+		boxscore, boxscoreErr = src.Boxscore.FetchBoxscore(ctx, game.GameID)
+		timing.boxscore = time.Since(boxscoreStart)
+		if boxscore != nil {
+			crawler.ApplyBoxscore(game, boxscore)
+		}
 	}
 
-	return response, nil
-}
-
-// buildGameInfo 建立單場比賽資訊
-func buildGameInfo(game *models.Game, oddsMap map[string]models.SpreadInfo, injuryMap map[string][]string) models.GameInfo {
 	homeTeam := game.HomeTeam.GetFullTeamName()
 	awayTeam := game.AwayTeam.GetFullTeamName()
 
@@ -253,7 +194,7 @@ func buildGameInfo(game *models.Game, oddsMap map[string]models.SpreadInfo, inju
 		// 顯示最終比分 "[111-109]"
 		scoreDisplay = fmt.Sprintf("[%d-%d]", game.AwayTeam.Score, game.HomeTeam.Score)
 	} else { // 未開始
-		gameTime, err := crawler.ConvertUTCToLocal(game.GameTimeUTC)
+		gameTime, err := crawler.ConvertUTCToLocal(game.GameTimeUTC, loc)
 		if err != nil {
 			log.Printf("時間轉換錯誤: %v", err)
 			gameTimeDisplay = "00:00"
@@ -264,55 +205,64 @@ func buildGameInfo(game *models.Game, oddsMap map[string]models.SpreadInfo, inju
 	}
 
 	// 取得中文隊名
-	homeTeamCN := models.TeamMap[homeTeam]
-	if homeTeamCN == "" {
-		homeTeamCN = homeTeam
-	}
-	awayTeamCN := models.TeamMap[awayTeam]
-	if awayTeamCN == "" {
-		awayTeamCN = awayTeam
-	}
+	homeTeamCN := teams.NameTW(game.HomeTeam.TeamID, homeTeam)
+	awayTeamCN := teams.NameTW(game.AwayTeam.TeamID, awayTeam)
 
 	// 取得盤口資訊（根據比賽狀態決定顯示哪個盤口）
 	spreadDisplay := models.SpreadDisplay{HasData: false}
-	if spread, ok := oddsMap[game.GameID]; ok && spread.Found {
-		spreadDisplay.HasData = true
-
-		if game.GameStatus == 1 { // 未開始：顯示開盤和當前盤口
-			spreadDisplay.Opening = fmt.Sprintf("%.1f", spread.HomeOpeningSpread)
-			spreadDisplay.Current = spread.HomeSpread // 當前盤口
-		} else { // 進行中或已結束：顯示開盤和最後盤口
-			// 已結束時，current 就是開打前的最後盤口
-			spreadDisplay.Opening = fmt.Sprintf("%.1f", spread.HomeOpeningSpread)
+	var totalsDisplay models.TotalsDisplay
+	var oddsComparison *models.OddsComparison
+	if oddsGame, ok := oddsMap[game.GameID]; ok {
+		totalsDisplay = buildTotalsDisplay(game, oddsGame.GetPreferredTotal(models.SupermatchBookID))
+
+		if spread := oddsGame.GetPreferredSpread(models.SupermatchBookID); spread.Found {
+			spreadDisplay.HasData = true
+			spreadDisplay.Book = spread.BookName
+
+			// 開盤與目前盤口（已開打時 current 就是開打前的最後盤口），莊家沒有開盤資料時開盤為空字串
+			if spread.HomeOpeningSpread != nil {
+				spreadDisplay.Opening = models.FormatLine(*spread.HomeOpeningSpread)
+			}
 			spreadDisplay.Current = spread.HomeSpread
 		}
+
+		// 所有莊家的盤口比較（共識盤口、最佳盤口）
+		if comparison := oddsGame.CompareOdds(); len(comparison.Books) > 0 {
+			oddsComparison = &comparison
+		}
 	}
 
-	// 取得傷兵資訊
-	homeInjuries := getInjuriesForTeam(homeTeam, injuryMap)
-	awayInjuries := getInjuriesForTeam(awayTeam, injuryMap)
+	// 球隊類型（季前賽的海外球隊、表演賽隊伍沒有戰績與 titan007 盤口資料）
+	homeKind := teams.Classify(game.HomeTeam.TeamID, homeTeam)
+	awayKind := teams.Classify(game.AwayTeam.TeamID, awayTeam)
 
 	// 取得主隊近五場戰績（使用快取）
+	historyStart := time.Now()
 	var homeHistory *models.TeamHistory
-	if history, err := crawler.FetchTeamHistoryWithCache(game.HomeTeam.TeamID, 5); err == nil {
-		homeHistory = history
-	} else {
-		log.Printf("取得主隊戰績失敗 (TeamID: %d): %v", game.HomeTeam.TeamID, err)
+	if opts.History && homeKind == teams.KindNBA {
+		if history, err := crawler.FetchTeamHistoryWithCache(ctx, src, sn, game.HomeTeam.TeamID, 5); err == nil {
+			homeHistory = history.InZone(loc)
+		} else {
+			log.Printf("取得主隊戰績失敗 (TeamID: %d): %v", game.HomeTeam.TeamID, err)
+		}
 	}
 
 	// 取得客隊近五場戰績（使用快取）
 	var awayHistory *models.TeamHistory
-	if history, err := crawler.FetchTeamHistoryWithCache(game.AwayTeam.TeamID, 5); err == nil {
-		awayHistory = history
-	} else {
-		log.Printf("取得客隊戰績失敗 (TeamID: %d): %v", game.AwayTeam.TeamID, err)
+	if opts.History && awayKind == teams.KindNBA {
+		if history, err := crawler.FetchTeamHistoryWithCache(ctx, src, sn, game.AwayTeam.TeamID, 5); err == nil {
+			awayHistory = history.InZone(loc)
+		} else {
+			log.Printf("取得客隊戰績失敗 (TeamID: %d): %v", game.AwayTeam.TeamID, err)
+		}
 	}
+	timing.history = time.Since(historyStart)
 
 	// 如果比賽進行中或已結束，取得球員數據和各節比分
 	var homePlayers, awayPlayers []models.PlayerDisplay
 	var periodScores *models.PeriodScores
 	if game.GameStatus == 2 || game.GameStatus == 3 { // 進行中或已結束
-		if boxscore, err := crawler.FetchBoxscore(game.GameID); err == nil {
+		if boxscore != nil {
 			// 處理主隊球員
 			homePlayerList := crawler.BuildPlayerDisplayList(boxscore.Game.HomeTeam.Players)
 			homePlayers = crawler.SortPlayersByStarterAndPoints(homePlayerList)
@@ -320,62 +270,197 @@ func buildGameInfo(game *models.Game, oddsMap map[string]models.SpreadInfo, inju
 			// 處理客隊球員
 			awayPlayerList := crawler.BuildPlayerDisplayList(boxscore.Game.AwayTeam.Players)
 			awayPlayers = crawler.SortPlayersByStarterAndPoints(awayPlayerList)
-		} else {
-			log.Printf("取得球員數據失敗 (GameID: %s): %v", game.GameID, err)
+		} else if boxscoreErr != nil {
+			log.Printf("取得 boxscore 失敗 (GameID: %s): %v", game.GameID, boxscoreErr)
 		}
 
 		// 處理各節比分
 		periodScores = buildPeriodScores(game)
 	}
 
+	// 取得傷兵資訊（personId 在所有 boxscore 抓完後由 linkInjuries 填入）
+	homeInjuries := getInjuriesForTeam(game.HomeTeam.TeamID, injuryMap)
+	awayInjuries := getInjuriesForTeam(game.AwayTeam.TeamID, injuryMap)
+
+	// 結算讓分、獨贏、大小分（進行中只結算已打完的節）
+	var betSettlement *models.BetSettlement
+	if periodScores != nil {
+		lines := buildSettlementLines(spreadDisplay, totalsDisplay)
+		var result models.BetSettlement
+		if game.GameStatus == 3 {
+			result = settlement.Settle(*periodScores, lines)
+		} else {
+			result = settlement.SettleCompleted(*periodScores, lines, completedPeriods(game))
+		}
+		if len(result.Segments) > 0 {
+			betSettlement = &result
+		}
+	}
+
 	return models.GameInfo{
 		GameID:         game.GameID,
 		GameTime:       gameTimeDisplay,
 		GameTimeUTC:    game.GameTimeUTC,
 		GameStatus:     game.GameStatus,
 		GameStatusText: game.GameStatusText,
+		GameType:       season.GameTypeOf(game.GameID, game.GameSubtype),
+		GameLabel:      game.GameLabel,
 		ScoreDisplay:   scoreDisplay,
 		HomeTeam: models.TeamInfo{
-			NameEN: homeTeam,
-			NameCN: homeTeamCN,
-			Wins:   game.HomeTeam.Wins,
-			Losses: game.HomeTeam.Losses,
+			TeamID:  game.HomeTeam.TeamID,
+			Tricode: game.HomeTeam.TeamTricode,
+			NameEN:  homeTeam,
+			NameCN:  homeTeamCN,
+			Kind:    string(homeKind),
+			Wins:    game.HomeTeam.Wins,
+			Losses:  game.HomeTeam.Losses,
 		},
 		AwayTeam: models.TeamInfo{
-			NameEN: awayTeam,
-			NameCN: awayTeamCN,
-			Wins:   game.AwayTeam.Wins,
-			Losses: game.AwayTeam.Losses,
+			TeamID:  game.AwayTeam.TeamID,
+			Tricode: game.AwayTeam.TeamTricode,
+			NameEN:  awayTeam,
+			NameCN:  awayTeamCN,
+			Kind:    string(awayKind),
+			Wins:    game.AwayTeam.Wins,
+			Losses:  game.AwayTeam.Losses,
 		},
-		HomeScore:    game.HomeTeam.Score,
-		AwayScore:    game.AwayTeam.Score,
-		Spread:       spreadDisplay,
-		HomeInjuries: homeInjuries,
-		AwayInjuries: awayInjuries,
-		HomeHistory:  homeHistory,
-		AwayHistory:  awayHistory,
-		HomePlayers:  homePlayers,
-		AwayPlayers:  awayPlayers,
-		PeriodScores: periodScores,
+		HomeScore:     game.HomeTeam.Score,
+		AwayScore:     game.AwayTeam.Score,
+		Spread:        spreadDisplay,
+		Totals:        totalsDisplay,
+		Odds:          oddsComparison,
+		HomeInjuries:  homeInjuries,
+		AwayInjuries:  awayInjuries,
+		InjuryChanges: []models.InjuryChange{}, // 由 SlateBuilder 在所有比賽建立後填入
+		HomeHistory:   homeHistory,
+		AwayHistory:   awayHistory,
+		HomePlayers:   homePlayers,
+		AwayPlayers:   awayPlayers,
+		PeriodScores:  periodScores,
+		Settlement:    betSettlement,
+	}, timing
+}
+
+// buildTotalsDisplay 建立大小分盤口、預期得分與大小分結果
+func buildTotalsDisplay(game *models.Game, line models.TotalLine) models.TotalsDisplay {
+	if !line.Found {
+		return models.TotalsDisplay{}
+	}
+
+	totals := models.TotalsDisplay{
+		Opening: models.FormatLine(line.Opening),
+		Current: models.FormatLine(line.Total),
+		Book:    line.BookName,
+		HasData: true,
 	}
+
+	if line.HomeSpread != nil {
+		home, away := models.ImpliedTeamTotals(line.Total, *line.HomeSpread)
+		totals.HomeImplied = models.FormatLine(home)
+		totals.AwayImplied = models.FormatLine(away)
+	}
+
+	halfLine := models.HalfTotalLine(line.Total)
+	if line.FirstHalfTotal != nil {
+		halfLine = *line.FirstHalfTotal
+	} else {
+		totals.FirstHalfDerived = true
+	}
+	totals.FirstHalf = models.FormatLine(halfLine)
+
+	if game.GameStatus == 1 {
+		return totals
+	}
+
+	// 上半場得分（Q1 + Q2）
+	for _, team := range []models.Team{game.HomeTeam, game.AwayTeam} {
+		for _, period := range team.Periods {
+			if period.Period <= 2 {
+				totals.FirstHalfPoints += period.Score
+			}
+		}
+	}
+	totals.Points = game.HomeTeam.Score + game.AwayTeam.Score
+
+	// 上半場結束後才判斷上半場結果，比賽結束後才判斷全場結果
+	if game.GameStatus == 3 || game.Period > 2 {
+		totals.FirstHalfResult = models.OverUnderResult(totals.FirstHalfPoints, halfLine)
+	}
+	if game.GameStatus == 3 {
+		totals.Result = models.OverUnderResult(totals.Points, line.Total)
+	}
+
+	return totals
+}
+
+// buildSettlementLines 整理結算用的全場與上半場盤口
+// 上半場讓分以全場讓分的一半估算；上半場大小分使用 TotalsDisplay 的上半場盤口
+func buildSettlementLines(spread models.SpreadDisplay, totals models.TotalsDisplay) settlement.Lines {
+	var full, half settlement.Line
+
+	if spread.HasData {
+		if v, err := strconv.ParseFloat(spread.Current, 64); err == nil {
+			halfSpread := settlement.HalfLine(v)
+			full.HomeSpread = &v
+			half.HomeSpread = &halfSpread
+			half.SpreadDerived = true
+		}
+	}
+
+	if totals.HasData {
+		if v, err := strconv.ParseFloat(totals.Current, 64); err == nil {
+			full.Total = &v
+		}
+		if v, err := strconv.ParseFloat(totals.FirstHalf, 64); err == nil {
+			half.Total = &v
+			half.TotalDerived = totals.FirstHalfDerived
+		}
+	}
+
+	return settlement.Lines{
+		models.SegmentFullGame:  full,
+		models.SegmentFirstHalf: half,
+	}
+}
+
+// completedPeriods 進行中比賽已打完的節數（節末時鐘歸零也算打完，沒有時鐘資料時不算）
+// 全場與延長賽不依此判斷，只在比賽結束後結算
+func completedPeriods(game *models.Game) int {
+	if game.GameStatus == 3 {
+		return game.Period
+	}
+	if game.GameClock != "" && formatGameClock(game.GameClock) == "00:00" {
+		return game.Period
+	}
+	return max(game.Period-1, 0)
 }
 
 // buildPeriodScores 建立各節比分資訊
 func buildPeriodScores(game *models.Game) *models.PeriodScores {
 	// 確保有四節的資料（如果比賽還在進行中，未完成的節顯示 0）
-	homePeriods := make([]int, 4)
-	awayPeriods := make([]int, 4)
+	// 有延長賽時依序附加在第四節之後
+	periods := 4
+	// 各節資料可能與快取的 boxscore 共用，只讀取不 append
+	for _, teamPeriods := range [][]models.PeriodScore{game.HomeTeam.Periods, game.AwayTeam.Periods} {
+		for _, period := range teamPeriods {
+			if period.Period > periods {
+				periods = period.Period
+			}
+		}
+	}
+	homePeriods := make([]int, periods)
+	awayPeriods := make([]int, periods)
 
 	// 填入主隊各節得分
 	for _, period := range game.HomeTeam.Periods {
-		if period.Period >= 1 && period.Period <= 4 {
+		if period.Period >= 1 {
 			homePeriods[period.Period-1] = period.Score
 		}
 	}
 
 	// 填入客隊各節得分
 	for _, period := range game.AwayTeam.Periods {
-		if period.Period >= 1 && period.Period <= 4 {
+		if period.Period >= 1 {
 			awayPeriods[period.Period-1] = period.Score
 		}
 	}
@@ -400,15 +485,24 @@ func formatGameClock(clock string) string {
 	return fmt.Sprintf("%02d:%02d", minutes, seconds)
 }
 
-// getInjuriesForTeam 取得球隊傷兵清單
-func getInjuriesForTeam(teamName string, injuryMap map[string][]string) []string {
-	// 處理 LA Clippers 特殊情況
-	if teamName == "Los Angeles Clippers" {
-		teamName = "LA Clippers"
+// getInjuriesForTeam 取得球隊傷兵清單（ESPN 隊名透過球隊資料表對應）
+func getInjuriesForTeam(teamID int, injuryMap map[string][]models.Injury) []models.Injury {
+	if injuries, ok := teams.Find(injuryMap, teamID); ok && len(injuries) > 0 {
+		return injuries
 	}
+	return []models.Injury{}
+}
 
-	if injuries, ok := injuryMap[teamName]; ok && len(injuries) > 0 {
+// linkInjuries 依 Sources 的球員名單填入傷兵的 NBA personId
+// 在比賽日所有 boxscore 都抓完後才呼叫，同樣的資料每次對應結果都相同
+func linkInjuries(ctx context.Context, src *crawler.Sources, teamID int, injuries []models.Injury) []models.Injury {
+	if len(injuries) == 0 || src.Roster == nil {
+		return injuries
+	}
+	roster, err := src.Roster.Roster(ctx, teamID)
+	if err != nil {
+		log.Printf("讀取球員名單失敗 (TeamID: %d): %v", teamID, err)
 		return injuries
 	}
-	return []string{}
+	return crawler.LinkInjuries(roster, injuries)
 }
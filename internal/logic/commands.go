@@ -0,0 +1,340 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"nba-scanner/internal/crawler"
+	"nba-scanner/internal/models"
+	"nba-scanner/internal/render"
+	"nba-scanner/internal/season"
+	"nba-scanner/internal/slate"
+	"nba-scanner/internal/teams"
+	"os"
+	"time"
+)
+
+// GamesQuery games 子指令的查詢條件
+type GamesQuery struct {
+	Date   string // 美東日期 YYYY-MM-DD（空字串表示目前比賽日）
+	Season string // 賽季（如 2024-25，只指定賽季時顯示該賽季最後一個比賽日）
+	Team   string // 球隊名稱或三碼縮寫（空字串表示全部）
+	Status string // live、final、upcoming（空字串表示全部）
+}
+
+// gameStatusFilters --status 對應的比賽狀態
+var gameStatusFilters = map[string]int{
+	"upcoming": 1,
+	"live":     2,
+	"final":    3,
+}
+
+// lookupTeam 以名稱、三碼縮寫或中文隊名查詢球隊
+func lookupTeam(name string) (teams.Team, error) {
+	team, ok := teams.Lookup(name)
+	if !ok {
+		return teams.Team{}, fmt.Errorf("找不到球隊 %q（可用三碼縮寫、英文或中文隊名）", name)
+	}
+	return team, nil
+}
+
+// ShowGames 輸出指定比賽日的比賽（可依球隊與比賽狀態篩選）
+func ShowGames(ctx context.Context, src *crawler.Sources, rs *slate.Resolver, injuries *InjuryTracker, q GamesQuery, loc *time.Location, format render.Format) error {
+	start := time.Now()
+
+	out, err := render.NewSlateRenderer(format, loc)
+	if err != nil {
+		return err
+	}
+	response, filtered, err := gamesSlate(ctx, src, rs, injuries, q, loc)
+	if err != nil {
+		return err
+	}
+	if len(filtered.Games) != len(response.Games) {
+		fmt.Fprintf(os.Stderr, "比賽日 %s 共 %d 場比賽，符合條件 %d 場\n", response.Date, len(response.Games), len(filtered.Games))
+	}
+	if err := out.RenderSlate(os.Stdout, filtered); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "\n執行時間：%v\n", time.Since(start))
+	return nil
+}
+
+// gamesSlate 以與 /api/games 相同的選項建立比賽日資料，回傳完整與篩選後的比賽
+func gamesSlate(ctx context.Context, src *crawler.Sources, rs *slate.Resolver, injuries *InjuryTracker, q GamesQuery, loc *time.Location) (response, filtered *models.APIResponse, err error) {
+	// 先檢查篩選條件，避免抓完資料才發現參數錯誤
+	teamID := 0
+	if q.Team != "" {
+		team, err := lookupTeam(q.Team)
+		if err != nil {
+			return nil, nil, err
+		}
+		teamID = team.ID
+	}
+	status, filterStatus := gameStatusFilters[q.Status]
+	if q.Status != "" && !filterStatus {
+		return nil, nil, fmt.Errorf("--status 只能是 live、final 或 upcoming: %q", q.Status)
+	}
+
+	day, err := resolveSlateDate(ctx, src, rs, q.Date, q.Season)
+	if err != nil {
+		return nil, nil, err
+	}
+	response, err = NewSlateBuilder(src, injuries).Build(ctx, day, slateOptions(ctx, rs, day, loc))
+	if err != nil {
+		return nil, nil, err
+	}
+	printSourceWarnings(response.Sources)
+
+	filtered = filterGames(response, func(info models.GameInfo) bool {
+		if teamID != 0 && info.HomeTeam.TeamID != teamID && info.AwayTeam.TeamID != teamID {
+			return false
+		}
+		return !filterStatus || info.GameStatus == status
+	})
+	return response, filtered, nil
+}
+
+// requireTextOrJSON 檢查只有文字與 JSON 輸出的指令（球隊戰績、傷兵、boxscore 不是比賽日資料）
+func requireTextOrJSON(command string, format render.Format) error {
+	if format != render.FormatText && format != render.FormatJSON {
+		return fmt.Errorf("%s 只支援 text 與 json 輸出", command)
+	}
+	return nil
+}
+
+// ShowTeam 輸出球隊近期戰績與過盤紀錄（seasonStr 為空時使用目前比賽日所屬的賽季）
+func ShowTeam(ctx context.Context, src *crawler.Sources, rs *slate.Resolver, name string, seasonStr string, limit int, loc *time.Location, format render.Format) error {
+	if err := requireTextOrJSON("team", format); err != nil {
+		return err
+	}
+	team, err := lookupTeam(name)
+	if err != nil {
+		return err
+	}
+
+	sn := src.SeasonFor(ctx, rs.Today(ctx))
+	if seasonStr != "" {
+		if sn, err = season.Parse(seasonStr); err != nil {
+			return err
+		}
+	}
+
+	history, err := crawler.FetchTeamHistory(ctx, src, sn, team.ID, limit)
+	if err != nil {
+		return fmt.Errorf("取得 %s 戰績失敗: %w", team.NameTW, err)
+	}
+	history = history.InZone(loc)
+	if format == render.FormatJSON {
+		return render.WriteJSON(os.Stdout, history)
+	}
+
+	fmt.Printf("%s（%s）%s 賽季近 %d 場\n", team.NameTW, team.Tricode, sn, len(history.RecentGames))
+	fmt.Printf("讓分 %d勝 %d負 %d走  大小分 大%d 小%d 走%d\n\n",
+		history.ATSWins, history.ATSLosses, history.ATSPushes,
+		history.OverCount, history.UnderCount, history.TotalPushCount)
+
+	for _, game := range history.RecentGames {
+		spread := "無盤口"
+		if game.HasSpread {
+			spread = fmt.Sprintf("%s %s", game.Spread, game.SpreadResult)
+		}
+		total := "無盤口"
+		if game.HasTotal {
+			total = fmt.Sprintf("%s %s（%d）", game.Total, game.TotalResult, game.Points)
+		}
+		fmt.Printf("%s %s  %-2s %s  %s %s  讓分 %s  大小 %s\n",
+			game.Date, game.Time, game.VsIndicator, teamNameTW(game.Opponent),
+			game.GameResult, game.Score, spread, total)
+	}
+	return nil
+}
+
+// teamNameTW 以英文隊名取得中文隊名（找不到時回傳原名）
+func teamNameTW(name string) string {
+	if team, ok := teams.Lookup(name); ok {
+		return team.NameTW
+	}
+	return name
+}
+
+// ShowInjuries 輸出傷兵名單（teamName 為空時輸出所有有傷兵的球隊）
+// JSON 輸出以三碼縮寫為 key
+func ShowInjuries(ctx context.Context, src *crawler.Sources, tracker *InjuryTracker, teamName string, format render.Format) error {
+	if err := requireTextOrJSON("injuries", format); err != nil {
+		return err
+	}
+	var only *teams.Team
+	if teamName != "" {
+		team, err := lookupTeam(teamName)
+		if err != nil {
+			return err
+		}
+		only = &team
+	}
+
+	injuryMap, err := src.Injury.FetchInjuryMap(ctx)
+	switch {
+	case crawler.IsStale(err):
+		// 資料庫保存的名單不是新的抓取結果，不比對變動
+		fmt.Fprintf(os.Stderr, "⚠️ 傷兵資料暫時無法取得，顯示先前保存的名單: %v\n", err)
+	case err != nil:
+		return fmt.Errorf("傷兵資料無法取得: %w", err)
+	default:
+		tracker.Observe(injuryMap, time.Now())
+	}
+
+	list := teams.All()
+	if only != nil {
+		list = []teams.Team{*only}
+	}
+
+	byTeam := make(map[string][]models.Injury)
+	shown := 0
+	for _, team := range list {
+		injuries := linkInjuries(ctx, src, team.ID, getInjuriesForTeam(team.ID, injuryMap))
+		if len(injuries) == 0 && only == nil {
+			continue
+		}
+		byTeam[team.Tricode] = injuries
+		if format == render.FormatJSON {
+			continue
+		}
+		if shown > 0 {
+			fmt.Println("  ---------------------------------")
+		}
+		render.WriteInjuries(os.Stdout, team.NameTW, injuries)
+		shown++
+	}
+
+	if format == render.FormatJSON {
+		return render.WriteJSON(os.Stdout, byTeam)
+	}
+	if shown == 0 {
+		fmt.Println("目前沒有球隊有傷兵")
+	}
+	return nil
+}
+
+// ShowOdds 顯示目前比賽日每場比賽各家莊家的盤口（book 可指定莊家 ID 或名稱，不分大小寫）
+// 文字以外的格式輸出整個比賽日；指定莊家時所有格式都只使用該莊家的盤口
+func ShowOdds(ctx context.Context, src *crawler.Sources, rs *slate.Resolver, injuries *InjuryTracker, book string, loc *time.Location, format render.Format) error {
+	out, err := render.NewSlateRenderer(format, loc)
+	if err != nil {
+		return err
+	}
+	response, err := buildCLISlate(ctx, src, rs, injuries, loc, book)
+	if err != nil {
+		return err
+	}
+	if format != render.FormatText {
+		return out.RenderSlate(os.Stdout, response)
+	}
+
+	fmt.Printf("比賽日 %s 有 %d 場比賽\n\n", response.Date, len(response.Games))
+
+	for i, info := range response.Games {
+		gameTime, _ := crawler.ConvertUTCToLocal(info.GameTimeUTC, loc)
+		fmt.Printf("%d. %s  %s  %s(主)\n", i+1, info.AwayTeam.NameCN, gameTime, info.HomeTeam.NameCN)
+
+		shown := 0
+		if info.Odds != nil {
+			for _, line := range info.Odds.Books {
+				printBookLine(line)
+				shown++
+			}
+		}
+		if shown == 0 {
+			fmt.Println("   無賠率資料")
+		}
+		if info.Odds != nil && book == "" {
+			render.WriteOddsComparison(os.Stdout, *info.Odds)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// printBookLine 顯示單一莊家的讓分、獨贏與大小分
+func printBookLine(line models.BookLine) {
+	fmt.Printf("   %-12s", line.BookName)
+	if line.HomeSpread != nil {
+		fmt.Printf("  讓分 主%s", models.FormatLine(*line.HomeSpread))
+		if line.HomeSpreadPrice != nil && line.AwaySpreadPrice != nil {
+			fmt.Printf("（%s/%s）", formatPrice(line.HomeSpreadPrice), formatPrice(line.AwaySpreadPrice))
+		}
+	}
+	if line.HomeMoneyline != nil && line.AwayMoneyline != nil {
+		fmt.Printf("  獨贏 主%s/客%s", formatPrice(line.HomeMoneyline), formatPrice(line.AwayMoneyline))
+	}
+	if line.Total != nil {
+		fmt.Printf("  大小 %s", models.FormatLine(*line.Total))
+		if line.OverPrice != nil && line.UnderPrice != nil {
+			fmt.Printf("（大%s/小%s）", formatPrice(line.OverPrice), formatPrice(line.UnderPrice))
+		}
+	}
+	fmt.Println()
+}
+
+// formatPrice 以小數賠率顯示
+func formatPrice(p *models.Price) string {
+	return fmt.Sprintf("%.2f", p.Decimal)
+}
+
+// ShowBoxscore 輸出單場比賽的各節比分與球員數據（JSON 為 NBA boxscore 原始資料）
+func ShowBoxscore(ctx context.Context, src *crawler.Sources, gameID string, format render.Format) error {
+	if err := requireTextOrJSON("boxscore", format); err != nil {
+		return err
+	}
+	boxscore, err := src.Boxscore.FetchBoxscore(ctx, gameID)
+	if err != nil {
+		return fmt.Errorf("取得 boxscore 失敗 (GameID: %s): %w", gameID, err)
+	}
+	game := boxscore.Game
+	if format == render.FormatJSON {
+		return render.WriteJSON(os.Stdout, boxscore)
+	}
+
+	homeCN := teams.NameTW(game.HomeTeam.TeamID, game.HomeTeam.TeamName)
+	awayCN := teams.NameTW(game.AwayTeam.TeamID, game.AwayTeam.TeamName)
+
+	status := game.GameStatusText
+	if game.GameStatus == 2 {
+		status = fmt.Sprintf("Q%d %s", game.Period, formatGameClock(game.GameClock))
+	}
+	fmt.Printf("%s %d - %d %s(主)  [%s]\n\n", awayCN, game.AwayTeam.Score, game.HomeTeam.Score, homeCN, status)
+
+	// 各節比分
+	for _, team := range []struct {
+		name string
+		data models.BoxscoreTeam
+	}{{awayCN, game.AwayTeam}, {homeCN, game.HomeTeam}} {
+		fmt.Printf("  %s", team.name)
+		for _, period := range team.data.Periods {
+			fmt.Printf("  %3d", period.Score)
+		}
+		fmt.Printf("  | %3d\n", team.data.Score)
+	}
+
+	// 球員數據（先發在前，依得分排序）
+	for _, team := range []struct {
+		name string
+		data models.BoxscoreTeam
+	}{{awayCN, game.AwayTeam}, {homeCN, game.HomeTeam}} {
+		fmt.Printf("\n  %s\n", team.name)
+		players := crawler.SortPlayersByStarterAndPoints(crawler.BuildPlayerDisplayList(team.data.Players))
+		if len(players) == 0 {
+			fmt.Println("  尚無球員數據")
+			continue
+		}
+		for _, p := range players {
+			starter := " "
+			if p.IsStarter {
+				starter = "*"
+			}
+			fmt.Printf("  %s #%-3s %-24s %5s  %3d分 %3d籃板 %3d助攻\n",
+				starter, p.JerseyNum, p.Name, p.Minutes, p.Points, p.Rebounds, p.Assists)
+		}
+	}
+	return nil
+}
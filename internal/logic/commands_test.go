@@ -0,0 +1,85 @@
+package logic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"nba-scanner/internal/crawler"
+	"nba-scanner/internal/render"
+	"nba-scanner/internal/slate"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// 錄製資料：湖人主場對勇士，已結束
+const (
+	testSchedule = `{"leagueSchedule":{"gameDates":[{"gameDate":"11/01/2025 00:00:00","games":[{
+		"gameId":"0022500101","gameCode":"20251101/GSWLAL","gameStatus":3,"gameStatusText":"Final",
+		"gameDateTimeEst":"2025-11-01T22:30:00Z","gameDateTimeUTC":"2025-11-02T02:30:00Z",
+		"homeTeam":{"teamId":1610612747,"teamName":"Lakers","teamCity":"Los Angeles","score":112,"wins":4,"losses":2},
+		"awayTeam":{"teamId":1610612744,"teamName":"Warriors","teamCity":"Golden State","score":108,"wins":3,"losses":3}}]}]}}`
+	testBoxscore = `{"game":{"gameId":"0022500101","gameStatus":3,"gameStatusText":"Final","period":4,"gameClock":"PT00M00.00S",
+		"homeTeam":{"teamId":1610612747,"teamName":"Lakers","score":112,
+			"periods":[{"period":1,"score":30},{"period":2,"score":28},{"period":3,"score":26},{"period":4,"score":28}],
+			"players":[{"personId":2544,"name":"LeBron James","jerseyNum":"23","starter":"1","played":"1","statistics":{"points":30,"minutes":"PT36M00.00S"}}]},
+		"awayTeam":{"teamId":1610612744,"teamName":"Warriors","score":108,
+			"periods":[{"period":1,"score":27},{"period":2,"score":25},{"period":3,"score":29},{"period":4,"score":27}],
+			"players":[{"personId":201939,"name":"Stephen Curry","jerseyNum":"30","starter":"1","played":"1","statistics":{"points":35,"minutes":"PT35M00.00S"}}]}}}`
+)
+
+func TestGamesJSONMatchesAPI(t *testing.T) {
+	dir := t.TempDir()
+	for name, body := range map[string]string{
+		"scheduleLeagueV2_9.json":  testSchedule,
+		"boxscore_0022500101.json": testBoxscore,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := context.Background()
+	src := crawler.NewFixtureSources(dir)
+	rs, err := NewSlateResolver(src, slate.Config{Override: "2025-11-01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	injuries := NewInjuryTracker(nil)
+
+	_, cli, err := gamesSlate(ctx, src, rs, injuries, GamesQuery{Date: "2025-11-01"}, time.UTC)
+	if err != nil {
+		t.Fatalf("CLI 比賽日建立失敗: %v", err)
+	}
+	api, err := GetGamesByDate(ctx, src, rs, injuries, "2025-11-01", "", time.UTC)
+	if err != nil {
+		t.Fatalf("/api/games 比賽日建立失敗: %v", err)
+	}
+
+	// 上游狀態包含抓取時間與耗時，每次建立都不同
+	cli.Sources, api.Sources = nil, nil
+
+	var got bytes.Buffer
+	if err := (render.JSON{}).RenderSlate(&got, cli); err != nil {
+		t.Fatal(err)
+	}
+	want, err := json.Marshal(api)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(bytes.TrimSpace(got.Bytes()), want) {
+		t.Errorf("games -o json 與 /api/games 不同\nCLI: %s\nAPI: %s", got.Bytes(), want)
+	}
+
+	if len(api.Games) != 1 {
+		t.Fatalf("比賽數 = %d，預期 1", len(api.Games))
+	}
+	game := api.Games[0]
+	if game.HomeHistory == nil || game.AwayHistory == nil {
+		t.Error("缺少兩隊近期戰績")
+	}
+	if len(game.HomePlayers) == 0 || len(game.AwayPlayers) == 0 {
+		t.Error("缺少球員數據")
+	}
+}
@@ -0,0 +1,306 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"nba-scanner/internal/crawler"
+	"nba-scanner/internal/gametime"
+	"nba-scanner/internal/models"
+	"nba-scanner/internal/store"
+	"nba-scanner/internal/teams"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// injuryChangeRetention 傷兵變動保留的時間
+	injuryChangeRetention = 7 * 24 * time.Hour
+	// gameInjuryWindow 比賽資訊顯示開賽前多久內的傷兵變動
+	gameInjuryWindow = 24 * time.Hour
+)
+
+// injuryChangeLog 傷兵名單變動的保存位置（store.Store 或記憶體）
+type injuryChangeLog interface {
+	SaveInjuryChanges(changes []models.InjuryChange, at time.Time) error
+	InjuryChanges(from, to time.Time) ([]models.InjuryChange, error)
+	PruneInjuryChanges(before time.Time) (int, error)
+}
+
+// InjuryTracker 比對前後兩次傷兵名單，記錄每位球員的狀態變動
+// 輪詢與每次建立比賽日資料抓到的名單都會更新；有資料庫時變動保存在資料庫，重啟後仍可查詢
+type InjuryTracker struct {
+	mu   sync.Mutex
+	last map[string][]models.Injury // 上一次的傷兵名單（nil 表示尚未有基準）
+	log  injuryChangeLog
+}
+
+// NewInjuryTracker 建立傷兵變動紀錄（db 為 nil 時只保存在記憶體）
+// 有資料庫時以最後一次保存的名單作為比對基準，重啟期間的變動也會被偵測到
+func NewInjuryTracker(db *store.Store) *InjuryTracker {
+	if db == nil {
+		return &InjuryTracker{log: &memoryInjuryLog{}}
+	}
+
+	t := &InjuryTracker{log: db}
+	if latest, _, err := db.LatestInjuries(); err != nil {
+		log.Printf("讀取傷兵名單失敗: %v", err)
+	} else if len(latest) > 0 {
+		t.last = latest
+	}
+	return t
+}
+
+// StartInjuryPoller 在背景定期抓取傷兵名單並記錄變動（server 模式使用），ctx 取消時停止
+func StartInjuryPoller(ctx context.Context, src crawler.InjurySource, tracker *InjuryTracker, interval time.Duration) {
+	go func() {
+		pollInjuries(ctx, src, tracker)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				pollInjuries(ctx, src, tracker)
+			}
+		}
+	}()
+}
+
+// pollInjuries 抓取一次傷兵名單並比對變動
+func pollInjuries(ctx context.Context, src crawler.InjurySource, tracker *InjuryTracker) {
+	injuryMap, err := src.FetchInjuryMap(ctx)
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		// 資料庫或斷路前的舊名單不是新的抓取結果，不比對變動
+		log.Printf("輪詢傷兵失敗: %v", err)
+		return
+	}
+
+	if n := tracker.Observe(injuryMap, time.Now()); n > 0 {
+		log.Printf("傷兵輪詢：%d 筆名單變動", n)
+	}
+}
+
+// Observe 與上一次的名單比對並記錄變動，回傳新增的變動筆數
+// 只能傳入成功抓取的名單，資料庫的備援名單（*crawler.StaleError）會被當成名單變動
+func (t *InjuryTracker) Observe(injuryMap map[string][]models.Injury, at time.Time) int {
+	// 整份名單為空通常是頁面解析失敗，不視為所有人都已歸隊
+	if len(injuryMap) == 0 {
+		return 0
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.last == nil {
+		t.last = injuryMap
+		return 0
+	}
+
+	var changes []models.InjuryChange
+	for _, team := range unionKeys(t.last, injuryMap) {
+		changes = append(changes, diffTeamInjuries(team, t.last[team], injuryMap[team], at)...)
+	}
+	t.last = injuryMap
+
+	if err := t.log.SaveInjuryChanges(changes, at); err != nil {
+		log.Printf("保存傷兵變動失敗: %v", err)
+	}
+	// 移除超過保留時間的紀錄
+	if _, err := t.log.PruneInjuryChanges(at.Add(-injuryChangeRetention)); err != nil {
+		log.Printf("清除過期傷兵變動失敗: %v", err)
+	}
+
+	return len(changes)
+}
+
+// Between 取得 [from, to) 之間的變動（to 為零值表示不設上限，teamIDs 不為空時只回傳這些球隊）
+func (t *InjuryTracker) Between(from, to time.Time, teamIDs ...int) ([]models.InjuryChange, error) {
+	changes, err := t.log.InjuryChanges(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []models.InjuryChange{}
+	for _, change := range changes {
+		if len(teamIDs) > 0 && !slices.Contains(teamIDs, change.TeamID) {
+			continue
+		}
+		result = append(result, change)
+	}
+	return result, nil
+}
+
+// memoryInjuryLog 沒有資料庫時保存在記憶體的傷兵變動
+type memoryInjuryLog struct {
+	mu      sync.RWMutex
+	changes []models.InjuryChange // 依時間排序
+	times   []time.Time           // 與 changes 對應的時間
+}
+
+// SaveInjuryChanges 加入同一次比對產生的變動
+func (m *memoryInjuryLog) SaveInjuryChanges(changes []models.InjuryChange, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, change := range changes {
+		m.changes = append(m.changes, change)
+		m.times = append(m.times, at)
+	}
+	return nil
+}
+
+// InjuryChanges 取得 [from, to) 之間的變動
+func (m *memoryInjuryLog) InjuryChanges(from, to time.Time) ([]models.InjuryChange, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []models.InjuryChange
+	for i, change := range m.changes {
+		if m.times[i].Before(from) || (!to.IsZero() && !m.times[i].Before(to)) {
+			continue
+		}
+		result = append(result, change)
+	}
+	return result, nil
+}
+
+// PruneInjuryChanges 移除指定時間之前的變動
+func (m *memoryInjuryLog) PruneInjuryChanges(before time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	drop := 0
+	for drop < len(m.times) && m.times[drop].Before(before) {
+		drop++
+	}
+	m.changes = m.changes[drop:]
+	m.times = m.times[drop:]
+	return drop, nil
+}
+
+// diffTeamInjuries 比對單一球隊前後兩次的傷兵名單
+func diffTeamInjuries(team string, before, after []models.Injury, at time.Time) []models.InjuryChange {
+	var teamID int
+	var tricode string
+	if t, ok := teams.Lookup(team); ok {
+		teamID, tricode = t.ID, t.Tricode
+	}
+	newChange := func(injury models.Injury, changeType string) models.InjuryChange {
+		return models.InjuryChange{
+			Team:        team,
+			TeamID:      teamID,
+			Tricode:     tricode,
+			PlayerName:  injury.PlayerName,
+			PersonID:    injury.PersonID,
+			ESPNID:      injury.ESPNID,
+			Type:        changeType,
+			Description: injury.Description,
+			At:          gametime.FormatUTC(at),
+		}
+	}
+
+	previous := make(map[string]models.Injury, len(before))
+	for _, injury := range before {
+		previous[injuryKey(injury)] = injury
+	}
+
+	var changes []models.InjuryChange
+	seen := make(map[string]bool, len(after))
+	for _, injury := range after {
+		key := injuryKey(injury)
+		seen[key] = true
+
+		old, ok := previous[key]
+		switch {
+		case !ok:
+			change := newChange(injury, models.InjuryChangeAdded)
+			change.To = injury.Status
+			changes = append(changes, change)
+		case old.Status != injury.Status:
+			change := newChange(injury, models.InjuryChangeStatus)
+			change.From, change.To = old.Status, injury.Status
+			changes = append(changes, change)
+		}
+	}
+	for _, injury := range before {
+		if !seen[injuryKey(injury)] {
+			change := newChange(injury, models.InjuryChangeCleared)
+			change.From = injury.Status
+			change.Description = ""
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// injuryKey 比對同一位球員用的 key（優先使用 ESPN 球員 ID）
+func injuryKey(injury models.Injury) string {
+	if injury.ESPNID != "" {
+		return "espn:" + injury.ESPNID
+	}
+	return "name:" + strings.ToLower(injury.PlayerName)
+}
+
+// unionKeys 兩份名單所有的球隊（依隊名排序，讓同一次輪詢的變動順序固定）
+func unionKeys(a, b map[string][]models.Injury) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// GetInjuryChanges 取得指定時間之後的傷兵名單變動
+func GetInjuryChanges(tracker *InjuryTracker, since time.Time) (*models.InjuryChanges, error) {
+	changes, err := tracker.Between(since, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	return &models.InjuryChanges{
+		Since:   gametime.FormatUTC(since),
+		Changes: changes,
+	}, nil
+}
+
+// ParseSince 解析 since 參數：RFC3339 時間、美東日期 YYYY-MM-DD（當天 00:00）或往前的時間長度（如 6h）
+func ParseSince(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if day, err := time.ParseInLocation("2006-01-02", s, gametime.Eastern); err == nil {
+		return day, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("since 格式錯誤（應為 RFC3339、YYYY-MM-DD 或時間長度如 6h）: %q", s)
+}
+
+// gameInjuryChanges 兩隊在開賽前 24 小時內的傷兵變動（比賽尚未開始時為最近 24 小時）
+func (t *InjuryTracker) gameInjuryChanges(info models.GameInfo) []models.InjuryChange {
+	now := time.Now()
+	from, to := now.Add(-gameInjuryWindow), time.Time{}
+	if start, err := time.Parse(time.RFC3339, info.GameTimeUTC); err == nil && start.Before(now) {
+		from, to = start.Add(-gameInjuryWindow), start
+	}
+	changes, err := t.Between(from, to, info.HomeTeam.TeamID, info.AwayTeam.TeamID)
+	if err != nil {
+		log.Printf("讀取傷兵變動失敗 (GameID: %s): %v", info.GameID, err)
+		return []models.InjuryChange{}
+	}
+	return changes
+}
@@ -0,0 +1,194 @@
+package logic
+
+import (
+	"context"
+	"log"
+	"math"
+	"nba-scanner/internal/crawler"
+	"nba-scanner/internal/models"
+	"nba-scanner/internal/store"
+	"strconv"
+	"time"
+)
+
+// SteamConfig 急速變盤的判斷條件
+type SteamConfig struct {
+	Threshold float64       // 變動幅度門檻（分）
+	Window    time.Duration // 時間窗
+}
+
+// DefaultSteamConfig 預設：30 分鐘內變動 1 分以上
+var DefaultSteamConfig = SteamConfig{
+	Threshold: 1.0,
+	Window:    30 * time.Minute,
+}
+
+// oddsHistoryRetention 賠率快照保留多久（之後只留各莊家最新的一筆）
+const oddsHistoryRetention = 14 * 24 * time.Hour
+
+// StartOddsPoller 在背景定期抓取賠率（server 模式使用），ctx 取消時停止
+// odds 為 store.Persist 包裝後的來源，抓取時即保存各莊家的盤口變動，這裡不再重複寫入
+func StartOddsPoller(ctx context.Context, odds crawler.OddsSource, db *store.Store, interval time.Duration) {
+	go func() {
+		pollOdds(ctx, odds, db)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				pollOdds(ctx, odds, db)
+			}
+		}
+	}()
+}
+
+// pollOdds 抓取一次賠率（由 Persist 保存快照）並清除過期的快照
+func pollOdds(ctx context.Context, odds crawler.OddsSource, db *store.Store) {
+	if _, err := odds.FetchOdds(ctx); err != nil {
+		if ctx.Err() == nil {
+			log.Printf("輪詢賠率失敗: %v", err)
+		}
+		return
+	}
+
+	now := time.Now()
+	if pruned, err := db.PruneOddsHistory(now.Add(-oddsHistoryRetention)); err != nil {
+		log.Printf("清除過期賠率快照失敗: %v", err)
+	} else if pruned > 0 {
+		log.Printf("清除 %d 筆過期賠率快照", pruned)
+	}
+}
+
+// GetOddsHistory 取得單場比賽各莊家的讓分/大小分走勢，並標記急速變盤
+func GetOddsHistory(db *store.Store, gameID string, steam SteamConfig) (*models.OddsHistory, error) {
+	snapshots, err := db.OddsHistory(gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &models.OddsHistory{
+		GameID:     gameID,
+		Books:      []models.OddsHistoryBook{},
+		SteamMoves: []models.SteamMove{},
+	}
+
+	bookIndex := make(map[string]int)
+	var times [][]time.Time // 與 Books[i].Points 對應的時間
+	for _, snap := range snapshots {
+		idx, ok := bookIndex[snap.BookID]
+		if !ok {
+			idx = len(history.Books)
+			bookIndex[snap.BookID] = idx
+			history.Books = append(history.Books, models.OddsHistoryBook{
+				BookID:   snap.BookID,
+				BookName: snap.BookName,
+			})
+			times = append(times, nil)
+		}
+
+		point := models.OddsHistoryPoint{Time: snap.CapturedAt.UTC().Format(time.RFC3339)}
+		for name, outcomes := range snap.Markets {
+			kind := models.MarketKind(name)
+			for _, outcome := range outcomes {
+				value, err := strconv.ParseFloat(outcome.Spread, 64)
+				if err != nil {
+					continue
+				}
+				switch {
+				case kind == models.MarketSpread && outcome.Type == "home":
+					point.HomeSpread = &value
+				case kind == models.MarketSpread && outcome.Type == "away":
+					point.AwaySpread = &value
+				case kind == models.MarketTotal && point.Total == nil:
+					point.Total = &value
+				}
+			}
+		}
+
+		// 快照可能只是賠率（水位）變動，盤口數值沒變時不新增時間點
+		points := history.Books[idx].Points
+		if len(points) > 0 && sameLine(points[len(points)-1], point) {
+			continue
+		}
+		history.Books[idx].Points = append(points, point)
+		times[idx] = append(times[idx], snap.CapturedAt)
+	}
+
+	for i := range history.Books {
+		book := &history.Books[i]
+		history.SteamMoves = append(history.SteamMoves,
+			detectSteam(book, times[i], "spread", func(p models.OddsHistoryPoint) *float64 { return p.HomeSpread }, steam)...)
+		history.SteamMoves = append(history.SteamMoves,
+			detectSteam(book, times[i], "total", func(p models.OddsHistoryPoint) *float64 { return p.Total }, steam)...)
+	}
+
+	return history, nil
+}
+
+// detectSteam 找出時間窗內變動超過門檻的走勢，並標記在對應的時間點上
+// 同一個起點只保留變動最大的一筆
+func detectSteam(book *models.OddsHistoryBook, times []time.Time, market string, line func(models.OddsHistoryPoint) *float64, steam SteamConfig) []models.SteamMove {
+	var moves []models.SteamMove
+	byStart := make(map[int]int) // 起點 index -> moves index
+
+	for j := range book.Points {
+		to := line(book.Points[j])
+		if to == nil {
+			continue
+		}
+
+		// 在時間窗內找出變動最大的起點
+		best, bestDelta := -1, 0.0
+		for i := j - 1; i >= 0 && times[j].Sub(times[i]) <= steam.Window; i-- {
+			from := line(book.Points[i])
+			if from == nil {
+				continue
+			}
+			if delta := *to - *from; math.Abs(delta) > math.Abs(bestDelta) {
+				best, bestDelta = i, delta
+			}
+		}
+		if best < 0 || math.Abs(bestDelta) < steam.Threshold {
+			continue
+		}
+
+		book.Points[j].Steam = true
+		move := models.SteamMove{
+			BookID: book.BookID,
+			Market: market,
+			From:   *line(book.Points[best]),
+			To:     *to,
+			Delta:  bestDelta,
+			Start:  book.Points[best].Time,
+			End:    book.Points[j].Time,
+		}
+		if k, ok := byStart[best]; ok {
+			if math.Abs(bestDelta) > math.Abs(moves[k].Delta) {
+				moves[k] = move
+			}
+			continue
+		}
+		byStart[best] = len(moves)
+		moves = append(moves, move)
+	}
+
+	return moves
+}
+
+// sameLine 兩個時間點的盤口數值是否相同
+func sameLine(a, b models.OddsHistoryPoint) bool {
+	return equalLine(a.HomeSpread, b.HomeSpread) &&
+		equalLine(a.AwaySpread, b.AwaySpread) &&
+		equalLine(a.Total, b.Total)
+}
+
+func equalLine(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
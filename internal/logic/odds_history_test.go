@@ -0,0 +1,109 @@
+package logic
+
+import (
+	"nba-scanner/internal/models"
+	"nba-scanner/internal/store"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDetectSteam(t *testing.T) {
+	t0 := time.Date(2026, 1, 14, 12, 0, 0, 0, time.UTC)
+	steam := SteamConfig{Threshold: 1.0, Window: 30 * time.Minute}
+
+	type point struct {
+		minute int
+		line   float64
+	}
+	tests := []struct {
+		name      string
+		points    []point
+		wantMoves []float64 // 各筆急速變盤的 Delta
+		wantSteam []bool    // 各時間點是否標記為急速變盤
+	}{
+		{"變動未達門檻", []point{{0, -4.5}, {10, -5}}, nil, []bool{false, false}},
+		{"剛好達到門檻", []point{{0, -4.5}, {10, -5.5}}, []float64{-1}, []bool{false, true}},
+		{"時間窗內累積超過門檻", []point{{0, -4.5}, {10, -5}, {20, -6}}, []float64{-1.5}, []bool{false, false, true}},
+		{"剛好在時間窗邊界", []point{{0, -4.5}, {30, -6}}, []float64{-1.5}, []bool{false, true}},
+		{"超過時間窗不算", []point{{0, -4.5}, {31, -6}}, nil, []bool{false, false}},
+		{"盤口回升也算", []point{{0, -6}, {5, -4.5}}, []float64{1.5}, []bool{false, true}},
+		// 同一個起點只保留變動最大的一筆
+		{"同一起點取最大變動", []point{{0, -4.5}, {5, -5.5}, {10, -6.5}}, []float64{-2}, []bool{false, true, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := &models.OddsHistoryBook{BookID: "sr:book:818"}
+			var times []time.Time
+			for _, p := range tt.points {
+				line := p.line
+				at := t0.Add(time.Duration(p.minute) * time.Minute)
+				book.Points = append(book.Points, models.OddsHistoryPoint{Time: at.Format(time.RFC3339), HomeSpread: &line})
+				times = append(times, at)
+			}
+
+			moves := detectSteam(book, times, "spread", func(p models.OddsHistoryPoint) *float64 { return p.HomeSpread }, steam)
+			if len(moves) != len(tt.wantMoves) {
+				t.Fatalf("急速變盤 %d 筆，預期 %d: %+v", len(moves), len(tt.wantMoves), moves)
+			}
+			for i, move := range moves {
+				if move.Delta != tt.wantMoves[i] {
+					t.Errorf("第 %d 筆變動 = %v，預期 %v", i+1, move.Delta, tt.wantMoves[i])
+				}
+			}
+			for i, p := range book.Points {
+				if p.Steam != tt.wantSteam[i] {
+					t.Errorf("第 %d 個時間點 Steam = %v，預期 %v", i+1, p.Steam, tt.wantSteam[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGetOddsHistory(t *testing.T) {
+	db, err := store.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	t0 := time.Date(2026, 1, 14, 12, 0, 0, 0, time.UTC)
+	snapshot := func(minute int, lines map[string]string, odds string) {
+		t.Helper()
+		market := models.OddsMarket{Name: "spread"}
+		for _, bookID := range []string{"sr:book:818", "sr:book:1"} {
+			if line, ok := lines[bookID]; ok {
+				market.Books = append(market.Books, models.Bookmaker{ID: bookID, Name: bookID, Outcomes: []models.Outcome{
+					{Type: "home", Odds: odds, Spread: line},
+				}})
+			}
+		}
+		game := models.OddsGame{GameID: "g1", Markets: []models.OddsMarket{market}}
+		if _, err := db.SaveOddsSnapshot(game, t0.Add(time.Duration(minute)*time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 818 在 20 分鐘內從 -4.5 變到 -6，另一家同時間只變動 0.5
+	snapshot(0, map[string]string{"sr:book:818": "-4.5", "sr:book:1": "-4.5"}, "1.91")
+	snapshot(5, map[string]string{"sr:book:818": "-4.5", "sr:book:1": "-4.5"}, "1.95") // 只有水位變動
+	snapshot(20, map[string]string{"sr:book:818": "-6", "sr:book:1": "-5"}, "1.95")
+
+	history, err := GetOddsHistory(db, "g1", DefaultSteamConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history.Books) != 2 {
+		t.Fatalf("莊家數 = %d，預期 2", len(history.Books))
+	}
+	for _, book := range history.Books {
+		// 水位變動不新增時間點
+		if len(book.Points) != 2 {
+			t.Errorf("%s 時間點 = %d，預期 2", book.BookID, len(book.Points))
+		}
+	}
+	// 急速變盤以各莊家分開判斷，不需要多家同時變動
+	if len(history.SteamMoves) != 1 || history.SteamMoves[0].BookID != "sr:book:818" || history.SteamMoves[0].Delta != -1.5 {
+		t.Errorf("SteamMoves = %+v，預期只有 sr:book:818 的 -1.5", history.SteamMoves)
+	}
+}
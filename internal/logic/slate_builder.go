@@ -0,0 +1,135 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"nba-scanner/internal/crawler"
+	"nba-scanner/internal/gametime"
+	"nba-scanner/internal/models"
+	"sync"
+	"time"
+)
+
+// SlateOptions 建立比賽日資料時要抓取的項目
+type SlateOptions struct {
+	Location  *time.Location // 開賽時間與歷史戰績日期的顯示時區（nil 使用預設時區）
+	Odds      bool           // 賠率（odds_todaysGames.json 只有目前比賽日前後的比賽）
+	Book      string         // 只使用指定莊家的盤口（莊家 ID 或名稱，不分大小寫，空字串使用所有莊家）
+	History   bool           // 兩隊近期戰績與 titan007 過盤紀錄
+	Boxscores bool           // 進行中與已結束比賽的 boxscore（即時比分、各節得分與球員數據，不抓時只有賽程的比分）
+}
+
+// SlateBuilder 建立單一比賽日的完整資料
+// CLI 與 server 都從這裡取得 models.APIResponse，兩邊顯示的資料完全相同
+type SlateBuilder struct {
+	src      *crawler.Sources
+	injuries *InjuryTracker
+}
+
+// NewSlateBuilder 建立比賽日資料產生器（抓到的傷兵名單記錄到 injuries，並以此提供兩隊的傷兵變動）
+func NewSlateBuilder(src *crawler.Sources, injuries *InjuryTracker) *SlateBuilder {
+	return &SlateBuilder{src: src, injuries: injuries}
+}
+
+// Build 取得指定 NBA 比賽日（美東日期）的比賽資料
+// 賽程失敗時回傳錯誤；賠率、傷兵、boxscore、titan007 失敗時照常回傳，狀態記錄在 Sources
+func (b *SlateBuilder) Build(ctx context.Context, day time.Time, opts SlateOptions) (*models.APIResponse, error) {
+	if opts.Location == nil {
+		loc, err := gametime.LoadZone("")
+		if err != nil {
+			return nil, err
+		}
+		opts.Location = loc
+	}
+
+	// 賽程與比賽資訊共用同一份 boxscore，每場只抓一次
+	ctx = crawler.WithBoxscoreMemo(ctx)
+
+	var (
+		scoreboard  *models.NBAScoreboard
+		odds        *models.NBAOdds
+		injuryMap   map[string][]models.Injury
+		scheduleErr error
+		wg          sync.WaitGroup
+	)
+
+	// 1. 賽程（從完整賽季 API，依日期自動選擇賽季）
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		scoreboard, scheduleErr = crawler.FetchScheduleForDate(ctx, b.src, day)
+	}()
+
+	// 2. 賠率
+	if opts.Odds {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			od, err := b.src.Odds.FetchOdds(ctx)
+			if err != nil {
+				log.Printf("賠率抓取失敗: %v", err)
+				return
+			}
+			if opts.Book != "" {
+				od = od.FilterBook(opts.Book)
+			}
+			odds = od
+		}()
+	}
+
+	// 3. 傷兵
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		im, err := b.src.Injury.FetchInjuryMap(ctx)
+		switch {
+		case crawler.IsStale(err):
+			// 資料庫保存的名單照常顯示，但不是新的抓取結果，不比對變動
+			log.Printf("傷兵使用先前保存的名單: %v", err)
+		case err != nil:
+			log.Printf("傷兵抓取失敗: %v", err)
+			im = make(map[string][]models.Injury)
+		default:
+			b.injuries.Observe(im, time.Now())
+		}
+		injuryMap = im
+	}()
+
+	wg.Wait()
+
+	// 請求已取消或逾時，不再處理抓到的部分資料
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if scheduleErr != nil {
+		return nil, fmt.Errorf("抓取資料失敗: 賽程錯誤: %w", scheduleErr)
+	}
+
+	sn := b.src.SeasonFor(ctx, day)
+	response := &models.APIResponse{
+		Date:     day.Format("2006-01-02"),
+		Season:   sn.String(),
+		Phase:    seasonPhase(ctx, b.src, sn, day),
+		TimeZone: opts.Location.String(),
+		Games:    make([]models.GameInfo, 0, len(scoreboard.Scoreboard.Games)),
+	}
+
+	oddsMap := crawler.BuildOddsGameMap(odds) // odds 為 nil 時是空的 map
+	games, err := buildGameInfos(ctx, b.src, sn, scoreboard.Scoreboard.Games, oddsMap, injuryMap, opts)
+	if err != nil {
+		return nil, err
+	}
+	// 所有 boxscore 都抓完後才對應傷兵的 personId，結果不受各場比賽完成的先後影響
+	for i := range games {
+		games[i].HomeInjuries = linkInjuries(ctx, b.src, games[i].HomeTeam.TeamID, games[i].HomeInjuries)
+		games[i].AwayInjuries = linkInjuries(ctx, b.src, games[i].AwayTeam.TeamID, games[i].AwayInjuries)
+		games[i].InjuryChanges = b.injuries.gameInjuryChanges(games[i])
+	}
+	response.Games = append(response.Games, games...)
+
+	// 各上游的狀態（在所有抓取完成後取得，包含 boxscore 與 titan007）
+	response.Sources = b.src.Monitor.Statuses()
+
+	return response, nil
+}
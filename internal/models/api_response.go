@@ -2,49 +2,81 @@ package models
 
 // APIResponse API 回應格式
 type APIResponse struct {
-	Date  string     `json:"date"`
-	Games []GameInfo `json:"games"`
+	Date     string         `json:"date"`
+	Season   string         `json:"season"`   // 賽季（如 "2025-26"）
+	Phase    string         `json:"phase"`    // 賽季階段：preseason、regular-season、play-in、playoffs、offseason
+	TimeZone string         `json:"timeZone"` // gameTime 與歷史戰績日期使用的顯示時區（IANA 名稱）
+	Games    []GameInfo     `json:"games"`
+	Sources  []SourceStatus `json:"sources"` // 各上游資料來源的狀態（失敗時前端可顯示「傷兵資料無法取得」）
 }
 
 // GameInfo 單場比賽資訊（用於前端）
 type GameInfo struct {
 	GameID         string          `json:"gameId"`         // 比賽 ID
 	GameTime       string          `json:"gameTime"`       // 開賽時間或比賽狀態 (Q4 04:00)
-	GameTimeUTC    string          `json:"gameTimeUTC"`    // UTC 時間（用於排序）
+	GameTimeUTC    string          `json:"gameTimeUTC"`    // 開賽時間（UTC，RFC 3339，用於排序與前端換算）
 	GameStatus     int             `json:"gameStatus"`     // 1=未開始, 2=進行中, 3=已結束
 	GameStatusText string          `json:"gameStatusText"` // 狀態文字
+	GameType       string          `json:"gameType"`       // 比賽類型：preseason、regular、cup、all-star、play-in、playoffs
+	GameLabel      string          `json:"gameLabel"`      // 賽事標籤（如 "Emirates NBA Cup"）
 	ScoreDisplay   string          `json:"scoreDisplay"`   // 比分顯示 "[98-128]" 或空字串
 	HomeTeam       TeamInfo        `json:"homeTeam"`
 	AwayTeam       TeamInfo        `json:"awayTeam"`
 	HomeScore      int             `json:"homeScore"`      // 主隊比分
 	AwayScore      int             `json:"awayScore"`      // 客隊比分
 	Spread         SpreadDisplay   `json:"spread"`
-	HomeInjuries   []string        `json:"homeInjuries"`
-	AwayInjuries   []string        `json:"awayInjuries"`
+	Totals         TotalsDisplay   `json:"totals"`
+	Odds           *OddsComparison `json:"odds,omitempty"` // 各莊家盤口比較（共識/最佳盤口）
+	HomeInjuries   []Injury        `json:"homeInjuries"`
+	AwayInjuries   []Injury        `json:"awayInjuries"`
+	InjuryChanges  []InjuryChange  `json:"injuryChanges"` // 兩隊開賽前 24 小時內的傷兵名單變動
 	HomeHistory    *TeamHistory    `json:"homeHistory,omitempty"`
 	AwayHistory    *TeamHistory    `json:"awayHistory,omitempty"`
 	HomePlayers    []PlayerDisplay `json:"homePlayers,omitempty"`    // 主隊球員數據（進行中時才有）
 	AwayPlayers    []PlayerDisplay `json:"awayPlayers,omitempty"`    // 客隊球員數據（進行中時才有）
 	PeriodScores   *PeriodScores   `json:"periodScores,omitempty"`   // 各節比分（進行中或已結束才有）
+	Settlement     *BetSettlement  `json:"settlement,omitempty"`     // 各時段讓分/獨贏/大小分結算（已打完的時段才有）
 }
 
 // PeriodScores 各節比分顯示
 type PeriodScores struct {
-	HomePeriods []int `json:"homePeriods"` // 主隊各節得分 [Q1, Q2, Q3, Q4]
-	AwayPeriods []int `json:"awayPeriods"` // 客隊各節得分 [Q1, Q2, Q3, Q4]
+	HomePeriods []int `json:"homePeriods"` // 主隊各節得分 [Q1, Q2, Q3, Q4, OT1...]
+	AwayPeriods []int `json:"awayPeriods"` // 客隊各節得分 [Q1, Q2, Q3, Q4, OT1...]
 }
 
 // TeamInfo 球隊資訊
 type TeamInfo struct {
-	NameEN string `json:"nameEN"`
-	NameCN string `json:"nameCN"`
-	Wins   int    `json:"wins"`   // 勝場
-	Losses int    `json:"losses"` // 敗場
+	TeamID  int    `json:"teamId"`  // NBA teamId
+	Tricode string `json:"tricode"` // 三碼縮寫（LAL）
+	NameEN  string `json:"nameEN"`
+	NameCN  string `json:"nameCN"` // 中文隊名（繁體）
+	Kind    string `json:"kind"`   // 球隊類型：nba、international（海外球隊）、exhibition（表演賽隊伍）
+	Wins    int    `json:"wins"`   // 勝場
+	Losses  int    `json:"losses"` // 敗場
 }
 
 // SpreadDisplay 即時盤口顯示（只顯示主隊）
 type SpreadDisplay struct {
-	Opening string `json:"opening"` // 開盤讓分
+	Opening string `json:"opening"` // 開盤讓分（莊家沒有開盤資料時為空字串）
 	Current string `json:"current"` // 即時讓分
+	Book    string `json:"book"`    // 盤口來源莊家
 	HasData bool   `json:"hasData"` // 是否有賠率資料
 }
+
+// 上游資料來源狀態（SourceStatus.Status）
+const (
+	SourceOK    = "ok"    // 最近一次抓取成功
+	SourceError = "error" // 最近一次抓取失敗（資料可能缺少或為舊資料）
+	SourceStale = "stale" // 上游斷路中，使用之前成功抓到的舊資料
+	SourceIdle  = "idle"  // 尚未抓取
+)
+
+// SourceStatus 單一上游資料來源的狀態
+type SourceStatus struct {
+	Name        string `json:"name"`                  // schedule、odds、injuries、boxscore、titan007
+	Status      string `json:"status"`                // ok、error、stale、idle
+	LatencyMs   int64  `json:"latencyMs"`             // 最近一次抓取耗時（毫秒）
+	LastAttempt string `json:"lastAttempt,omitempty"` // 最近一次抓取時間（RFC3339，UTC）
+	LastSuccess string `json:"lastSuccess,omitempty"` // 最近一次成功的時間（RFC3339，UTC）
+	Error       string `json:"error,omitempty"`       // 最近一次失敗的錯誤訊息
+}
@@ -0,0 +1,11 @@
+package models
+
+// 比賽類型（GameInfo.GameType）
+const (
+	GameTypePreseason = "preseason" // 季前賽
+	GameTypeRegular   = "regular"   // 例行賽
+	GameTypeCup       = "cup"       // NBA 盃（小組賽、淘汰賽與冠軍戰）
+	GameTypeAllStar   = "all-star"  // 明星賽
+	GameTypePlayIn    = "play-in"   // 附加賽
+	GameTypePlayoffs  = "playoffs"  // 季後賽
+)
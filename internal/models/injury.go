@@ -0,0 +1,84 @@
+package models
+
+import "strings"
+
+// InjuryStatus 傷兵狀態（依出賽可能性由低到高）
+type InjuryStatus string
+
+const (
+	InjuryOut          InjuryStatus = "Out"          // 確定缺陣
+	InjuryDoubtful     InjuryStatus = "Doubtful"     // 出賽機率低
+	InjuryQuestionable InjuryStatus = "Questionable" // 出賽成疑
+	InjuryDayToDay     InjuryStatus = "Day-To-Day"   // 每日觀察（GTD）
+	InjuryProbable     InjuryStatus = "Probable"     // 可望出賽
+	InjuryUnknown      InjuryStatus = "Unknown"      // 無法辨識的狀態
+)
+
+// ParseInjuryStatus 將 ESPN 的狀態文字轉為傷兵狀態（不分大小寫，GTD 視為 Day-To-Day）
+func ParseInjuryStatus(s string) InjuryStatus {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "out", "out for season", "suspension", "suspended":
+		return InjuryOut
+	case "doubtful":
+		return InjuryDoubtful
+	case "questionable":
+		return InjuryQuestionable
+	case "day-to-day", "day to day", "gtd", "game-time decision", "game time decision":
+		return InjuryDayToDay
+	case "probable":
+		return InjuryProbable
+	default:
+		return InjuryUnknown
+	}
+}
+
+// Injury 單一球員的傷兵資訊
+type Injury struct {
+	PlayerName  string       `json:"playerName"`
+	PersonID    int          `json:"personId,omitempty"`   // NBA personId（比對 boxscore 球員名單，找不到時為 0）
+	ESPNID      string       `json:"espnId,omitempty"`     // ESPN 球員 ID
+	ESPNURL     string       `json:"espnUrl,omitempty"`    // ESPN 球員頁面
+	Position    string       `json:"position,omitempty"`   // 位置（G/F/C）
+	Status      InjuryStatus `json:"status"`               // 正規化後的狀態
+	StatusText  string       `json:"statusText"`           // ESPN 原始狀態文字
+	ReturnDate  string       `json:"returnDate,omitempty"` // 預計復出日期 "2026-01-20"
+	ReportDate  string       `json:"reportDate,omitempty"` // 傷情更新日期 "2026-01-14"
+	Description string       `json:"description"`          // 傷情說明
+}
+
+// String 單行顯示格式："姓名 狀態 說明"
+func (i Injury) String() string {
+	parts := []string{i.PlayerName, i.StatusText, i.Description}
+	if i.StatusText == "" {
+		parts[1] = string(i.Status)
+	}
+	return strings.Join(strings.Fields(strings.Join(parts, " ")), " ")
+}
+
+// 傷兵名單變動類型（InjuryChange.Type）
+const (
+	InjuryChangeAdded   = "added"   // 新列入傷兵名單
+	InjuryChangeStatus  = "status"  // 狀態改變（如 Questionable → Out）
+	InjuryChangeCleared = "cleared" // 從傷兵名單移除
+)
+
+// InjuryChange 兩次傷兵名單之間的單一球員變動
+type InjuryChange struct {
+	Team        string       `json:"team"`    // ESPN 隊名
+	TeamID      int          `json:"teamId"`  // NBA teamId（對應不到時為 0）
+	Tricode     string       `json:"tricode"` // 三碼縮寫
+	PlayerName  string       `json:"playerName"`
+	PersonID    int          `json:"personId,omitempty"`
+	ESPNID      string       `json:"espnId,omitempty"`
+	Type        string       `json:"type"`                  // added、status、cleared
+	From        InjuryStatus `json:"from,omitempty"`        // 變動前狀態（新列入時為空）
+	To          InjuryStatus `json:"to,omitempty"`          // 變動後狀態（移除時為空）
+	Description string       `json:"description,omitempty"` // 變動後的傷情說明
+	At          string       `json:"at"`                    // 偵測到變動的時間（RFC3339，UTC）
+}
+
+// InjuryChanges 傷兵名單變動列表（/api/injuries/changes）
+type InjuryChanges struct {
+	Since   string         `json:"since"` // 查詢起點（RFC3339，UTC）
+	Changes []InjuryChange `json:"changes"`
+}
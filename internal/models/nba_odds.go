@@ -1,5 +1,7 @@
 package models
 
+import "strings"
+
 // NBAOdds 賠率 API 回應結構
 type NBAOdds struct {
 	Games []OddsGame `json:"games"`
@@ -29,47 +31,104 @@ type Bookmaker struct {
 
 // Outcome 賠率結果
 type Outcome struct {
-	Type          string  `json:"type"`
-	Odds          string  `json:"odds"`
-	Spread        string  `json:"spread,omitempty"`
-	OpeningSpread float64 `json:"opening_spread,omitempty"`
+	Type          string   `json:"type"`
+	Odds          string   `json:"odds"`
+	Spread        string   `json:"spread,omitempty"`
+	OpeningSpread *float64 `json:"opening_spread,omitempty"` // 沒有開盤資料時為 nil（0 是平手盤）
 }
 
+// SupermatchBookID 預設顯示的莊家（Supermatch）
+const SupermatchBookID = "sr:book:818"
+
 // SpreadInfo 整理後的讓分盤資訊
 type SpreadInfo struct {
 	HomeSpread        string
 	AwaySpread        string
-	HomeOpeningSpread float64
-	AwayOpeningSpread float64
+	HomeOpeningSpread *float64 // 沒有開盤資料時為 nil（0 是平手盤）
+	AwayOpeningSpread *float64
+	BookID            string
+	BookName          string
 	Found             bool
 }
 
 // GetSupermatchSpread 取得 Supermatch 的讓分盤資訊
 func (og *OddsGame) GetSupermatchSpread() SpreadInfo {
+	return og.GetSpread(SupermatchBookID)
+}
+
+// GetSpread 取得指定莊家的讓分盤資訊
+func (og *OddsGame) GetSpread(bookID string) SpreadInfo {
 	result := SpreadInfo{Found: false}
 
 	// 找到 spread market (name = "spread")
 	for _, market := range og.Markets {
-		if market.Name == "spread" {
-			// 找 Supermatch bookmaker (id: "sr:book:818")
+		if MarketKind(market.Name) == MarketSpread {
+			for _, bookmaker := range market.Books {
+				if bookmaker.ID == bookID {
+					return spreadFromBook(bookmaker)
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+// GetPreferredSpread 取得顯示用的讓分盤：優先使用指定莊家，沒有時改用第一個有讓分盤的莊家
+func (og *OddsGame) GetPreferredSpread(bookID string) SpreadInfo {
+	if spread := og.GetSpread(bookID); spread.Found {
+		return spread
+	}
+
+	for _, market := range og.Markets {
+		if MarketKind(market.Name) == MarketSpread {
+			for _, bookmaker := range market.Books {
+				if spread := spreadFromBook(bookmaker); spread.HomeSpread != "" {
+					return spread
+				}
+			}
+		}
+	}
+
+	return SpreadInfo{Found: false}
+}
+
+// FilterBook 回傳只保留指定莊家（ID 或名稱，不分大小寫）盤口的副本，不修改原本的資料
+func (o *NBAOdds) FilterBook(book string) *NBAOdds {
+	filtered := &NBAOdds{Games: make([]OddsGame, len(o.Games))}
+	for i, game := range o.Games {
+		markets := make([]OddsMarket, len(game.Markets))
+		for j, market := range game.Markets {
+			var books []Bookmaker
 			for _, bookmaker := range market.Books {
-				if bookmaker.ID == "sr:book:818" {
-					result.Found = true
-					// 解析 home 和 away 的賠率
-					for _, outcome := range bookmaker.Outcomes {
-						if outcome.Type == "home" {
-							result.HomeSpread = outcome.Spread
-							result.HomeOpeningSpread = outcome.OpeningSpread
-						} else if outcome.Type == "away" {
-							result.AwaySpread = outcome.Spread
-							result.AwayOpeningSpread = outcome.OpeningSpread
-						}
-					}
-					return result
+				if strings.EqualFold(bookmaker.ID, book) || strings.EqualFold(bookmaker.Name, book) {
+					books = append(books, bookmaker)
 				}
 			}
+			market.Books = books
+			markets[j] = market
 		}
+		game.Markets = markets
+		filtered.Games[i] = game
 	}
+	return filtered
+}
 
+// spreadFromBook 解析單一莊家的 home 和 away 讓分
+func spreadFromBook(bookmaker Bookmaker) SpreadInfo {
+	result := SpreadInfo{
+		BookID:   bookmaker.ID,
+		BookName: bookmaker.Name,
+		Found:    true,
+	}
+	for _, outcome := range bookmaker.Outcomes {
+		if outcome.Type == "home" {
+			result.HomeSpread = outcome.Spread
+			result.HomeOpeningSpread = outcome.OpeningSpread
+		} else if outcome.Type == "away" {
+			result.AwaySpread = outcome.Spread
+			result.AwayOpeningSpread = outcome.OpeningSpread
+		}
+	}
 	return result
 }
@@ -0,0 +1,38 @@
+package models
+
+import "testing"
+
+func TestFilterBook(t *testing.T) {
+	odds := &NBAOdds{Games: []OddsGame{{
+		GameID: "0022500510",
+		Markets: []OddsMarket{{Name: "spread", Books: []Bookmaker{
+			{ID: "sr:book:818", Name: "Supermatch"},
+			{ID: "sr:book:1", Name: "Other"},
+		}}},
+	}}}
+
+	tests := []struct {
+		name string
+		book string
+		want []string
+	}{
+		{"莊家 ID", "sr:book:818", []string{"sr:book:818"}},
+		{"莊家名稱不分大小寫", "other", []string{"sr:book:1"}},
+		{"沒有該莊家", "unknown", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered := odds.FilterBook(tt.book)
+			var got []string
+			for _, b := range filtered.Games[0].Markets[0].Books {
+				got = append(got, b.ID)
+			}
+			if len(got) != len(tt.want) || (len(got) > 0 && got[0] != tt.want[0]) {
+				t.Errorf("FilterBook(%q) = %v，預期 %v", tt.book, got, tt.want)
+			}
+			if n := len(odds.Games[0].Markets[0].Books); n != 2 {
+				t.Errorf("原本的資料被修改（剩 %d 家莊家）", n)
+			}
+		})
+	}
+}
@@ -17,6 +17,8 @@ type Game struct {
 	Period         int    `json:"period"`
 	GameClock      string `json:"gameClock"`
 	GameTimeUTC    string `json:"gameTimeUTC"`
+	GameLabel      string `json:"gameLabel"`
+	GameSubtype    string `json:"gameSubtype"`
 	HomeTeam       Team   `json:"homeTeam"`
 	AwayTeam       Team   `json:"awayTeam"`
 }
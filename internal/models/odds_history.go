@@ -0,0 +1,35 @@
+package models
+
+// OddsHistory 單場比賽的盤口走勢（/api/games/{id}/odds/history）
+type OddsHistory struct {
+	GameID     string            `json:"gameId"`
+	Books      []OddsHistoryBook `json:"books"`
+	SteamMoves []SteamMove       `json:"steamMoves"` // 短時間內的大幅變盤
+}
+
+// OddsHistoryBook 單一莊家的盤口時間序列
+type OddsHistoryBook struct {
+	BookID   string             `json:"bookId"`
+	BookName string             `json:"bookName"`
+	Points   []OddsHistoryPoint `json:"points"`
+}
+
+// OddsHistoryPoint 盤口變動的時間點（只記錄有變動的時間點）
+type OddsHistoryPoint struct {
+	Time       string   `json:"time"`                 // RFC3339（UTC）
+	HomeSpread *float64 `json:"homeSpread,omitempty"` // 主隊讓分
+	AwaySpread *float64 `json:"awaySpread,omitempty"` // 客隊讓分
+	Total      *float64 `json:"total,omitempty"`      // 大小分
+	Steam      bool     `json:"steam"`                // 此時間點是否為急速變盤
+}
+
+// SteamMove 急速變盤（在時間窗內變動超過門檻）
+type SteamMove struct {
+	BookID string  `json:"bookId"`
+	Market string  `json:"market"` // "spread" 或 "total"
+	From   float64 `json:"from"`
+	To     float64 `json:"to"`
+	Delta  float64 `json:"delta"`
+	Start  string  `json:"start"` // RFC3339（UTC）
+	End    string  `json:"end"`   // RFC3339（UTC）
+}
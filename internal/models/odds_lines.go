@@ -0,0 +1,341 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 市場類型
+const (
+	MarketSpread    = "spread"
+	MarketMoneyline = "moneyline"
+	MarketTotal     = "total"
+
+	MarketFirstHalfTotal = "1h_total"
+)
+
+// MarketKind 將賠率 API 的 market 名稱歸類為讓分、獨贏、大小分或上半場大小分，無法辨識時回傳空字串
+func MarketKind(name string) string {
+	switch strings.ToLower(name) {
+	case "spread", "handicap", "point spread":
+		return MarketSpread
+	case "2way", "moneyline", "money line", "h2h":
+		return MarketMoneyline
+	case "total", "totals", "over/under", "overunder":
+		return MarketTotal
+	case "1h_total", "1st_half_total", "first half total", "1st half total", "total_1h":
+		return MarketFirstHalfTotal
+	}
+	return ""
+}
+
+// Price 賠率（同時提供歐洲盤小數與美式賠率）
+type Price struct {
+	Decimal  float64 `json:"decimal"`  // 例如 1.91
+	American int     `json:"american"` // 例如 -110
+}
+
+// ParsePrice 解析賠率字串：帶正負號的整數是美式賠率（-110、+150），其餘是小數賠率（1.91、150）
+func ParsePrice(s string) (Price, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Price{}, false
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return Price{}, false
+	}
+
+	if strings.HasPrefix(s, "+") || strings.HasPrefix(s, "-") {
+		// 美式賠率沒有小數部分且絕對值 >= 100（-1.5 之類的是讓分，不是賠率）
+		if v != math.Trunc(v) || math.Abs(v) < 100 {
+			return Price{}, false
+		}
+		return Price{Decimal: AmericanToDecimal(int(v)), American: int(v)}, true
+	}
+
+	if v <= 1 {
+		return Price{}, false
+	}
+	return Price{Decimal: v, American: DecimalToAmerican(v)}, true
+}
+
+// DecimalToAmerican 小數賠率轉美式賠率（1.91 -> -110，2.50 -> +150）
+func DecimalToAmerican(d float64) int {
+	if d <= 1 {
+		return 0
+	}
+	if d >= 2 {
+		return int(math.Round((d - 1) * 100))
+	}
+	return int(math.Round(-100 / (d - 1)))
+}
+
+// AmericanToDecimal 美式賠率轉小數賠率（-110 -> 1.909，+150 -> 2.50）
+func AmericanToDecimal(a int) float64 {
+	switch {
+	case a > 0:
+		return roundTo(1+float64(a)/100, 3)
+	case a < 0:
+		return roundTo(1+100/math.Abs(float64(a)), 3)
+	}
+	return 0
+}
+
+// ImpliedProbability 賠率隱含勝率
+func (p Price) ImpliedProbability() float64 {
+	if p.Decimal <= 0 {
+		return 0
+	}
+	return 1 / p.Decimal
+}
+
+// BookLine 單一莊家的所有盤口
+type BookLine struct {
+	BookID   string `json:"bookId"`
+	BookName string `json:"bookName"`
+
+	HomeSpread        *float64 `json:"homeSpread,omitempty"`
+	AwaySpread        *float64 `json:"awaySpread,omitempty"`
+	HomeSpreadOpening *float64 `json:"homeSpreadOpening,omitempty"`
+	HomeSpreadPrice   *Price   `json:"homeSpreadPrice,omitempty"`
+	AwaySpreadPrice   *Price   `json:"awaySpreadPrice,omitempty"`
+
+	HomeMoneyline *Price `json:"homeMoneyline,omitempty"`
+	AwayMoneyline *Price `json:"awayMoneyline,omitempty"`
+
+	Total        *float64 `json:"total,omitempty"`
+	TotalOpening *float64 `json:"totalOpening,omitempty"`
+	OverPrice    *Price   `json:"overPrice,omitempty"`
+	UnderPrice   *Price   `json:"underPrice,omitempty"`
+
+	FirstHalfTotal *float64 `json:"firstHalfTotal,omitempty"`
+}
+
+// BookLines 解析所有莊家的讓分、獨贏、大小分盤口
+func (og *OddsGame) BookLines() []BookLine {
+	byBook := make(map[string]*BookLine)
+	var order []string
+
+	for _, market := range og.Markets {
+		kind := MarketKind(market.Name)
+		if kind == "" {
+			continue
+		}
+
+		for _, book := range market.Books {
+			line, ok := byBook[book.ID]
+			if !ok {
+				line = &BookLine{BookID: book.ID, BookName: book.Name}
+				byBook[book.ID] = line
+				order = append(order, book.ID)
+			}
+
+			for _, outcome := range book.Outcomes {
+				price := parsePricePtr(outcome.Odds)
+				value := parseFloatPtr(outcome.Spread)
+				var opening *float64
+				if outcome.OpeningSpread != nil {
+					v := *outcome.OpeningSpread
+					opening = &v
+				}
+
+				switch kind {
+				case MarketSpread:
+					switch outcome.Type {
+					case "home":
+						line.HomeSpread, line.HomeSpreadPrice, line.HomeSpreadOpening = value, price, opening
+					case "away":
+						line.AwaySpread, line.AwaySpreadPrice = value, price
+					}
+				case MarketMoneyline:
+					switch outcome.Type {
+					case "home":
+						line.HomeMoneyline = price
+					case "away":
+						line.AwayMoneyline = price
+					}
+				case MarketTotal:
+					switch outcome.Type {
+					case "over":
+						line.Total, line.OverPrice = value, price
+						if opening != nil {
+							line.TotalOpening = opening
+						}
+					case "under":
+						line.UnderPrice = price
+						if line.Total == nil {
+							line.Total = value
+						}
+						if line.TotalOpening == nil {
+							line.TotalOpening = opening
+						}
+					}
+				case MarketFirstHalfTotal:
+					if line.FirstHalfTotal == nil {
+						line.FirstHalfTotal = value
+					}
+				}
+			}
+		}
+	}
+
+	lines := make([]BookLine, 0, len(order))
+	for _, id := range order {
+		lines = append(lines, *byBook[id])
+	}
+	return lines
+}
+
+// OddsComparison 多家莊家的盤口比較（/api/games 的 odds 欄位）
+type OddsComparison struct {
+	Books     []BookLine    `json:"books"`
+	Consensus ConsensusLine `json:"consensus"`
+	Best      BestLines     `json:"best"`
+}
+
+// ConsensusLine 各莊家盤口的中位數
+type ConsensusLine struct {
+	HomeSpread    *float64 `json:"homeSpread,omitempty"`
+	Total         *float64 `json:"total,omitempty"`
+	HomeMoneyline *Price   `json:"homeMoneyline,omitempty"`
+	AwayMoneyline *Price   `json:"awayMoneyline,omitempty"`
+	BookCount     int      `json:"bookCount"`
+}
+
+// BestLine 對下注方最有利的盤口
+type BestLine struct {
+	BookID   string   `json:"bookId"`
+	BookName string   `json:"bookName"`
+	Line     *float64 `json:"line,omitempty"` // 讓分或大小分（獨贏沒有）
+	Price    *Price   `json:"price,omitempty"`
+}
+
+// BestLines 各下注選項的最佳盤口
+type BestLines struct {
+	HomeSpread    *BestLine `json:"homeSpread,omitempty"`    // 主隊受讓最多（或讓分最少）
+	AwaySpread    *BestLine `json:"awaySpread,omitempty"`    // 客隊受讓最多（或讓分最少）
+	HomeMoneyline *BestLine `json:"homeMoneyline,omitempty"` // 主隊獨贏賠率最高
+	AwayMoneyline *BestLine `json:"awayMoneyline,omitempty"` // 客隊獨贏賠率最高
+	Over          *BestLine `json:"over,omitempty"`          // 大分盤口最低
+	Under         *BestLine `json:"under,omitempty"`         // 小分盤口最高
+}
+
+// CompareOdds 計算多家莊家的共識盤口與最佳盤口
+func (og *OddsGame) CompareOdds() OddsComparison {
+	lines := og.BookLines()
+	result := OddsComparison{Books: lines}
+
+	var spreads, totals, homeML, awayML []float64
+	for _, l := range lines {
+		if l.HomeSpread != nil {
+			spreads = append(spreads, *l.HomeSpread)
+		}
+		if l.Total != nil {
+			totals = append(totals, *l.Total)
+		}
+		if l.HomeMoneyline != nil {
+			homeML = append(homeML, l.HomeMoneyline.Decimal)
+		}
+		if l.AwayMoneyline != nil {
+			awayML = append(awayML, l.AwayMoneyline.Decimal)
+		}
+
+		best := &result.Best
+		// 讓分：數值越大對下注方越有利（-3 優於 -4，+5 優於 +4）
+		best.HomeSpread = pickBest(best.HomeSpread, l, l.HomeSpread, l.HomeSpreadPrice, true)
+		best.AwaySpread = pickBest(best.AwaySpread, l, l.AwaySpread, l.AwaySpreadPrice, true)
+		// 大分：盤口越低越有利；小分：盤口越高越有利
+		best.Over = pickBest(best.Over, l, l.Total, l.OverPrice, false)
+		best.Under = pickBest(best.Under, l, l.Total, l.UnderPrice, true)
+		best.HomeMoneyline = pickBestPrice(best.HomeMoneyline, l, l.HomeMoneyline)
+		best.AwayMoneyline = pickBestPrice(best.AwayMoneyline, l, l.AwayMoneyline)
+	}
+
+	result.Consensus = ConsensusLine{
+		HomeSpread: median(spreads),
+		Total:      median(totals),
+		BookCount:  len(lines),
+	}
+	if m := median(homeML); m != nil {
+		result.Consensus.HomeMoneyline = &Price{Decimal: roundTo(*m, 3), American: DecimalToAmerican(*m)}
+	}
+	if m := median(awayML); m != nil {
+		result.Consensus.AwayMoneyline = &Price{Decimal: roundTo(*m, 3), American: DecimalToAmerican(*m)}
+	}
+
+	return result
+}
+
+// pickBest 比較盤口數值（相同時比較賠率），higher 表示數值越大越好
+func pickBest(cur *BestLine, l BookLine, value *float64, price *Price, higher bool) *BestLine {
+	if value == nil {
+		return cur
+	}
+	candidate := &BestLine{BookID: l.BookID, BookName: l.BookName, Line: value, Price: price}
+	if cur == nil {
+		return candidate
+	}
+
+	if *value != *cur.Line {
+		if (*value > *cur.Line) == higher {
+			return candidate
+		}
+		return cur
+	}
+	if price != nil && (cur.Price == nil || price.Decimal > cur.Price.Decimal) {
+		return candidate
+	}
+	return cur
+}
+
+// pickBestPrice 比較獨贏賠率，小數賠率越高越好
+func pickBestPrice(cur *BestLine, l BookLine, price *Price) *BestLine {
+	if price == nil {
+		return cur
+	}
+	if cur == nil || price.Decimal > cur.Price.Decimal {
+		return &BestLine{BookID: l.BookID, BookName: l.BookName, Price: price}
+	}
+	return cur
+}
+
+// median 中位數，沒有資料時回傳 nil
+func median(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	mid := len(sorted) / 2
+	m := sorted[mid]
+	if len(sorted)%2 == 0 {
+		m = (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return &m
+}
+
+func parseFloatPtr(s string) *float64 {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return nil
+	}
+	return &v
+}
+
+func parsePricePtr(s string) *Price {
+	p, ok := ParsePrice(s)
+	if !ok {
+		return nil
+	}
+	return &p
+}
+
+func roundTo(v float64, places int) float64 {
+	pow := math.Pow(10, float64(places))
+	return math.Round(v*pow) / pow
+}
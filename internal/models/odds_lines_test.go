@@ -0,0 +1,74 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBookLinesOpeningSpread(t *testing.T) {
+	tests := []struct {
+		name        string
+		outcomes    string
+		wantOpening *float64
+	}{
+		{"平手盤開盤", `[{"type":"home","odds":"1.91","spread":"-1.5","opening_spread":0},{"type":"away","odds":"1.91","spread":"1.5","opening_spread":0}]`, floatPtr(0)},
+		{"讓分開盤", `[{"type":"home","odds":"1.91","spread":"-3.5","opening_spread":-2.5}]`, floatPtr(-2.5)},
+		{"沒有開盤資料", `[{"type":"home","odds":"1.91","spread":"-3.5"}]`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var outcomes []Outcome
+			if err := json.Unmarshal([]byte(tt.outcomes), &outcomes); err != nil {
+				t.Fatalf("解析測試資料失敗: %v", err)
+			}
+			game := OddsGame{Markets: []OddsMarket{{Name: "spread", Books: []Bookmaker{{ID: "sr:book:1", Name: "Book", Outcomes: outcomes}}}}}
+
+			lines := game.BookLines()
+			if len(lines) != 1 {
+				t.Fatalf("莊家數 = %d，預期 1", len(lines))
+			}
+			got := lines[0].HomeSpreadOpening
+			switch {
+			case tt.wantOpening == nil && got != nil:
+				t.Errorf("開盤 = %v，預期沒有資料", *got)
+			case tt.wantOpening != nil && (got == nil || *got != *tt.wantOpening):
+				t.Errorf("開盤 = %v，預期 %v", got, *tt.wantOpening)
+			}
+		})
+	}
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func TestParsePrice(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want Price
+		ok   bool
+	}{
+		{"小數賠率", "1.91", Price{Decimal: 1.91, American: -110}, true},
+		{"小數賠率前後空白", " 2.50 ", Price{Decimal: 2.5, American: 150}, true},
+		{"大於 100 的小數賠率", "101.0", Price{Decimal: 101, American: 10000}, true},
+		{"沒有小數點的小數賠率", "150", Price{Decimal: 150, American: 14900}, true},
+		{"美式賠率負數", "-110", Price{Decimal: 1.909, American: -110}, true},
+		{"美式賠率正數", "+150", Price{Decimal: 2.5, American: 150}, true},
+		{"美式賠率帶 .0", "-110.0", Price{Decimal: 1.909, American: -110}, true},
+		{"美式賠率絕對值小於 100", "-50", Price{}, false},
+		{"帶正負號的讓分", "-1.5", Price{}, false},
+		{"美式賠率有小數部分", "+150.5", Price{}, false},
+		{"小數賠率不大於 1", "1.0", Price{}, false},
+		{"空字串", "", Price{}, false},
+		{"非數字", "EVEN", Price{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParsePrice(tt.in)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("ParsePrice(%q) = %+v, %v，預期 %+v, %v", tt.in, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package models
+
+// BetResult 注單結算結果
+// 亞洲盤的四分之一盤口（如 -4.25）會拆成兩半注，因此有贏半、輸半
+type BetResult string
+
+const (
+	BetWin      BetResult = "W"  // 贏
+	BetHalfWin  BetResult = "HW" // 贏半
+	BetPush     BetResult = "P"  // 走盤（退回本金）
+	BetHalfLoss BetResult = "HL" // 輸半
+	BetLoss     BetResult = "L"  // 輸
+)
+
+// 比賽時段
+const (
+	SegmentFullGame  = "full"
+	SegmentFirstHalf = "1h"
+	SegmentQ1        = "q1"
+	SegmentQ2        = "q2"
+	SegmentQ3        = "q3"
+	SegmentQ4        = "q4"
+	SegmentOvertime  = "ot"
+)
+
+// BetSettlement 比賽各時段的結算結果（/api/games 的 settlement 欄位）
+type BetSettlement struct {
+	Segments []SegmentSettlement `json:"segments"`
+}
+
+// Segment 取得指定時段的結算結果
+func (s *BetSettlement) Segment(segment string) (SegmentSettlement, bool) {
+	for _, seg := range s.Segments {
+		if seg.Segment == segment {
+			return seg, true
+		}
+	}
+	return SegmentSettlement{}, false
+}
+
+// SegmentSettlement 單一時段（全場、上半場、各節、延長賽）的結算
+type SegmentSettlement struct {
+	Segment   string              `json:"segment"` // full, 1h, q1-q4, ot
+	Label     string              `json:"label"`   // 顯示名稱（全場、上半…）
+	HomeScore int                 `json:"homeScore"`
+	AwayScore int                 `json:"awayScore"`
+	Moneyline MoneylineSettlement `json:"moneyline"`
+	Spread    *SpreadSettlement   `json:"spread,omitempty"` // 有讓分盤口才有
+	Total     *TotalSettlement    `json:"total,omitempty"`  // 有大小分盤口才有
+}
+
+// MoneylineSettlement 獨贏結算（該時段平手時為走盤）
+type MoneylineSettlement struct {
+	Home BetResult `json:"home"`
+	Away BetResult `json:"away"`
+}
+
+// SpreadSettlement 讓分結算
+type SpreadSettlement struct {
+	HomeLine float64   `json:"homeLine"` // 主隊讓分（負數為讓分）
+	Derived  bool      `json:"derived"`  // 盤口是否由全場盤口估算
+	Home     BetResult `json:"home"`
+	Away     BetResult `json:"away"`
+}
+
+// TotalSettlement 大小分結算
+type TotalSettlement struct {
+	Line    float64   `json:"line"`
+	Derived bool      `json:"derived"` // 盤口是否由全場盤口估算
+	Points  int       `json:"points"`  // 兩隊總得分
+	Over    BetResult `json:"over"`
+	Under   BetResult `json:"under"`
+}
@@ -1,29 +1,73 @@
 package models
 
+import (
+	"strings"
+	"time"
+)
+
 // TeamHistory 球隊歷史戰績
 type TeamHistory struct {
-	TeamID       int            `json:"teamId"`
-	TeamName     string         `json:"teamName"`
-	RecentGames  []GameResult   `json:"recentGames"`
-	WinCount     int            `json:"winCount"`
-	LossCount    int            `json:"lossCount"`
+	TeamID         int          `json:"teamId"`
+	TeamName       string       `json:"teamName"`
+	RecentGames    []GameResult `json:"recentGames"`
+	WinCount       int          `json:"winCount"`       // Deprecated: 同 ATSWins，保留舊的 JSON 欄位
+	LossCount      int          `json:"lossCount"`      // Deprecated: 同 ATSLosses，保留舊的 JSON 欄位
+	ATSWins        int          `json:"atsWins"`        // 有盤口資料的比賽中贏盤（含贏半）場數
+	ATSLosses      int          `json:"atsLosses"`      // 輸盤（含輸半）場數
+	ATSPushes      int          `json:"atsPushes"`      // 走盤場數
+	OverCount      int          `json:"overCount"`      // 大分場數（有大小分盤口的比賽）
+	UnderCount     int          `json:"underCount"`     // 小分場數
+	TotalPushCount int          `json:"totalPushCount"` // 大小分走盤場數
 }
 
 // GameResult 單場比賽結果
 type GameResult struct {
-	GameID      string  `json:"gameId"`      // 比賽 ID（用於跳轉）
-	GameDate    string  `json:"gameDate"`    // NBA 原始比賽日期（美國時間，用於跳轉查詢）
-	Date        string  `json:"date"`        // 比賽日期（台北時間，用於顯示）
-	Time        string  `json:"time"`        // 比賽時間
-	Opponent    string  `json:"opponent"`    // 對手
-	VsIndicator string  `json:"vsIndicator"` // "vs" 或 "@"
-	IsHome      bool    `json:"isHome"`      // 是否主場
-	Score       string  `json:"score"`       // 比分 (如: "111-103")
-	GameResult  string  `json:"gameResult"`  // W 或 L (實際勝負)
-	SpreadResult string `json:"spreadResult"` // W 或 L (過盤結果)
-	Result      string  `json:"result"`      // W 或 L (過盤結果，保留向後相容)
-	Spread      string  `json:"spread"`      // 盤口 (如: "-5.5" 或 "無盤口")
-	HasSpread   bool    `json:"hasSpread"`   // 是否有盤口資料
+	GameID       string    `json:"gameId"`       // 比賽 ID（用於跳轉）
+	GameDate     string    `json:"gameDate"`     // NBA 原始比賽日期（美國時間，用於跳轉查詢）
+	GameTimeUTC  string    `json:"gameTimeUTC"`  // 開賽時間（UTC）
+	Date         string    `json:"date"`         // 比賽日期（顯示時區，用於顯示）
+	Time         string    `json:"time"`         // 比賽時間（顯示時區）
+	Opponent     string    `json:"opponent"`     // 對手
+	VsIndicator  string    `json:"vsIndicator"`  // "vs" 或 "@"
+	IsHome       bool      `json:"isHome"`       // 是否主場
+	Score        string    `json:"score"`        // 比分 (如: "111-103")
+	GameResult   string    `json:"gameResult"`   // W 或 L (實際勝負)
+	SpreadResult BetResult `json:"spreadResult"` // W/HW/P/HL/L (過盤結果)，無盤口時為空字串
+	Result       BetResult `json:"result"`       // 同 SpreadResult (保留向後相容)
+	Spread       string    `json:"spread"`       // 盤口 (如: "-5.5" 或 "無盤口")
+	HasSpread    bool      `json:"hasSpread"`    // 是否有盤口資料
+	Points       int       `json:"points"`       // 兩隊總得分
+	Total        string    `json:"total"`        // 大小分盤口（如: "228.5"），無資料時為空字串
+	TotalResult  string    `json:"totalResult"`  // O/U/P（大小分結果），無盤口時為空字串
+	HasTotal     bool      `json:"hasTotal"`     // 是否有大小分盤口
+}
+
+// Localize 依 GameTimeUTC 填入指定時區的顯示日期與時間（"2025/10/02"、"08:00"）
+func (r *GameResult) Localize(loc *time.Location) {
+	t, err := time.Parse(time.RFC3339, r.GameTimeUTC)
+	if err != nil {
+		// 沒有開賽時間時只顯示 NBA 比賽日
+		r.Date = strings.ReplaceAll(r.GameDate, "-", "/")
+		r.Time = ""
+		return
+	}
+	local := t.In(loc)
+	r.Date = local.Format("2006/01/02")
+	r.Time = local.Format("15:04")
+}
+
+// InZone 回傳以指定時區顯示日期與時間的副本（快取中的資料不會被修改）
+func (h *TeamHistory) InZone(loc *time.Location) *TeamHistory {
+	if h == nil {
+		return nil
+	}
+	copied := *h
+	copied.RecentGames = make([]GameResult, len(h.RecentGames))
+	for i, game := range h.RecentGames {
+		game.Localize(loc)
+		copied.RecentGames[i] = game
+	}
+	return &copied
 }
 
 // FullSchedule 完整賽季賽程
@@ -35,17 +79,20 @@ type FullSchedule struct {
 
 // GameDate 某日的比賽
 type GameDate struct {
-	GameDate string           `json:"gameDate"`
-	Games    []ScheduledGame  `json:"games"`
+	GameDate string          `json:"gameDate"`
+	Games    []ScheduledGame `json:"games"`
 }
 
 // ScheduledGame 賽程中的比賽
 type ScheduledGame struct {
-	GameID          string `json:"gameId"`
-	GameCode        string `json:"gameCode"`
-	GameStatus      int    `json:"gameStatus"`   // 1=未開始 2=進行中 3=已結束
-	GameStatusText  string `json:"gameStatusText"`
-	GameDateTimeEst string `json:"gameDateTimeEst"` // 東岸時間，格式: 2025-10-02T12:00:00Z
+	GameID          string        `json:"gameId"`
+	GameCode        string        `json:"gameCode"`
+	GameStatus      int           `json:"gameStatus"` // 1=未開始 2=進行中 3=已結束
+	GameStatusText  string        `json:"gameStatusText"`
+	GameDateTimeEst string        `json:"gameDateTimeEst"` // 美東當地時間（帶 Z 但不是 UTC），格式: 2025-10-02T12:00:00Z
+	GameDateTimeUTC string        `json:"gameDateTimeUTC"` // UTC 時間，格式: 2025-10-02T16:00:00Z
+	GameLabel       string        `json:"gameLabel"`       // 賽事標籤（如 "Emirates NBA Cup"、"Preseason"）
+	GameSubtype     string        `json:"gameSubtype"`     // 賽事子類型（NBA 盃為 "in-season"、"in-season-knockout"）
 	HomeTeam        ScheduledTeam `json:"homeTeam"`
 	AwayTeam        ScheduledTeam `json:"awayTeam"`
 }
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"fmt"
+	"math"
+)
+
+// 大小分結果
+const (
+	TotalOver  = "O" // 大
+	TotalUnder = "U" // 小
+	TotalPush  = "P" // 走盤
+)
+
+// TotalsDisplay 大小分盤口顯示
+type TotalsDisplay struct {
+	Opening     string `json:"opening"`     // 開盤大小分
+	Current     string `json:"current"`     // 即時大小分（已開賽時為開賽前最後盤口）
+	Book        string `json:"book"`        // 盤口來源莊家
+	HomeImplied string `json:"homeImplied"` // 主隊預期得分（由大小分與讓分推算）
+	AwayImplied string `json:"awayImplied"` // 客隊預期得分
+	HasData     bool   `json:"hasData"`     // 是否有大小分資料
+
+	// 上半場盤口：莊家有上半場大小分時使用，否則以全場的一半估算
+	FirstHalf        string `json:"firstHalf"`
+	FirstHalfDerived bool   `json:"firstHalfDerived"` // 上半場盤口是否為估算值
+
+	// 比賽進行中或已結束才有
+	Points          int    `json:"points"`          // 全場總得分
+	FirstHalfPoints int    `json:"firstHalfPoints"` // 上半場總得分
+	Result          string `json:"result"`          // 全場大小分結果 O/U/P（比賽結束才有）
+	FirstHalfResult string `json:"firstHalfResult"` // 上半場大小分結果 O/U/P（上半場結束才有）
+}
+
+// TotalLine 單一莊家的大小分盤口
+type TotalLine struct {
+	BookID         string
+	BookName       string
+	Total          float64
+	Opening        float64 // 沒有開盤資料時等於 Total
+	HomeSpread     *float64
+	FirstHalfTotal *float64
+	Found          bool
+}
+
+// GetPreferredTotal 取得指定莊家的大小分盤口，該莊家沒有時改用第一個有大小分的莊家
+func (og *OddsGame) GetPreferredTotal(bookID string) TotalLine {
+	lines := og.BookLines()
+
+	pick := -1
+	for i, l := range lines {
+		if l.Total == nil {
+			continue
+		}
+		if l.BookID == bookID {
+			pick = i
+			break
+		}
+		if pick < 0 {
+			pick = i
+		}
+	}
+	if pick < 0 {
+		return TotalLine{}
+	}
+
+	l := lines[pick]
+	result := TotalLine{
+		BookID:         l.BookID,
+		BookName:       l.BookName,
+		Total:          *l.Total,
+		Opening:        *l.Total,
+		HomeSpread:     l.HomeSpread,
+		FirstHalfTotal: l.FirstHalfTotal,
+		Found:          true,
+	}
+	if l.TotalOpening != nil {
+		result.Opening = *l.TotalOpening
+	}
+	return result
+}
+
+// ImpliedTeamTotals 由大小分與主隊讓分推算兩隊預期得分
+// 例如大小分 220、主隊 -6：主隊 113、客隊 107
+func ImpliedTeamTotals(total, homeSpread float64) (home, away float64) {
+	return (total - homeSpread) / 2, (total + homeSpread) / 2
+}
+
+// OverUnderResult 判斷大小分結果
+func OverUnderResult(points int, line float64) string {
+	switch diff := float64(points) - line; {
+	case diff > 0:
+		return TotalOver
+	case diff < 0:
+		return TotalUnder
+	}
+	return TotalPush
+}
+
+// HalfTotalLine 以全場大小分估算上半場盤口（取到最接近的 0.5）
+func HalfTotalLine(total float64) float64 {
+	return math.Round(total) / 2
+}
+
+// FormatLine 格式化盤口數值（一位小數，四分之一盤口如 -2.25 保留兩位）
+func FormatLine(v float64) string {
+	if v*2 != math.Trunc(v*2) {
+		return fmt.Sprintf("%.2f", v)
+	}
+	return fmt.Sprintf("%.1f", v)
+}
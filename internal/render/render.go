@@ -0,0 +1,160 @@
+// Package render 將比賽日資料（models.APIResponse）輸出為不同格式
+//
+// CLI 的 --output 選擇輸出格式：text 為原本的中文說明文字，json 與 /api/games 回應相同，
+// csv 每場比賽一列，markdown 與 table 為表格（可貼到聊天群組或在終端機對齊顯示）。
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"nba-scanner/internal/gametime"
+	"nba-scanner/internal/models"
+	"strings"
+	"time"
+)
+
+// Format 輸出格式
+type Format string
+
+// 支援的輸出格式
+const (
+	FormatText     Format = "text"
+	FormatJSON     Format = "json"
+	FormatCSV      Format = "csv"
+	FormatMarkdown Format = "markdown"
+	FormatTable    Format = "table"
+)
+
+// Formats 所有輸出格式（--output 的說明與檢查）
+var Formats = []Format{FormatText, FormatJSON, FormatCSV, FormatMarkdown, FormatTable}
+
+// ParseFormat 解析 --output（不分大小寫，md 為 markdown 的別名）
+func ParseFormat(s string) (Format, error) {
+	f := Format(strings.ToLower(strings.TrimSpace(s)))
+	if f == "md" {
+		return FormatMarkdown, nil
+	}
+	for _, known := range Formats {
+		if f == known {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("不支援的輸出格式 %q（可用 %s）", s, formatList())
+}
+
+// formatList 以逗號列出所有輸出格式
+func formatList() string {
+	names := make([]string, len(Formats))
+	for i, f := range Formats {
+		names[i] = string(f)
+	}
+	return strings.Join(names, "、")
+}
+
+// SlateRenderer 輸出一個比賽日的比賽
+type SlateRenderer interface {
+	RenderSlate(w io.Writer, slate *models.APIResponse) error
+}
+
+// NewSlateRenderer 建立指定格式的比賽日輸出（loc 為開賽時間的顯示時區）
+func NewSlateRenderer(f Format, loc *time.Location) (SlateRenderer, error) {
+	switch f {
+	case FormatText, "":
+		return Text{Location: loc}, nil
+	case FormatJSON:
+		return JSON{}, nil
+	case FormatCSV:
+		return CSV{Location: loc}, nil
+	case FormatMarkdown:
+		return Markdown{Location: loc}, nil
+	case FormatTable:
+		return Table{Location: loc}, nil
+	}
+	return nil, fmt.Errorf("不支援的輸出格式 %q", f)
+}
+
+// JSON 與 /api/games 相同的 JSON 輸出
+type JSON struct{}
+
+// RenderSlate 輸出比賽日
+func (JSON) RenderSlate(w io.Writer, slate *models.APIResponse) error {
+	return WriteJSON(w, slate)
+}
+
+// WriteJSON 輸出任意資料的 JSON（與 server 回應一樣不縮排，方便以 jq 等工具處理）
+func WriteJSON(w io.Writer, v any) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+// 比賽狀態名稱（與 games --status 相同）
+var statusNames = map[int]string{
+	1: "upcoming",
+	2: "live",
+	3: "final",
+}
+
+// startTime 開賽時間（顯示時區的 15:04，無法解析時為空字串）
+func startTime(info models.GameInfo, loc *time.Location) string {
+	t, err := gametime.Local(info.GameTimeUTC, loc)
+	if err != nil {
+		return ""
+	}
+	return t
+}
+
+// scoreText 比分（客-主，未開打時為空字串）
+func scoreText(info models.GameInfo) string {
+	if info.GameStatus < 2 {
+		return ""
+	}
+	return fmt.Sprintf("%d-%d", info.AwayScore, info.HomeScore)
+}
+
+// statusText 表格中的比賽狀態（進行中顯示節數與時間）
+func statusText(info models.GameInfo) string {
+	switch info.GameStatus {
+	case 2:
+		return info.GameTime
+	case 3:
+		return "已結束"
+	}
+	return "未開打"
+}
+
+// spreadText 表格中的讓分（主隊讓分，開盤 → 目前）
+func spreadText(spread models.SpreadDisplay) string {
+	if !spread.HasData {
+		return "-"
+	}
+	if spread.Opening == "" || spread.Opening == spread.Current {
+		return "主" + spread.Current
+	}
+	return fmt.Sprintf("主%s → %s", spread.Opening, spread.Current)
+}
+
+// totalText 表格中的大小分（開盤 → 目前，已結束顯示大小分結果）
+func totalText(totals models.TotalsDisplay) string {
+	if !totals.HasData {
+		return "-"
+	}
+	text := totals.Current
+	if totals.Opening != totals.Current {
+		text = totals.Opening + " → " + totals.Current
+	}
+	if totals.Result != "" {
+		text += " " + totals.Result
+	}
+	return text
+}
+
+// settlementText 表格中的全場讓分結果（主隊）
+func settlementText(info models.GameInfo) string {
+	if info.Settlement == nil || info.GameStatus != 3 {
+		return ""
+	}
+	if seg, ok := info.Settlement.Segment(models.SegmentFullGame); ok && seg.Spread != nil {
+		return "主" + string(seg.Spread.Home)
+	}
+	return ""
+}
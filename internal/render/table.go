@@ -0,0 +1,190 @@
+package render
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"nba-scanner/internal/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSV 每場比賽一列（欄位名稱為英文，方便匯入試算表或以程式處理）
+type CSV struct {
+	Location *time.Location
+}
+
+// csvHeader CSV 欄位
+var csvHeader = []string{
+	"date", "game_id", "game_type", "status", "start_time",
+	"away", "home", "away_name", "home_name", "away_score", "home_score",
+	"spread_book", "spread_opening", "spread_current",
+	"total_book", "total_opening", "total_current", "away_implied", "home_implied",
+	"total_result", "home_spread_result",
+}
+
+// RenderSlate 輸出比賽日
+func (c CSV) RenderSlate(w io.Writer, slate *models.APIResponse) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, info := range slate.Games {
+		var awayScore, homeScore string
+		if info.GameStatus >= 2 {
+			awayScore = strconv.Itoa(info.AwayScore)
+			homeScore = strconv.Itoa(info.HomeScore)
+		}
+		var spreadResult string
+		if info.Settlement != nil && info.GameStatus == 3 {
+			if seg, ok := info.Settlement.Segment(models.SegmentFullGame); ok && seg.Spread != nil {
+				spreadResult = string(seg.Spread.Home)
+			}
+		}
+
+		row := []string{
+			slate.Date, info.GameID, info.GameType, statusNames[info.GameStatus], startTime(info, c.Location),
+			info.AwayTeam.Tricode, info.HomeTeam.Tricode, info.AwayTeam.NameCN, info.HomeTeam.NameCN, awayScore, homeScore,
+			info.Spread.Book, info.Spread.Opening, info.Spread.Current,
+			info.Totals.Book, info.Totals.Opening, info.Totals.Current, info.Totals.AwayImplied, info.Totals.HomeImplied,
+			info.Totals.Result, spreadResult,
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// tableHeader markdown 與終端機表格的欄位
+var tableHeader = []string{"#", "時間", "客隊", "主隊", "比分", "狀態", "讓分", "大小分", "過盤"}
+
+// tableRows 表格的每一列（每場比賽一列）
+func tableRows(slate *models.APIResponse, loc *time.Location) [][]string {
+	rows := make([][]string, 0, len(slate.Games))
+	for i, info := range slate.Games {
+		status := statusText(info)
+		if label := gameTypeLabels[info.GameType]; label != "" {
+			status = label + " " + status
+		}
+		rows = append(rows, []string{
+			strconv.Itoa(i + 1),
+			startTime(info, loc),
+			info.AwayTeam.NameCN,
+			info.HomeTeam.NameCN,
+			scoreText(info),
+			status,
+			spreadText(info.Spread),
+			totalText(info.Totals),
+			settlementText(info),
+		})
+	}
+	return rows
+}
+
+// Markdown markdown 表格（可直接貼到聊天群組）
+type Markdown struct {
+	Location *time.Location
+}
+
+// RenderSlate 輸出比賽日
+func (m Markdown) RenderSlate(w io.Writer, slate *models.APIResponse) error {
+	fmt.Fprintf(w, "### 比賽日 %s（%d 場）\n\n", slate.Date, len(slate.Games))
+
+	writeMarkdownRow(w, tableHeader)
+	separator := make([]string, len(tableHeader))
+	for i := range separator {
+		separator[i] = "---"
+	}
+	writeMarkdownRow(w, separator)
+
+	for _, row := range tableRows(slate, m.Location) {
+		writeMarkdownRow(w, row)
+	}
+	return nil
+}
+
+// writeMarkdownRow 輸出 markdown 表格的一列（儲存格中的 | 需要跳脫）
+func writeMarkdownRow(w io.Writer, cells []string) {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		escaped[i] = strings.ReplaceAll(cell, "|", `\|`)
+	}
+	fmt.Fprintf(w, "| %s |\n", strings.Join(escaped, " | "))
+}
+
+// Table 終端機對齊的表格
+type Table struct {
+	Location *time.Location
+}
+
+// RenderSlate 輸出比賽日
+func (t Table) RenderSlate(w io.Writer, slate *models.APIResponse) error {
+	fmt.Fprintf(w, "比賽日 %s 有 %d 場比賽\n\n", slate.Date, len(slate.Games))
+
+	rows := append([][]string{tableHeader}, tableRows(slate, t.Location)...)
+
+	// 各欄寬度（中文字佔兩格）
+	widths := make([]int, len(tableHeader))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], displayWidth(cell))
+		}
+	}
+
+	for r, row := range rows {
+		writeTableRow(w, row, widths)
+		if r == 0 {
+			dashes := make([]string, len(widths))
+			for i, width := range widths {
+				dashes[i] = strings.Repeat("-", width)
+			}
+			writeTableRow(w, dashes, widths)
+		}
+	}
+	return nil
+}
+
+// writeTableRow 輸出對齊的一列（欄與欄之間空兩格，最後一欄不補空白）
+func writeTableRow(w io.Writer, cells []string, widths []int) {
+	var b strings.Builder
+	for i, cell := range cells {
+		b.WriteString(cell)
+		if i < len(cells)-1 {
+			b.WriteString(strings.Repeat(" ", widths[i]-displayWidth(cell)+2))
+		}
+	}
+	fmt.Fprintln(w, strings.TrimRight(b.String(), " "))
+}
+
+// displayWidth 字串在終端機上的顯示寬度（中日韓文字與全形符號佔兩格）
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		if isWide(r) {
+			width += 2
+		} else {
+			width++
+		}
+	}
+	return width
+}
+
+// isWide 是否為全形字元（East Asian Wide / Fullwidth 的常用範圍）
+func isWide(r rune) bool {
+	switch {
+	case r >= 0x1100 && r <= 0x115F, // 韓文字母
+		r >= 0x2E80 && r <= 0xA4CF, // 中日韓部首、標點、漢字
+		r >= 0xAC00 && r <= 0xD7A3, // 韓文音節
+		r >= 0xF900 && r <= 0xFAFF, // 相容漢字
+		r >= 0xFE30 && r <= 0xFE4F, // 相容標點
+		r >= 0xFF00 && r <= 0xFF60, // 全形符號
+		r >= 0xFFE0 && r <= 0xFFE6:
+		return true
+	}
+	return false
+}
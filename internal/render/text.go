@@ -0,0 +1,178 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"nba-scanner/internal/models"
+	"strconv"
+	"time"
+)
+
+// Text 中文說明文字（CLI 預設的輸出）
+type Text struct {
+	Location *time.Location
+}
+
+// RenderSlate 輸出比賽日
+func (t Text) RenderSlate(w io.Writer, slate *models.APIResponse) error {
+	fmt.Fprintf(w, "比賽日 %s 有 %d 場比賽\n\n", slate.Date, len(slate.Games))
+
+	for i, info := range slate.Games {
+		t.writeGame(w, i+1, info)
+	}
+	return nil
+}
+
+// gameTypeLabels 例行賽以外的比賽類型名稱
+var gameTypeLabels = map[string]string{
+	models.GameTypePreseason: "季前賽",
+	models.GameTypeCup:       "NBA 盃",
+	models.GameTypeAllStar:   "明星賽",
+	models.GameTypePlayIn:    "附加賽",
+	models.GameTypePlayoffs:  "季後賽",
+}
+
+// writeGame 輸出單場比賽資訊（資料與 /api/games 回應的同一場比賽相同）
+func (t Text) writeGame(w io.Writer, index int, info models.GameInfo) {
+	homeTeamCN := info.HomeTeam.NameCN
+	awayTeamCN := info.AwayTeam.NameCN
+
+	// 顯示比賽狀態和比分（例行賽以外標示比賽類型）
+	status := gameStatusText(info)
+	if label := gameTypeLabels[info.GameType]; label != "" {
+		status = "[" + label + "] " + status
+	}
+	fmt.Fprintf(w, "%d. %s%s  %s  %s%s(主)  %s\n",
+		index, awayTeamCN, teamRecord(info.AwayTeam), startTime(info, t.Location), homeTeamCN, teamRecord(info.HomeTeam), status)
+
+	// 如果比賽進行中或已結束，顯示比分
+	if info.GameStatus >= 2 { // 2=進行中, 3=結束
+		fmt.Fprintf(w, "   比分：%s %d - %d %s", awayTeamCN, info.AwayScore, info.HomeScore, homeTeamCN)
+		if info.GameStatus == 2 { // 進行中
+			fmt.Fprintf(w, "  [%s]", info.GameTime)
+		}
+		fmt.Fprintln(w)
+	}
+
+	// 顯示讓分盤
+	if spread := info.Spread; spread.HasData && spread.Opening != "" {
+		fmt.Fprintf(w, "   讓分盤（%s）：開盤 主隊%s/客隊%s → 目前 主隊%s/客隊%s\n",
+			spread.Book,
+			spread.Opening, negateLine(spread.Opening),
+			spread.Current, negateLine(spread.Current))
+	} else if spread.HasData {
+		fmt.Fprintf(w, "   讓分盤（%s）：目前 主隊%s/客隊%s\n",
+			spread.Book, spread.Current, negateLine(spread.Current))
+	} else {
+		fmt.Fprintln(w, "   讓分盤：無資料")
+	}
+
+	// 大小分與預期得分
+	if totals := info.Totals; totals.HasData {
+		fmt.Fprintf(w, "   大小分（%s）：開盤 %s → 目前 %s", totals.Book, totals.Opening, totals.Current)
+		if totals.HomeImplied != "" {
+			fmt.Fprintf(w, "  預期得分 客隊%s/主隊%s", totals.AwayImplied, totals.HomeImplied)
+		}
+		fmt.Fprintln(w)
+	}
+
+	// 多家莊家比較
+	if info.Odds != nil {
+		WriteOddsComparison(w, *info.Odds)
+	}
+
+	// 結算結果（已結束的比賽，以及進行中比賽已打完的節）
+	if info.Settlement != nil {
+		writeSettlement(w, *info.Settlement)
+	}
+
+	// 顯示傷兵
+	fmt.Fprintln(w, "\n  ---------------------------------")
+	WriteInjuries(w, awayTeamCN, info.AwayInjuries)
+	fmt.Fprintln(w, "  ---------------------------------")
+	WriteInjuries(w, homeTeamCN, info.HomeInjuries)
+	fmt.Fprintln(w)
+}
+
+// teamRecord 球隊戰績文字（還沒有戰績時不顯示）
+func teamRecord(team models.TeamInfo) string {
+	if team.Wins > 0 || team.Losses > 0 {
+		return fmt.Sprintf(" (%d-%d)", team.Wins, team.Losses)
+	}
+	return ""
+}
+
+// negateLine 由主隊讓分換算客隊讓分（無法解析時原樣回傳）
+func negateLine(line string) string {
+	v, err := strconv.ParseFloat(line, 64)
+	if err != nil {
+		return line
+	}
+	return models.FormatLine(-v + 0) // +0 避免出現 -0.0
+}
+
+// WriteOddsComparison 輸出共識盤口與最佳盤口
+func WriteOddsComparison(w io.Writer, cmp models.OddsComparison) {
+	if len(cmp.Books) < 2 {
+		return
+	}
+
+	if cmp.Consensus.HomeSpread != nil {
+		fmt.Fprintf(w, "   共識讓分（%d 家）：主隊%.1f", cmp.Consensus.BookCount, *cmp.Consensus.HomeSpread)
+		if cmp.Consensus.Total != nil {
+			fmt.Fprintf(w, "  大小分 %.1f", *cmp.Consensus.Total)
+		}
+		fmt.Fprintln(w)
+	}
+	if b := cmp.Best.HomeSpread; b != nil {
+		fmt.Fprintf(w, "   最佳主隊讓分：%.1f @ %s\n", *b.Line, b.BookName)
+	}
+	if b := cmp.Best.AwaySpread; b != nil {
+		fmt.Fprintf(w, "   最佳客隊讓分：%.1f @ %s\n", *b.Line, b.BookName)
+	}
+}
+
+// writeSettlement 輸出全場、上半場與各節的獨贏/讓分/大小分結算
+func writeSettlement(w io.Writer, result models.BetSettlement) {
+	for _, seg := range result.Segments {
+		fmt.Fprintf(w, "   %s 客%d-主%d：獨贏 主%s", seg.Label, seg.AwayScore, seg.HomeScore, seg.Moneyline.Home)
+		if seg.Spread != nil {
+			fmt.Fprintf(w, "  讓分 主%s %s", models.FormatLine(seg.Spread.HomeLine), seg.Spread.Home)
+		}
+		if seg.Total != nil {
+			fmt.Fprintf(w, "  大小 %s 大%s", models.FormatLine(seg.Total.Line), seg.Total.Over)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// gameStatusText 取得比賽狀態文字
+func gameStatusText(info models.GameInfo) string {
+	switch info.GameStatus {
+	case 1: // 未開始
+		return ""
+	case 2: // 進行中
+		return "[進行中]" // 節數與時間顯示在比分後
+	case 3: // 已結束
+		return "[已結束]"
+	default:
+		return ""
+	}
+}
+
+// WriteInjuries 輸出球隊傷兵名單
+func WriteInjuries(w io.Writer, teamCN string, injuries []models.Injury) {
+	fmt.Fprintf(w, "  %s 傷兵名單\n", teamCN)
+
+	if len(injuries) == 0 {
+		fmt.Fprintln(w, "  沒有傷兵")
+		return
+	}
+	for _, injury := range injuries {
+		if injury.ReturnDate != "" {
+			fmt.Fprintf(w, "  %s（預計復出 %s）\n", injury.String(), injury.ReturnDate)
+		} else {
+			fmt.Fprintf(w, "  %s\n", injury.String())
+		}
+	}
+}
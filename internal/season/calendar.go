@@ -0,0 +1,198 @@
+package season
+
+import (
+	"nba-scanner/internal/models"
+	"strings"
+	"time"
+)
+
+// Phase 賽季階段
+type Phase string
+
+const (
+	Offseason     Phase = "offseason"      // 休賽期
+	Preseason     Phase = "preseason"      // 季前賽
+	RegularSeason Phase = "regular-season" // 例行賽（含盃賽）
+	PlayIn        Phase = "play-in"        // 附加賽
+	Playoffs      Phase = "playoffs"       // 季後賽（含總冠軍賽）
+)
+
+// PhaseOfGameID 由 NBA GameID 前三碼判斷比賽所屬階段
+// 001=季前賽、002=例行賽、003=明星賽、004=季後賽、005=附加賽、006=盃賽冠軍戰
+func PhaseOfGameID(gameID string) (Phase, bool) {
+	if len(gameID) < 3 {
+		return "", false
+	}
+	switch gameID[:3] {
+	case "001":
+		return Preseason, true
+	case "002", "003", "006":
+		return RegularSeason, true
+	case "004":
+		return Playoffs, true
+	case "005":
+		return PlayIn, true
+	}
+	return "", false
+}
+
+// GameTypeOf 判斷比賽類型（GameInfo.GameType）
+// GameID 前三碼決定季前賽/例行賽/明星賽/季後賽/附加賽，NBA 盃的小組賽與淘汰賽雖然是例行賽 ID，
+// 但賽程的 gameSubtype 會標示 "in-season"、"in-season-knockout"
+func GameTypeOf(gameID string, subtype string) string {
+	if len(gameID) < 3 {
+		return ""
+	}
+	switch gameID[:3] {
+	case "001":
+		return models.GameTypePreseason
+	case "002":
+		if strings.HasPrefix(subtype, "in-season") {
+			return models.GameTypeCup
+		}
+		return models.GameTypeRegular
+	case "003":
+		return models.GameTypeAllStar
+	case "004":
+		return models.GameTypePlayoffs
+	case "005":
+		return models.GameTypePlayIn
+	case "006":
+		return models.GameTypeCup
+	}
+	return ""
+}
+
+// Span 階段的起訖日期（美東日期，含頭尾）
+type Span struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Calendar 單一賽季的聯盟行事曆
+type Calendar struct {
+	Season Season
+	Spans  map[Phase]Span
+}
+
+// phaseOrder 各階段在賽季中的先後順序
+var phaseOrder = []Phase{Preseason, RegularSeason, PlayIn, Playoffs}
+
+// NewCalendar 由整季賽程建立行事曆（各階段的第一場與最後一場比賽日）
+// 賽程中沒有的階段（例如季後賽賽程尚未公布）會以預設行事曆補上
+func NewCalendar(s Season, schedule *models.FullSchedule) Calendar {
+	cal := Calendar{Season: s, Spans: make(map[Phase]Span)}
+
+	for _, gameDate := range schedule.LeagueSchedule.GameDates {
+		// API 日期格式: "10/21/2025 00:00:00"
+		date, err := time.ParseInLocation("01/02/2006 15:04:05", gameDate.GameDate, newYorkLocation)
+		if err != nil {
+			continue
+		}
+
+		for _, game := range gameDate.Games {
+			phase, ok := PhaseOfGameID(game.GameID)
+			if !ok {
+				continue
+			}
+			span, ok := cal.Spans[phase]
+			if !ok || date.Before(span.Start) {
+				span.Start = date
+			}
+			if !ok || date.After(span.End) {
+				span.End = date
+			}
+			cal.Spans[phase] = span
+		}
+	}
+
+	defaults := DefaultCalendar(s)
+	for _, phase := range phaseOrder {
+		if _, ok := cal.Spans[phase]; !ok {
+			cal.Spans[phase] = defaults.Spans[phase]
+		}
+	}
+	return cal
+}
+
+// DefaultCalendar 沒有賽程資料時使用的近似行事曆
+// 季前賽 10/1 起、例行賽 10/21 起、附加賽 4/14 起、季後賽 4/18 至 6/22
+func DefaultCalendar(s Season) Calendar {
+	y := s.StartYear
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, newYorkLocation)
+	}
+	return Calendar{
+		Season: s,
+		Spans: map[Phase]Span{
+			Preseason:     {Start: date(y, time.October, 1), End: date(y, time.October, 20)},
+			RegularSeason: {Start: date(y, time.October, 21), End: date(y+1, time.April, 13)},
+			PlayIn:        {Start: date(y+1, time.April, 14), End: date(y+1, time.April, 17)},
+			Playoffs:      {Start: date(y+1, time.April, 18), End: date(y+1, time.June, 22)},
+		},
+	}
+}
+
+// Phase 指定 NBA 比賽日所處的賽季階段
+// 兩個階段之間的空檔（例如季前賽結束到例行賽開打）仍算前一個階段
+func (c Calendar) Phase(t time.Time) Phase {
+	day := dateOnly(t)
+
+	current := Offseason
+	for _, phase := range phaseOrder {
+		span, ok := c.Spans[phase]
+		if !ok || day.Before(span.Start) {
+			continue
+		}
+		current = phase
+		if !day.After(span.End) {
+			return phase
+		}
+	}
+
+	// 季後賽結束之後為休賽期
+	if span, ok := c.Spans[Playoffs]; ok && day.After(span.End) {
+		return Offseason
+	}
+	return current
+}
+
+// SeasonOf 以行事曆判斷 NBA 比賽日所屬的賽季
+// 季後賽結束前（即使延到 7 月之後）屬於行事曆的賽季，結束後的休賽期屬於下一個賽季；
+// 行事曆賽季開始（7/1）之前的日期以 For 判斷
+func (c Calendar) SeasonOf(t time.Time) Season {
+	day := dateOnly(t)
+	start, _ := c.Season.Bounds()
+	if day.Before(start) {
+		return For(day)
+	}
+	if !day.After(c.End()) {
+		return c.Season
+	}
+	next := Season{StartYear: c.Season.StartYear + 1}
+	if sn := For(day); sn.StartYear > next.StartYear {
+		return sn
+	}
+	return next
+}
+
+// Start 賽季第一場比賽的日期
+func (c Calendar) Start() time.Time {
+	return c.Spans[Preseason].Start
+}
+
+// End 賽季最後一場比賽的日期
+func (c Calendar) End() time.Time {
+	return c.Spans[Playoffs].End
+}
+
+// HasRegularSeasonGames 在指定比賽日是否已經開打例行賽（用於判斷過盤資料是否可用）
+func (c Calendar) HasRegularSeasonGames(t time.Time) bool {
+	span, ok := c.Spans[RegularSeason]
+	return ok && !dateOnly(t).Before(span.Start)
+}
+
+// dateOnly 只保留日期（呼叫端傳入的應為 NBA 比賽日）
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, newYorkLocation)
+}
@@ -0,0 +1,39 @@
+package season
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalendarSeasonOf(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, newYorkLocation)
+	}
+	cal := DefaultCalendar(Season{StartYear: 2025})
+	// 季後賽延到 7 月（例如 2020 年的園區賽季）
+	late := DefaultCalendar(Season{StartYear: 2025})
+	late.Spans[Playoffs] = Span{Start: date(2026, time.May, 1), End: date(2026, time.July, 10)}
+
+	tests := []struct {
+		name string
+		cal  Calendar
+		day  time.Time
+		want int
+	}{
+		{"例行賽", cal, date(2025, time.December, 25), 2025},
+		{"季後賽", cal, date(2026, time.June, 10), 2025},
+		{"季後賽結束後的休賽期", cal, date(2026, time.June, 25), 2026},
+		{"下一個賽季的休賽期", cal, date(2026, time.August, 1), 2026},
+		{"延到 7 月的季後賽", late, date(2026, time.July, 5), 2025},
+		{"延長的季後賽結束後", late, date(2026, time.July, 11), 2026},
+		{"行事曆賽季之前", cal, date(2024, time.November, 1), 2024},
+		{"兩個賽季之後", cal, date(2027, time.November, 1), 2027},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cal.SeasonOf(tt.day); got.StartYear != tt.want {
+				t.Errorf("SeasonOf(%s) = %s，預期 %d", tt.day.Format("2006-01-02"), got, tt.want)
+			}
+		})
+	}
+}
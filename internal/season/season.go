@@ -0,0 +1,118 @@
+// Package season 計算 NBA 賽季與賽季階段（季前賽、例行賽、附加賽、季後賽、休賽期）
+package season
+
+import (
+	"fmt"
+	"nba-scanner/internal/gametime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Season NBA 賽季，以開季年份表示（2025 表示 2025-26 賽季）
+type Season struct {
+	StartYear int
+}
+
+// newYorkLocation NBA 賽程使用的美東時區
+var newYorkLocation = gametime.Eastern
+
+// seasonStartMonth 新賽季從 7 月起算（6 月總冠軍賽結束後進入下一個賽季的休賽期）
+const seasonStartMonth = time.July
+
+// For 取得指定日期所屬的賽季（只看 t 本身的年月，呼叫端需先轉成 NBA 比賽日）
+func For(t time.Time) Season {
+	if t.Month() >= seasonStartMonth {
+		return Season{StartYear: t.Year()}
+	}
+	return Season{StartYear: t.Year() - 1}
+}
+
+// Current 取得目前的賽季（以美東日期判斷）
+// 沒有賽程時的近似值，有賽程時應使用 Calendar.SeasonOf（crawler.Sources.SeasonFor）
+func Current() Season {
+	return For(time.Now().In(newYorkLocation))
+}
+
+// Parse 解析賽季字串，接受 "2025-26"、"2025-2026"、"25-26" 或 "2025"
+func Parse(s string) (Season, error) {
+	s = strings.TrimSpace(s)
+	first, _, _ := strings.Cut(s, "-")
+
+	year, err := strconv.Atoi(first)
+	if err != nil {
+		return Season{}, fmt.Errorf("賽季格式錯誤: %q", s)
+	}
+	if len(first) == 2 {
+		year += 2000
+	}
+	if year < 1946 || year > 2100 {
+		return Season{}, fmt.Errorf("賽季年份超出範圍: %q", s)
+	}
+
+	season := Season{StartYear: year}
+	if _, second, ok := strings.Cut(s, "-"); ok {
+		if second != season.endSuffix(len(second)) {
+			return Season{}, fmt.Errorf("賽季格式錯誤: %q", s)
+		}
+	}
+	return season, nil
+}
+
+// endSuffix 結束年份的後 n 碼
+func (s Season) endSuffix(n int) string {
+	end := strconv.Itoa(s.StartYear + 1)
+	if n >= len(end) {
+		return end
+	}
+	return end[len(end)-n:]
+}
+
+// String NBA 慣用格式 "2025-26"
+func (s Season) String() string {
+	return fmt.Sprintf("%d-%s", s.StartYear, s.endSuffix(2))
+}
+
+// IsZero 是否未指定賽季
+func (s Season) IsZero() bool {
+	return s.StartYear == 0
+}
+
+// Previous 上一個賽季
+func (s Season) Previous() Season {
+	return Season{StartYear: s.StartYear - 1}
+}
+
+// Titan007 titan007 HandicapDetail 頁面的賽季參數 "2025-2026"
+func (s Season) Titan007() string {
+	return fmt.Sprintf("%d-%d", s.StartYear, s.StartYear+1)
+}
+
+// LetGoal titan007 l1.js 路徑使用的賽季 "25-26"
+func (s Season) LetGoal() string {
+	return fmt.Sprintf("%02d-%s", s.StartYear%100, s.endSuffix(2))
+}
+
+// GameIDYear NBA GameID 第 4-5 碼的賽季年份（"0022500511" 中的 "25"）
+func (s Season) GameIDYear() string {
+	return fmt.Sprintf("%02d", s.StartYear%100)
+}
+
+// Bounds 賽季涵蓋的日期範圍（美東 7/1 至隔年 6/30）
+func (s Season) Bounds() (start, end time.Time) {
+	start = time.Date(s.StartYear, seasonStartMonth, 1, 0, 0, 0, 0, newYorkLocation)
+	end = start.AddDate(1, 0, 0).Add(-time.Nanosecond)
+	return start, end
+}
+
+// ForGameID 由 NBA GameID 取得賽季（"0022500511" -> 2025-26）
+func ForGameID(gameID string) (Season, bool) {
+	if len(gameID) < 5 {
+		return Season{}, false
+	}
+	yy, err := strconv.Atoi(gameID[3:5])
+	if err != nil {
+		return Season{}, false
+	}
+	return Season{StartYear: 2000 + yy}, true
+}
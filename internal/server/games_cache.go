@@ -0,0 +1,206 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"nba-scanner/internal/models"
+	"sync"
+	"time"
+)
+
+// 預設快取時間
+const (
+	DefaultLiveTTL     = 15 * time.Second // 有比賽進行中，或有上游來源失敗
+	DefaultUpcomingTTL = time.Minute      // 還有比賽未開打（盤口、傷兵仍會變動）
+	DefaultFinalTTL    = time.Hour        // 整個比賽日都已打完
+)
+
+// CacheConfig /api/games 回應快取時間（依比賽狀態決定，0 使用預設值，負數表示不快取）
+type CacheConfig struct {
+	LiveTTL     time.Duration
+	UpcomingTTL time.Duration
+	FinalTTL    time.Duration
+}
+
+// cachedGames 已編碼的 /api/games 回應
+type cachedGames struct {
+	body    []byte
+	etag    string
+	expires time.Time
+}
+
+// gamesCall 進行中的回應建立（相同 key 的請求共用結果）
+type gamesCall struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int // 等待結果的請求數，全部離開時取消上游抓取
+	entry   *cachedGames
+	err     error
+}
+
+// gamesCache /api/games 的回應快取
+// 相同比賽日的同時請求只建立一次回應（開賽時多人同時重新整理不會重複抓上游），
+// 建立好的回應依比賽狀態快取一段時間
+type gamesCache struct {
+	cfg     CacheConfig
+	timeout time.Duration // 建立回應的期限（Config.RequestTimeout，0 表示不限制）
+
+	mu       sync.Mutex
+	entries  map[string]*cachedGames
+	inflight map[string]*gamesCall
+}
+
+// newGamesCache 建立回應快取（timeout 為建立一次回應的期限，0 表示不限制）
+func newGamesCache(cfg CacheConfig, timeout time.Duration) *gamesCache {
+	if cfg.LiveTTL == 0 {
+		cfg.LiveTTL = DefaultLiveTTL
+	}
+	if cfg.UpcomingTTL == 0 {
+		cfg.UpcomingTTL = DefaultUpcomingTTL
+	}
+	if cfg.FinalTTL == 0 {
+		cfg.FinalTTL = DefaultFinalTTL
+	}
+	return &gamesCache{
+		cfg:      cfg,
+		timeout:  timeout,
+		entries:  make(map[string]*cachedGames),
+		inflight: make(map[string]*gamesCall),
+	}
+}
+
+// get 取得快取的回應，沒有或已過期時呼叫 build 建立（相同 key 同時只會執行一次）
+// build 使用獨立的 context：第一個請求中斷不會影響其他等待中的請求，所有請求都離開（中斷或逾時）後才取消
+// build 本身也受請求期限限制，後來加入等待的請求不會讓抓取無限延長
+// 回傳的 bool 表示是否直接使用了快取
+func (c *gamesCache) get(ctx context.Context, key string, build func(context.Context) (*models.APIResponse, error)) (*cachedGames, bool, error) {
+	now := time.Now()
+
+	c.mu.Lock()
+	if entry, ok := c.entries[key]; ok && now.Before(entry.expires) {
+		c.mu.Unlock()
+		return entry, true, nil
+	}
+	call, ok := c.inflight[key]
+	if !ok {
+		var (
+			buildCtx context.Context
+			cancel   context.CancelFunc
+		)
+		if c.timeout > 0 {
+			buildCtx, cancel = context.WithTimeout(context.Background(), c.timeout)
+		} else {
+			buildCtx, cancel = context.WithCancel(context.Background())
+		}
+		call = &gamesCall{done: make(chan struct{}), cancel: cancel}
+		c.inflight[key] = call
+		go c.run(buildCtx, key, call, build)
+	}
+	call.waiters++
+	c.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.entry, false, call.err
+	case <-ctx.Done():
+		c.mu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			// 沒有人在等了：取消上游抓取，之後的請求重新建立
+			call.cancel()
+			if c.inflight[key] == call {
+				delete(c.inflight, key)
+			}
+		}
+		c.mu.Unlock()
+		return nil, false, ctx.Err()
+	}
+}
+
+// run 建立回應並寫入快取
+func (c *gamesCache) run(ctx context.Context, key string, call *gamesCall, build func(context.Context) (*models.APIResponse, error)) {
+	defer close(call.done)
+	defer call.cancel()
+
+	response, err := build(ctx)
+	var entry *cachedGames
+	if err == nil {
+		entry, err = c.encode(response)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.inflight[key] == call {
+		delete(c.inflight, key)
+	}
+	call.entry, call.err = entry, err
+	if err != nil || entry.expires.IsZero() {
+		return // 錯誤不快取
+	}
+
+	// 順便清掉過期的回應，避免查過的日期一直留在記憶體中
+	now := time.Now()
+	for k, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = entry
+}
+
+// encode 編碼回應並計算 ETag 與到期時間
+func (c *gamesCache) encode(response *models.APIResponse) (*cachedGames, error) {
+	body, err := json.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+	body = append(body, '\n') // 與 json.Encoder 的輸出一致
+
+	etag, err := gamesETag(response)
+	if err != nil {
+		return nil, err
+	}
+	entry := &cachedGames{body: body, etag: etag}
+	if ttl := c.ttl(response); ttl > 0 {
+		entry.expires = time.Now().Add(ttl)
+	}
+	return entry, nil
+}
+
+// gamesETag 以比賽資料計算 ETag
+// 上游來源只取名稱與狀態：每次重新建立時抓取時間、耗時都會不同，比賽資料沒變時 ETag 應維持不變
+func gamesETag(response *models.APIResponse) (string, error) {
+	stable := *response
+	stable.Sources = make([]models.SourceStatus, len(response.Sources))
+	for i, source := range response.Sources {
+		stable.Sources[i] = models.SourceStatus{Name: source.Name, Status: source.Status}
+	}
+	data, err := json.Marshal(stable)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// ttl 依比賽狀態決定快取時間：進行中或有來源失敗時最短，整個比賽日打完時最長
+func (c *gamesCache) ttl(response *models.APIResponse) time.Duration {
+	for _, source := range response.Sources {
+		if source.Status == models.SourceError || source.Status == models.SourceStale {
+			return c.cfg.LiveTTL
+		}
+	}
+
+	ttl := c.cfg.FinalTTL
+	for _, game := range response.Games {
+		switch game.GameStatus {
+		case 2:
+			return c.cfg.LiveTTL
+		case 1:
+			ttl = c.cfg.UpcomingTTL
+		}
+	}
+	return ttl
+}
@@ -0,0 +1,231 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"nba-scanner/internal/models"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGamesCacheCoalescesConcurrentRequests(t *testing.T) {
+	c := newGamesCache(CacheConfig{}, 0)
+	release := make(chan struct{})
+	var builds int32
+	build := func(ctx context.Context) (*models.APIResponse, error) {
+		atomic.AddInt32(&builds, 1)
+		<-release
+		return &models.APIResponse{Date: "2026-01-01"}, nil
+	}
+
+	const requests = 8
+	var (
+		wg      sync.WaitGroup
+		entries = make([]*cachedGames, requests)
+	)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			entry, _, err := c.get(context.Background(), "2026-01-01", build)
+			if err != nil {
+				t.Errorf("請求 %d 失敗: %v", i, err)
+			}
+			entries[i] = entry
+		}(i)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if builds != 1 {
+		t.Errorf("同時請求建立了 %d 次回應，預期 1 次", builds)
+	}
+	for i, entry := range entries {
+		if entry == nil || entry != entries[0] {
+			t.Errorf("請求 %d 沒有共用同一份回應", i)
+		}
+	}
+
+	if _, cached, err := c.get(context.Background(), "2026-01-01", build); err != nil || !cached {
+		t.Errorf("第二次請求應使用快取，得到 cached=%v, %v", cached, err)
+	}
+	if builds != 1 {
+		t.Errorf("使用快取時不應再建立回應（共 %d 次）", builds)
+	}
+}
+
+func TestGamesCacheCancellation(t *testing.T) {
+	tests := []struct {
+		name       string
+		stay       bool // 是否有另一個請求留下來等待
+		wantCancel bool
+	}{
+		{"所有請求離開時取消上游抓取", false, true},
+		{"還有請求在等時繼續抓取", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newGamesCache(CacheConfig{}, 0)
+			started := make(chan struct{})
+			release := make(chan struct{})
+			cancelled := make(chan struct{})
+			build := func(ctx context.Context) (*models.APIResponse, error) {
+				close(started)
+				select {
+				case <-ctx.Done():
+					close(cancelled)
+					return nil, ctx.Err()
+				case <-release:
+					return &models.APIResponse{}, nil
+				}
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			first := make(chan error, 1)
+			go func() {
+				_, _, err := c.get(ctx, "key", build)
+				first <- err
+			}()
+			<-started
+
+			second := make(chan error, 1)
+			if tt.stay {
+				go func() {
+					_, _, err := c.get(context.Background(), "key", build)
+					second <- err
+				}()
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			cancel()
+			if err := <-first; !errors.Is(err, context.Canceled) {
+				t.Errorf("離開的請求錯誤 = %v，預期 context.Canceled", err)
+			}
+
+			select {
+			case <-cancelled:
+				if !tt.wantCancel {
+					t.Fatal("還有請求在等時不應取消上游抓取")
+				}
+			case <-time.After(50 * time.Millisecond):
+				if tt.wantCancel {
+					t.Fatal("所有請求離開後應取消上游抓取")
+				}
+			}
+
+			if tt.stay {
+				close(release)
+				if err := <-second; err != nil {
+					t.Errorf("留下的請求應取得回應，得到 %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestGamesCacheBuildTimeout(t *testing.T) {
+	c := newGamesCache(CacheConfig{}, 10*time.Millisecond)
+	var builds int32
+	build := func(ctx context.Context) (*models.APIResponse, error) {
+		atomic.AddInt32(&builds, 1)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	start := time.Now()
+	_, _, err := c.get(context.Background(), "key", build)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("錯誤 = %v，預期 context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("建立回應超過期限仍等待了 %v", elapsed)
+	}
+
+	// 錯誤不快取，下一個請求重新建立
+	c.get(context.Background(), "key", build)
+	if builds != 2 {
+		t.Errorf("建立了 %d 次回應，預期 2 次（錯誤不快取）", builds)
+	}
+}
+
+func TestGamesCacheTTL(t *testing.T) {
+	cfg := CacheConfig{LiveTTL: time.Second, UpcomingTTL: time.Minute, FinalTTL: time.Hour}
+	tests := []struct {
+		name     string
+		response models.APIResponse
+		want     time.Duration
+	}{
+		{"全部打完", models.APIResponse{Games: []models.GameInfo{{GameStatus: 3}, {GameStatus: 3}}}, time.Hour},
+		{"還有比賽未開打", models.APIResponse{Games: []models.GameInfo{{GameStatus: 3}, {GameStatus: 1}}}, time.Minute},
+		{"有比賽進行中", models.APIResponse{Games: []models.GameInfo{{GameStatus: 1}, {GameStatus: 2}}}, time.Second},
+		{"上游來源失敗", models.APIResponse{
+			Games:   []models.GameInfo{{GameStatus: 3}},
+			Sources: []models.SourceStatus{{Status: models.SourceError}},
+		}, time.Second},
+		{"上游來源使用舊資料", models.APIResponse{
+			Games:   []models.GameInfo{{GameStatus: 3}},
+			Sources: []models.SourceStatus{{Status: models.SourceStale}},
+		}, time.Second},
+	}
+	c := newGamesCache(cfg, 0)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.ttl(&tt.response); got != tt.want {
+				t.Errorf("ttl = %v，預期 %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGamesCacheETagIgnoresSourceTimings(t *testing.T) {
+	c := newGamesCache(CacheConfig{LiveTTL: -1, UpcomingTTL: -1, FinalTTL: -1}, 0)
+	rebuild := func(score int, attempt string, latency int64) *cachedGames {
+		t.Helper()
+		entry, _, err := c.get(context.Background(), "2026-01-01", func(ctx context.Context) (*models.APIResponse, error) {
+			return &models.APIResponse{
+				Date:  "2026-01-01",
+				Games: []models.GameInfo{{GameID: "0022500001", GameStatus: 3, HomeScore: score}},
+				Sources: []models.SourceStatus{{
+					Name: "schedule", Status: models.SourceOK, LatencyMs: latency, LastAttempt: attempt, LastSuccess: attempt,
+				}},
+			}, nil
+		})
+		if err != nil {
+			t.Fatalf("建立回應失敗: %v", err)
+		}
+		return entry
+	}
+
+	first := rebuild(110, "2026-01-01T10:00:00Z", 120)
+	second := rebuild(110, "2026-01-01T11:00:00Z", 340)
+	if string(first.body) == string(second.body) {
+		t.Fatal("兩次建立的回應內容應不同（上游抓取時間不同）")
+	}
+	if first.etag != second.etag {
+		t.Errorf("比賽資料相同時 ETag 應相同：%s / %s", first.etag, second.etag)
+	}
+
+	if changed := rebuild(112, "2026-01-01T11:00:00Z", 340); changed.etag == first.etag {
+		t.Error("比分改變時 ETag 應不同")
+	}
+}
+
+func TestGamesCacheNegativeTTLDisablesCaching(t *testing.T) {
+	c := newGamesCache(CacheConfig{LiveTTL: -1, UpcomingTTL: -1, FinalTTL: -1}, 0)
+	var builds int32
+	build := func(ctx context.Context) (*models.APIResponse, error) {
+		atomic.AddInt32(&builds, 1)
+		return &models.APIResponse{}, nil
+	}
+	for i := 0; i < 2; i++ {
+		if _, cached, err := c.get(context.Background(), "key", build); err != nil || cached {
+			t.Fatalf("不快取時 cached=%v, %v", cached, err)
+		}
+	}
+	if builds != 2 {
+		t.Errorf("建立了 %d 次回應，預期 2 次", builds)
+	}
+}
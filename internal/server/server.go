@@ -1,22 +1,73 @@
 package server
 
 import (
+	"context"
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"log"
+	"nba-scanner/internal/crawler"
+	"nba-scanner/internal/gametime"
 	"nba-scanner/internal/logic"
+	"nba-scanner/internal/models"
+	"nba-scanner/internal/slate"
+	"nba-scanner/internal/store"
+	"net"
 	"net/http"
+	"strings"
+	"time"
 )
 
 //go:embed static/*
 var staticFiles embed.FS
 
-// Start 啟動 HTTP Server
-func Start(port int) error {
+// Config Server 設定
+type Config struct {
+	Port             int
+	Store            *store.Store         // 為 nil 時不提供盤口走勢
+	OddsPollInterval time.Duration        // 賠率輪詢間隔
+	InjuryInterval   time.Duration        // 傷兵名單輪詢間隔（0 表示只在請求時比對）
+	Injuries         *logic.InjuryTracker // 傷兵名單變動紀錄（nil 表示依 Store 建立）
+	Steam            logic.SteamConfig    // 急速變盤判斷條件
+	Season           string               // 預設賽季（/api/games 未指定 date 與 season 時使用，空字串表示目前賽季）
+	Location         *time.Location       // 預設顯示時區（/api/games 未指定 tz 時使用，nil 表示 Asia/Taipei）
+	Slate            *slate.Resolver      // 未指定日期時的比賽日判斷（nil 表示使用預設設定）
+	RequestTimeout   time.Duration        // /api/games 單次請求的期限（0 表示不限制，仍會在連線中斷時取消）
+	Cache            CacheConfig          // /api/games 回應快取時間
+}
+
+// Start 啟動 HTTP Server，ctx 取消時停止背景輪詢並關閉 server
+func Start(ctx context.Context, cfg Config, src *crawler.Sources) error {
+	if cfg.Slate == nil {
+		rs, err := logic.NewSlateResolver(src, slate.Config{Hold: slate.DefaultHold})
+		if err != nil {
+			return err
+		}
+		cfg.Slate = rs
+	}
+	if cfg.Injuries == nil {
+		cfg.Injuries = logic.NewInjuryTracker(cfg.Store)
+	}
+
+	// 背景更新整季賽程（ETag 未變更時不會重新下載）
+	src.Season.StartRefresh(ctx, 5*time.Minute)
+
+	// 背景輪詢賠率，保存盤口走勢
+	if cfg.Store != nil && cfg.OddsPollInterval > 0 {
+		logic.StartOddsPoller(ctx, src.Odds, cfg.Store, cfg.OddsPollInterval)
+	}
+
+	// 背景輪詢傷兵名單，記錄狀態變動
+	if cfg.InjuryInterval > 0 {
+		logic.StartInjuryPoller(ctx, src.Injury, cfg.Injuries, cfg.InjuryInterval)
+	}
+
 	// API endpoint
-	http.HandleFunc("/api/games", handleGamesAPI)
+	http.HandleFunc("/api/games", handleGamesAPI(cfg, src, newGamesCache(cfg.Cache, cfg.RequestTimeout)))
+	http.HandleFunc("GET /api/games/{id}/odds/history", handleOddsHistoryAPI(cfg))
+	http.HandleFunc("GET /api/injuries/changes", handleInjuryChangesAPI(cfg))
 
 	// 靜態檔案（HTML, CSS, JS）
 	staticFS, err := fs.Sub(staticFiles, "static")
@@ -25,30 +76,180 @@ func Start(port int) error {
 	}
 	http.Handle("/", http.FileServer(http.FS(staticFS)))
 
-	addr := fmt.Sprintf(":%d", port)
+	addr := fmt.Sprintf(":%d", cfg.Port)
 	log.Printf("🏀 NBA Scanner 啟動於 http://localhost%s\n", addr)
-	return http.ListenAndServe(addr, nil)
+
+	server := &http.Server{
+		Addr: addr,
+		// 請求的 context 衍生自 ctx，關閉 server 時進行中的抓取也會一併取消
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	go func() {
+		<-ctx.Done()
+		// 停止接受新連線，並取消進行中的請求
+		log.Println("正在關閉 server...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			server.Close()
+		}
+	}()
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
 }
 
 // handleGamesAPI 處理 API 請求
-func handleGamesAPI(w http.ResponseWriter, r *http.Request) {
-	// 設定 CORS 和 JSON header
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
+// 相同比賽日的同時請求共用一次抓取，回應依比賽狀態快取，並支援 ETag / If-None-Match
+func handleGamesAPI(cfg Config, src *crawler.Sources, cache *gamesCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// 設定 CORS 和 JSON header
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
 
-	// 取得日期參數
-	dateParam := r.URL.Query().Get("date")
+		// 取得日期與賽季參數
+		dateParam := r.URL.Query().Get("date")
+		seasonParam := r.URL.Query().Get("season")
+		if dateParam == "" && seasonParam == "" {
+			seasonParam = cfg.Season
+		}
 
-	// 取得比賽資料
-	games, err := logic.GetGamesByDate(dateParam)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error": err.Error(),
+		// 顯示時區：?tz=America/New_York，未指定時使用 server 預設
+		loc := cfg.Location
+		if tz := r.URL.Query().Get("tz"); tz != "" || loc == nil {
+			var err error
+			loc, err = gametime.LoadZone(tz)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{
+					"error": err.Error(),
+				})
+				return
+			}
+		}
+
+		// 連線中斷時取消抓取，並套用整體期限
+		ctx := r.Context()
+		if cfg.RequestTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, cfg.RequestTimeout)
+			defer cancel()
+		}
+
+		// 未指定日期時以目前比賽日作為 key，比賽日切換後不會沿用前一天的回應
+		key := strings.Join([]string{dateParam, seasonParam, loc.String()}, "|")
+		if dateParam == "" {
+			key += "|" + cfg.Slate.Today(ctx).Format("2006-01-02")
+		}
+
+		// 取得比賽資料
+		entry, hit, err := cache.get(ctx, key, func(ctx context.Context) (*models.APIResponse, error) {
+			return logic.GetGamesByDate(ctx, src, cfg.Slate, cfg.Injuries, dateParam, seasonParam, loc)
 		})
-		return
+		if err != nil {
+			if r.Context().Err() != nil {
+				// 使用者已離開，不需要回應
+				log.Printf("請求已取消: %s", r.URL)
+				return
+			}
+			status := http.StatusInternalServerError
+			if ctx.Err() != nil || errors.Is(err, context.DeadlineExceeded) {
+				status = http.StatusGatewayTimeout
+				err = fmt.Errorf("超過請求期限 %v: %w", cfg.RequestTimeout, err)
+			}
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(map[string]any{
+				"error":   err.Error(),
+				"sources": src.Monitor.Statuses(),
+			})
+			return
+		}
+
+		w.Header().Set("ETag", entry.etag)
+		w.Header().Set("Cache-Control", "no-cache") // 每次都向 server 確認，未變更時回傳 304
+		if hit {
+			w.Header().Set("X-Cache", "HIT")
+		} else {
+			w.Header().Set("X-Cache", "MISS")
+		}
+		if etagMatches(r.Header.Get("If-None-Match"), entry.etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		// 回傳 JSON
+		w.Write(entry.body)
+	}
+}
+
+// etagMatches If-None-Match 是否包含目前的 ETag（忽略弱比對前綴 W/）
+func etagMatches(header string, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// handleOddsHistoryAPI 回傳單場比賽的盤口走勢
+func handleOddsHistoryAPI(cfg Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		if cfg.Store == nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "未啟用資料庫，無盤口走勢資料",
+			})
+			return
+		}
+
+		history, err := logic.GetOddsHistory(cfg.Store, r.PathValue("id"), cfg.Steam)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		json.NewEncoder(w).Encode(history)
 	}
+}
+
+// handleInjuryChangesAPI 回傳指定時間之後的傷兵名單變動（?since= 未指定時為最近 24 小時）
+func handleInjuryChangesAPI(cfg Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
 
-	// 回傳 JSON
-	json.NewEncoder(w).Encode(games)
+		now := time.Now()
+		since := now.Add(-24 * time.Hour)
+		if param := r.URL.Query().Get("since"); param != "" {
+			var err error
+			since, err = logic.ParseSince(param, now)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{
+					"error": err.Error(),
+				})
+				return
+			}
+		}
+
+		changes, err := logic.GetInjuryChanges(cfg.Injuries, since)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": err.Error(),
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(changes)
+	}
 }
@@ -0,0 +1,181 @@
+// Package settlement 依各節比分與盤口結算讓分、獨贏、大小分
+// 支援全場、上半場、各節與延長賽，以及亞洲盤的四分之一盤口（如 -4.25 拆成 -4 與 -4.5 各半注）
+package settlement
+
+import (
+	"math"
+	"nba-scanner/internal/models"
+)
+
+// Line 單一時段的盤口
+type Line struct {
+	HomeSpread    *float64 // 主隊讓分（負數為讓分，正數為受讓）
+	Total         *float64 // 大小分
+	SpreadDerived bool     // 讓分是否由全場盤口估算
+	TotalDerived  bool     // 大小分是否由全場盤口估算
+}
+
+// Lines 各時段的盤口（key 為 models.SegmentFullGame 等時段）
+type Lines map[string]Line
+
+// segmentLabels 時段的顯示名稱
+var segmentLabels = map[string]string{
+	models.SegmentFullGame:  "全場",
+	models.SegmentFirstHalf: "上半",
+	models.SegmentQ1:        "第一節",
+	models.SegmentQ2:        "第二節",
+	models.SegmentQ3:        "第三節",
+	models.SegmentQ4:        "第四節",
+	models.SegmentOvertime:  "延長賽",
+}
+
+// Settle 結算已結束比賽的所有時段（含全場與延長賽）
+func Settle(ps models.PeriodScores, lines Lines) models.BetSettlement {
+	return settlePeriods(ps, lines, len(ps.HomePeriods), true)
+}
+
+// SettleCompleted 只結算前 completed 節已全部打完的時段（用於進行中的比賽）
+// 只結算上半場與各節；全場與延長賽要等比賽結束（GameStatus 3）才以 Settle 結算，
+// 第四節或延長賽打平時比賽仍會繼續，不能以時鐘歸零判斷
+func SettleCompleted(ps models.PeriodScores, lines Lines, completed int) models.BetSettlement {
+	return settlePeriods(ps, lines, min(completed, 4), false)
+}
+
+// settlePeriods 結算前 completed 節已打完的時段，final 為比賽已結束
+func settlePeriods(ps models.PeriodScores, lines Lines, completed int, final bool) models.BetSettlement {
+	result := models.BetSettlement{Segments: []models.SegmentSettlement{}}
+
+	periods := min(len(ps.HomePeriods), len(ps.AwayPeriods))
+	completed = min(completed, periods)
+
+	add := func(segment string, from, to int) {
+		home, away := sum(ps.HomePeriods, from, to), sum(ps.AwayPeriods, from, to)
+		result.Segments = append(result.Segments, settleSegment(segment, home, away, lines[segment]))
+	}
+
+	if final {
+		add(models.SegmentFullGame, 0, periods)
+	}
+	if completed >= 2 {
+		add(models.SegmentFirstHalf, 0, 2)
+	}
+	for i, segment := range []string{models.SegmentQ1, models.SegmentQ2, models.SegmentQ3, models.SegmentQ4} {
+		if completed > i {
+			add(segment, i, i+1)
+		}
+	}
+	if final && periods > 4 {
+		add(models.SegmentOvertime, 4, periods)
+	}
+
+	return result
+}
+
+// settleSegment 結算單一時段
+func settleSegment(segment string, home, away int, line Line) models.SegmentSettlement {
+	seg := models.SegmentSettlement{
+		Segment:   segment,
+		Label:     segmentLabels[segment],
+		HomeScore: home,
+		AwayScore: away,
+	}
+	seg.Moneyline.Home, seg.Moneyline.Away = Moneyline(home, away)
+
+	if line.HomeSpread != nil {
+		s := &models.SpreadSettlement{HomeLine: *line.HomeSpread, Derived: line.SpreadDerived}
+		s.Home, s.Away = Spread(home, away, *line.HomeSpread)
+		seg.Spread = s
+	}
+	if line.Total != nil {
+		t := &models.TotalSettlement{Line: *line.Total, Derived: line.TotalDerived, Points: home + away}
+		t.Over, t.Under = Total(home+away, *line.Total)
+		seg.Total = t
+	}
+
+	return seg
+}
+
+// Moneyline 獨贏結算，平手為走盤
+func Moneyline(home, away int) (homeResult, awayResult models.BetResult) {
+	r := settle(float64(home - away))
+	return r, Opposite(r)
+}
+
+// Spread 讓分結算，homeLine 為主隊讓分（-4.5 表示主隊讓 4.5 分）
+func Spread(home, away int, homeLine float64) (homeResult, awayResult models.BetResult) {
+	r := settleLine(float64(home-away), homeLine)
+	return r, Opposite(r)
+}
+
+// Total 大小分結算
+func Total(points int, line float64) (over, under models.BetResult) {
+	r := settleLine(float64(points), -line)
+	return r, Opposite(r)
+}
+
+// IsQuarterLine 是否為四分之一盤口（x.25 或 x.75）
+func IsQuarterLine(line float64) bool {
+	return math.Mod(math.Abs(line)*4, 2) == 1
+}
+
+// settleLine 以 value + line 判斷輸贏，四分之一盤口拆成相鄰兩個盤口各半注
+func settleLine(value, line float64) models.BetResult {
+	if !IsQuarterLine(line) {
+		return settle(value + line)
+	}
+	return combine(settle(value+line-0.25), settle(value+line+0.25))
+}
+
+// settle 單一盤口的輸贏（> 0 贏、< 0 輸、= 0 走盤）
+func settle(diff float64) models.BetResult {
+	switch {
+	case diff > 0:
+		return models.BetWin
+	case diff < 0:
+		return models.BetLoss
+	}
+	return models.BetPush
+}
+
+// combine 合併兩個半注的結果
+func combine(a, b models.BetResult) models.BetResult {
+	switch {
+	case a == b:
+		return a
+	case a == models.BetWin || b == models.BetWin:
+		if a == models.BetLoss || b == models.BetLoss {
+			return models.BetPush
+		}
+		return models.BetHalfWin
+	default:
+		return models.BetHalfLoss
+	}
+}
+
+// Opposite 對手方的結果
+func Opposite(r models.BetResult) models.BetResult {
+	switch r {
+	case models.BetWin:
+		return models.BetLoss
+	case models.BetHalfWin:
+		return models.BetHalfLoss
+	case models.BetHalfLoss:
+		return models.BetHalfWin
+	case models.BetLoss:
+		return models.BetWin
+	}
+	return r
+}
+
+// HalfLine 以全場盤口估算上半場盤口（取一半後對齊到四分之一盤）
+func HalfLine(line float64) float64 {
+	return math.Round(line*2) / 4
+}
+
+func sum(periods []int, from, to int) int {
+	total := 0
+	for i := from; i < to && i < len(periods); i++ {
+		total += periods[i]
+	}
+	return total
+}
@@ -0,0 +1,257 @@
+package settlement
+
+import (
+	"nba-scanner/internal/models"
+	"testing"
+)
+
+func ptr(v float64) *float64 { return &v }
+
+func TestSpread(t *testing.T) {
+	tests := []struct {
+		name     string
+		home     int
+		away     int
+		homeLine float64
+		wantHome models.BetResult
+		wantAway models.BetResult
+	}{
+		{"主隊讓分過盤", 110, 100, -4.5, models.BetWin, models.BetLoss},
+		{"主隊讓分未過盤", 102, 100, -4.5, models.BetLoss, models.BetWin},
+		{"整數盤走盤", 104, 100, -4, models.BetPush, models.BetPush},
+		{"主隊受讓輸球仍過盤", 98, 100, 3.5, models.BetWin, models.BetLoss},
+		{"平手盤", 100, 100, 0, models.BetPush, models.BetPush},
+		// 四分之一盤：-4.25 拆成 -4 與 -4.5 各半注
+		{"-4.25 贏 4 分輸半", 104, 100, -4.25, models.BetHalfLoss, models.BetHalfWin},
+		{"-4.25 贏 5 分全贏", 105, 100, -4.25, models.BetWin, models.BetLoss},
+		{"-4.25 贏 3 分全輸", 103, 100, -4.25, models.BetLoss, models.BetWin},
+		// -4.75 拆成 -4.5 與 -5 各半注
+		{"-4.75 贏 5 分贏半", 105, 100, -4.75, models.BetHalfWin, models.BetHalfLoss},
+		{"-4.75 贏 6 分全贏", 106, 100, -4.75, models.BetWin, models.BetLoss},
+		{"-4.75 贏 4 分全輸", 104, 100, -4.75, models.BetLoss, models.BetWin},
+		{"+0.25 平手贏半", 100, 100, 0.25, models.BetHalfWin, models.BetHalfLoss},
+		{"-0.25 平手輸半", 100, 100, -0.25, models.BetHalfLoss, models.BetHalfWin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home, away := Spread(tt.home, tt.away, tt.homeLine)
+			if home != tt.wantHome || away != tt.wantAway {
+				t.Errorf("Spread(%d, %d, %v) = %s/%s，預期 %s/%s", tt.home, tt.away, tt.homeLine, home, away, tt.wantHome, tt.wantAway)
+			}
+		})
+	}
+}
+
+func TestTotal(t *testing.T) {
+	tests := []struct {
+		name      string
+		points    int
+		line      float64
+		wantOver  models.BetResult
+		wantUnder models.BetResult
+	}{
+		{"大分", 230, 228.5, models.BetWin, models.BetLoss},
+		{"小分", 220, 228.5, models.BetLoss, models.BetWin},
+		{"走盤", 228, 228, models.BetPush, models.BetPush},
+		{"228.25 剛好 228 大分輸半", 228, 228.25, models.BetHalfLoss, models.BetHalfWin},
+		{"228.75 剛好 229 大分贏半", 229, 228.75, models.BetHalfWin, models.BetHalfLoss},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			over, under := Total(tt.points, tt.line)
+			if over != tt.wantOver || under != tt.wantUnder {
+				t.Errorf("Total(%d, %v) = %s/%s，預期 %s/%s", tt.points, tt.line, over, under, tt.wantOver, tt.wantUnder)
+			}
+		})
+	}
+}
+
+func TestMoneyline(t *testing.T) {
+	tests := []struct {
+		name     string
+		home     int
+		away     int
+		wantHome models.BetResult
+		wantAway models.BetResult
+	}{
+		{"主隊勝", 101, 99, models.BetWin, models.BetLoss},
+		{"客隊勝", 99, 101, models.BetLoss, models.BetWin},
+		{"單節平手走盤", 25, 25, models.BetPush, models.BetPush},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home, away := Moneyline(tt.home, tt.away)
+			if home != tt.wantHome || away != tt.wantAway {
+				t.Errorf("Moneyline(%d, %d) = %s/%s，預期 %s/%s", tt.home, tt.away, home, away, tt.wantHome, tt.wantAway)
+			}
+		})
+	}
+}
+
+func TestHalfLine(t *testing.T) {
+	tests := []struct {
+		line float64
+		want float64
+	}{
+		{-4.5, -2.25},
+		{-5, -2.5},
+		{-3.5, -1.75},
+		{228.5, 114.25},
+		{0, 0},
+	}
+	for _, tt := range tests {
+		if got := HalfLine(tt.line); got != tt.want {
+			t.Errorf("HalfLine(%v) = %v，預期 %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+// segments 結算結果的時段（依序）
+func segments(result models.BetSettlement) []string {
+	var names []string
+	for _, seg := range result.Segments {
+		names = append(names, seg.Segment)
+	}
+	return names
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSettleSegments(t *testing.T) {
+	regulation := models.PeriodScores{
+		HomePeriods: []int{25, 26, 24, 26},
+		AwayPeriods: []int{20, 30, 25, 24},
+	}
+	overtime := models.PeriodScores{
+		HomePeriods: []int{25, 25, 25, 25, 10, 12},
+		AwayPeriods: []int{25, 25, 25, 25, 10, 8},
+	}
+	// 第四節打完平手、延長賽尚未開打
+	tiedLive := models.PeriodScores{
+		HomePeriods: []int{25, 25, 25, 25, 0},
+		AwayPeriods: []int{25, 25, 25, 25, 0},
+	}
+
+	tests := []struct {
+		name      string
+		settle    func() models.BetSettlement
+		want      []string
+		wantScore map[string][2]int // 時段 -> 主隊、客隊得分
+	}{
+		{
+			name:   "已結束的比賽",
+			settle: func() models.BetSettlement { return Settle(regulation, nil) },
+			want:   []string{models.SegmentFullGame, models.SegmentFirstHalf, models.SegmentQ1, models.SegmentQ2, models.SegmentQ3, models.SegmentQ4},
+			wantScore: map[string][2]int{
+				models.SegmentFullGame:  {101, 99},
+				models.SegmentFirstHalf: {51, 50},
+			},
+		},
+		{
+			name:   "延長賽",
+			settle: func() models.BetSettlement { return Settle(overtime, nil) },
+			want:   []string{models.SegmentFullGame, models.SegmentFirstHalf, models.SegmentQ1, models.SegmentQ2, models.SegmentQ3, models.SegmentQ4, models.SegmentOvertime},
+			wantScore: map[string][2]int{
+				models.SegmentFullGame: {122, 118},
+				models.SegmentOvertime: {22, 18},
+			},
+		},
+		{
+			name:   "進行中打完一節",
+			settle: func() models.BetSettlement { return SettleCompleted(regulation, nil, 1) },
+			want:   []string{models.SegmentQ1},
+		},
+		{
+			name:   "進行中打完上半場",
+			settle: func() models.BetSettlement { return SettleCompleted(regulation, nil, 2) },
+			want:   []string{models.SegmentFirstHalf, models.SegmentQ1, models.SegmentQ2},
+		},
+		{
+			name:   "進行中第四節時鐘歸零不結算全場",
+			settle: func() models.BetSettlement { return SettleCompleted(tiedLive, nil, 4) },
+			want:   []string{models.SegmentFirstHalf, models.SegmentQ1, models.SegmentQ2, models.SegmentQ3, models.SegmentQ4},
+		},
+		{
+			name:   "進行中延長賽時鐘歸零不結算全場與延長賽",
+			settle: func() models.BetSettlement { return SettleCompleted(overtime, nil, 6) },
+			want:   []string{models.SegmentFirstHalf, models.SegmentQ1, models.SegmentQ2, models.SegmentQ3, models.SegmentQ4},
+		},
+		{
+			name:   "尚未打完任何一節",
+			settle: func() models.BetSettlement { return SettleCompleted(regulation, nil, 0) },
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.settle()
+			if got := segments(result); !equalStrings(got, tt.want) {
+				t.Fatalf("時段 = %v，預期 %v", got, tt.want)
+			}
+			for _, seg := range result.Segments {
+				want, ok := tt.wantScore[seg.Segment]
+				if ok && (seg.HomeScore != want[0] || seg.AwayScore != want[1]) {
+					t.Errorf("%s 比分 = %d-%d，預期 %d-%d", seg.Segment, seg.HomeScore, seg.AwayScore, want[0], want[1])
+				}
+			}
+		})
+	}
+}
+
+func TestSettleLines(t *testing.T) {
+	ps := models.PeriodScores{
+		HomePeriods: []int{25, 26, 24, 26},
+		AwayPeriods: []int{20, 30, 25, 24},
+	}
+	lines := Lines{
+		models.SegmentFullGame:  {HomeSpread: ptr(-1.5), Total: ptr(200.5)},
+		models.SegmentFirstHalf: {HomeSpread: ptr(-0.75), Total: ptr(101), SpreadDerived: true},
+	}
+
+	tests := []struct {
+		segment    string
+		wantML     models.BetResult
+		wantSpread models.BetResult
+		wantOver   models.BetResult
+		derived    bool
+	}{
+		{models.SegmentFullGame, models.BetWin, models.BetWin, models.BetLoss, false},     // 101-99，共 200 分
+		{models.SegmentFirstHalf, models.BetWin, models.BetHalfWin, models.BetPush, true}, // 51-50，共 101 分
+		{models.SegmentQ2, models.BetLoss, "", "", false},                                 // 各節沒有盤口
+	}
+
+	result := Settle(ps, lines)
+	for _, tt := range tests {
+		t.Run(tt.segment, func(t *testing.T) {
+			seg, ok := result.Segment(tt.segment)
+			if !ok {
+				t.Fatalf("沒有 %s 的結算", tt.segment)
+			}
+			if seg.Moneyline.Home != tt.wantML {
+				t.Errorf("獨贏 = %s，預期 %s", seg.Moneyline.Home, tt.wantML)
+			}
+			if tt.wantSpread == "" {
+				if seg.Spread != nil || seg.Total != nil {
+					t.Errorf("沒有盤口的時段不應結算讓分與大小分")
+				}
+				return
+			}
+			if seg.Spread == nil || seg.Spread.Home != tt.wantSpread || seg.Spread.Derived != tt.derived {
+				t.Errorf("讓分 = %+v，預期 %s（估算 %v）", seg.Spread, tt.wantSpread, tt.derived)
+			}
+			if seg.Total == nil || seg.Total.Over != tt.wantOver {
+				t.Errorf("大小分 = %+v，預期大分 %s", seg.Total, tt.wantOver)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+// Package slate 決定目前應顯示哪一個 NBA 比賽日（slate）
+//
+// NBA 比賽日以美東日期為準，但美東午夜時西岸的比賽往往還在進行，
+// 因此前一個比賽日的最後一場比賽結束（再加上保留時間）之後，才切換到新的比賽日。
+// 所有時區的使用者都會得到同一個比賽日，CLI、server 與是否需要抓盤口的判斷共用此結果。
+package slate
+
+import (
+	"context"
+	"fmt"
+	"nba-scanner/internal/gametime"
+	"nba-scanner/internal/models"
+	"time"
+)
+
+// 預設值
+const (
+	DefaultHold       = time.Hour                    // 前一個比賽日打完後繼續顯示的時間（方便查看最終比分）
+	DefaultGameLength = 2*time.Hour + 30*time.Minute // 一場比賽從開賽到結束的預估時間
+	maxGameLength     = 4 * time.Hour                // 賽程狀態仍為進行中時，最多視為進行到開賽後 4 小時
+
+	// fallbackRollover 無法取得賽程時，美東午夜後多久切換比賽日（約等於最晚一場比賽結束）
+	fallbackRollover = 90 * time.Minute
+)
+
+// Config 比賽日判斷設定
+type Config struct {
+	Hold       time.Duration // 前一個比賽日最後一場結束後，繼續顯示的時間（通常為 DefaultHold）
+	GameLength time.Duration // 預估單場比賽長度（0 使用預設值）
+	Override   string        // 固定比賽日 "2025-10-21"（空字串表示自動判斷，用於重播錄製資料或除錯）
+}
+
+// GamesFunc 取得指定 NBA 比賽日的賽程
+type GamesFunc func(ctx context.Context, day time.Time) ([]models.ScheduledGame, error)
+
+// Resolver 將目前時間對應到 NBA 比賽日
+type Resolver struct {
+	cfg      Config
+	override time.Time
+	games    GamesFunc
+	now      func() time.Time
+}
+
+// New 建立比賽日判斷器，games 為 nil 時只依美東時間估算
+func New(cfg Config, games GamesFunc) (*Resolver, error) {
+	if cfg.Hold < 0 {
+		cfg.Hold = 0
+	}
+	if cfg.GameLength <= 0 {
+		cfg.GameLength = DefaultGameLength
+	}
+
+	r := &Resolver{cfg: cfg, games: games, now: time.Now}
+	if cfg.Override != "" {
+		day, err := time.ParseInLocation("2006-01-02", cfg.Override, gametime.Eastern)
+		if err != nil {
+			return nil, fmt.Errorf("比賽日格式錯誤（應為 YYYY-MM-DD）: %q", cfg.Override)
+		}
+		r.override = day
+	}
+	return r, nil
+}
+
+// Today 目前應顯示的 NBA 比賽日（美東日期的 00:00）
+func (r *Resolver) Today(ctx context.Context) time.Time {
+	return r.At(ctx, r.now())
+}
+
+// At 指定時間應顯示的 NBA 比賽日
+func (r *Resolver) At(ctx context.Context, now time.Time) time.Time {
+	if !r.override.IsZero() {
+		return r.override
+	}
+
+	now = now.In(gametime.Eastern)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, gametime.Eastern)
+	previous := today.AddDate(0, 0, -1)
+
+	if now.Before(r.rollover(ctx, previous, now)) {
+		return previous
+	}
+	return today
+}
+
+// rollover 前一個比賽日切換到下一個比賽日的時間
+func (r *Resolver) rollover(ctx context.Context, day time.Time, now time.Time) time.Time {
+	fallback := day.AddDate(0, 0, 1).Add(fallbackRollover + r.cfg.Hold)
+	if r.games == nil {
+		return fallback
+	}
+	games, err := r.games(ctx, day)
+	if err != nil {
+		return fallback
+	}
+
+	var end time.Time
+	for _, game := range games {
+		start, err := gametime.GameStart(game.GameDateTimeUTC, game.GameDateTimeEst)
+		if err != nil {
+			continue
+		}
+		gameEnd := start.Add(r.cfg.GameLength)
+
+		// 賽程顯示仍在進行中（延長賽、延遲開賽），視為至少進行到現在
+		if game.GameStatus == 2 && now.After(gameEnd) && now.Before(start.Add(maxGameLength)) {
+			gameEnd = now.Add(time.Minute)
+		}
+		if gameEnd.After(end) {
+			end = gameEnd
+		}
+	}
+	// 前一個比賽日沒有比賽，午夜就切換
+	if end.IsZero() {
+		return day.AddDate(0, 0, 1)
+	}
+	return end.Add(r.cfg.Hold)
+}
+
+// IsNear 指定比賽日是否為目前比賽日的前一天、當天或隔天（這些比賽日需要抓取盤口）
+func (r *Resolver) IsNear(ctx context.Context, day time.Time) bool {
+	today := r.Today(ctx)
+	target := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, gametime.Eastern)
+	return !target.Before(today.AddDate(0, 0, -1)) && !target.After(today.AddDate(0, 0, 1))
+}
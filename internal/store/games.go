@@ -0,0 +1,110 @@
+package store
+
+import (
+	"encoding/json"
+	"nba-scanner/internal/models"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// FinalScore 已結束比賽的最終比分與各節比分
+type FinalScore struct {
+	GameID      string               `json:"gameId"`
+	HomeTeamID  int                  `json:"homeTeamId"`
+	AwayTeamID  int                  `json:"awayTeamId"`
+	HomeScore   int                  `json:"homeScore"`
+	AwayScore   int                  `json:"awayScore"`
+	HomePeriods []models.PeriodScore `json:"homePeriods"`
+	AwayPeriods []models.PeriodScore `json:"awayPeriods"`
+	RecordedAt  time.Time            `json:"recordedAt"`
+}
+
+// SaveScheduledGames 寫入（覆蓋）賽程中的比賽
+func (s *Store) SaveScheduledGames(games []models.ScheduledGame) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketGames)
+		for _, game := range games {
+			if err := put(b, game.GameID, game); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Game 以比賽 ID 取得賽程資料
+func (s *Store) Game(gameID string) (models.ScheduledGame, bool, error) {
+	var game models.ScheduledGame
+	var found bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		found, err = get(tx.Bucket(bucketGames), gameID, &game)
+		return err
+	})
+	return game, found, err
+}
+
+// GamesOn 取得指定 NBA 比賽日（美東日期，"2006-01-02"）的比賽
+func (s *Store) GamesOn(date string) ([]models.ScheduledGame, error) {
+	var games []models.ScheduledGame
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketGames).ForEach(func(k, v []byte) error {
+			var game models.ScheduledGame
+			if err := json.Unmarshal(v, &game); err != nil {
+				return err
+			}
+			// GameDateTimeEst 前 10 碼即為 NBA 比賽日
+			if strings.HasPrefix(game.GameDateTimeEst, date) {
+				games = append(games, game)
+			}
+			return nil
+		})
+	})
+	return games, err
+}
+
+// SaveFinalScore 寫入已結束比賽的比分
+func (s *Store) SaveFinalScore(final FinalScore) error {
+	if final.RecordedAt.IsZero() {
+		final.RecordedAt = time.Now()
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return put(tx.Bucket(bucketFinals), final.GameID, final)
+	})
+}
+
+// FinalScore 取得已結束比賽的比分
+func (s *Store) FinalScore(gameID string) (FinalScore, bool, error) {
+	var final FinalScore
+	var found bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		found, err = get(tx.Bucket(bucketFinals), gameID, &final)
+		return err
+	})
+	return final, found, err
+}
+
+// SaveFinalBoxscore 寫入已結束比賽的完整 boxscore（比賽結束後不會再變動）
+func (s *Store) SaveFinalBoxscore(boxscore *models.BoxscoreResponse) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return put(tx.Bucket(bucketBoxscores), boxscore.Game.GameID, boxscore)
+	})
+}
+
+// FinalBoxscore 取得已結束比賽的 boxscore
+func (s *Store) FinalBoxscore(gameID string) (*models.BoxscoreResponse, bool, error) {
+	var boxscore models.BoxscoreResponse
+	var found bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		found, err = get(tx.Bucket(bucketBoxscores), gameID, &boxscore)
+		return err
+	})
+	if !found || err != nil {
+		return nil, found, err
+	}
+	return &boxscore, true, nil
+}
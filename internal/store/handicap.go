@@ -0,0 +1,47 @@
+package store
+
+import (
+	"nba-scanner/internal/crawler"
+	"nba-scanner/internal/season"
+	"strconv"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// HandicapRows titan007 球隊盤口戰績（HandicapDetail 頁面的整季資料）
+type HandicapRows struct {
+	TeamID    int                    `json:"teamId"` // titan007 球隊 ID
+	Season    string                 `json:"season"` // 賽季（如 "2025-26"）
+	Games     []crawler.HandicapGame `json:"games"`
+	FetchedAt time.Time              `json:"fetchedAt"`
+}
+
+// handicapKey 盤口戰績的 key："2025-26/<teamID>"
+func handicapKey(teamID int, sn season.Season) string {
+	return sn.String() + "/" + strconv.Itoa(teamID)
+}
+
+// SaveHandicapRows 寫入（覆蓋）球隊在指定賽季的盤口戰績
+func (s *Store) SaveHandicapRows(teamID int, sn season.Season, games []crawler.HandicapGame, at time.Time) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return put(tx.Bucket(bucketHandicap), handicapKey(teamID, sn), HandicapRows{
+			TeamID:    teamID,
+			Season:    sn.String(),
+			Games:     games,
+			FetchedAt: at,
+		})
+	})
+}
+
+// HandicapRows 取得球隊在指定賽季最後一次保存的盤口戰績
+func (s *Store) HandicapRows(teamID int, sn season.Season) (HandicapRows, bool, error) {
+	var rows HandicapRows
+	var found bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		found, err = get(tx.Bucket(bucketHandicap), handicapKey(teamID, sn), &rows)
+		return err
+	})
+	return rows, found, err
+}
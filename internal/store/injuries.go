@@ -0,0 +1,207 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"nba-scanner/internal/models"
+	"reflect"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// InjuryReport 某支球隊在某個時間點的傷兵名單
+type InjuryReport struct {
+	Team       string          `json:"team"` // ESPN 隊名
+	Injuries   []models.Injury `json:"injuries"`
+	ReportedAt time.Time       `json:"reportedAt"`
+}
+
+// SaveInjuryReports 保存一次成功抓取的各隊傷兵名單，只有名單變動時才寫入
+// 不在這次名單中的球隊（ESPN 頁面上已沒有傷兵）保存為空的名單，之後讀取的最新名單才會與頁面一致
+func (s *Store) SaveInjuryReports(injuryMap map[string][]models.Injury, at time.Time) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		latest, err := latestInjuryReports(tx)
+		if err != nil {
+			return err
+		}
+
+		for team, injuries := range injuryMap {
+			if last, found := latest[team]; found && reflect.DeepEqual(last.Injuries, injuries) {
+				continue
+			}
+			report := InjuryReport{Team: team, Injuries: injuries, ReportedAt: at}
+			if err := putInjuryReport(tx, report); err != nil {
+				return err
+			}
+		}
+
+		for team, last := range latest {
+			if _, ok := injuryMap[team]; ok || len(last.Injuries) == 0 {
+				continue
+			}
+			report := InjuryReport{Team: team, Injuries: []models.Injury{}, ReportedAt: at}
+			if err := putInjuryReport(tx, report); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// putInjuryReport 寫入一筆傷兵名單紀錄並更新該隊最新的名單
+func putInjuryReport(tx *bolt.Tx, report InjuryReport) error {
+	if err := put(tx.Bucket(bucketInjuries), timeKey(report.Team, report.ReportedAt), report); err != nil {
+		return err
+	}
+	return put(tx.Bucket(bucketInjuryNow), report.Team, report)
+}
+
+// latestInjuryReports 取得各隊最新的傷兵名單紀錄
+func latestInjuryReports(tx *bolt.Tx) (map[string]InjuryReport, error) {
+	latest := make(map[string]InjuryReport)
+	err := tx.Bucket(bucketInjuryNow).ForEach(func(k, v []byte) error {
+		var report InjuryReport
+		if err := json.Unmarshal(v, &report); err != nil {
+			return err
+		}
+		latest[report.Team] = report
+		return nil
+	})
+	return latest, err
+}
+
+// InjuryHistory 取得球隊所有的傷兵名單紀錄（依時間排序）
+func (s *Store) InjuryHistory(team string) ([]InjuryReport, error) {
+	var reports []InjuryReport
+	prefix := []byte(team + "/")
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucketInjuries).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var report InjuryReport
+			if err := json.Unmarshal(v, &report); err != nil {
+				return err
+			}
+			reports = append(reports, report)
+		}
+		return nil
+	})
+	return reports, err
+}
+
+// LatestInjuries 取得最後一次保存的傷兵名單（格式同 FetchInjuryMap，沒有傷兵的球隊不列出）與保存時間
+func (s *Store) LatestInjuries() (map[string][]models.Injury, time.Time, error) {
+	result := make(map[string][]models.Injury)
+	var reportedAt time.Time
+	err := s.db.View(func(tx *bolt.Tx) error {
+		latest, err := latestInjuryReports(tx)
+		if err != nil {
+			return err
+		}
+		for team, report := range latest {
+			if report.ReportedAt.After(reportedAt) {
+				reportedAt = report.ReportedAt
+			}
+			if len(report.Injuries) > 0 {
+				result[team] = report.Injuries
+			}
+		}
+		return nil
+	})
+	return result, reportedAt, err
+}
+
+// PruneInjuryReports 刪除指定時間之前的傷兵名單紀錄，回傳刪除的筆數
+// 各隊最新的名單另外保存在 injuries_latest，不受影響
+func (s *Store) PruneInjuryReports(before time.Time) (int, error) {
+	pruned := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketInjuries)
+		end := before.UTC().Format(keyTimeFormat)
+
+		// key 為 "球隊/時間"，需要比對每一筆的時間部分
+		var keys [][]byte
+		c := b.Cursor()
+		for k, _ := c.First(); k != nil; k, _ = c.Next() {
+			if i := bytes.LastIndexByte(k, '/'); i >= 0 && string(k[i+1:]) < end {
+				keys = append(keys, append([]byte{}, k...))
+			}
+		}
+		for _, k := range keys {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+			pruned++
+		}
+		return nil
+	})
+	return pruned, err
+}
+
+// SaveInjuryChanges 保存同一次比對產生的傷兵名單變動
+func (s *Store) SaveInjuryChanges(changes []models.InjuryChange, at time.Time) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketInjuryLog)
+		prefix := at.UTC().Format(keyTimeFormat)
+		for i, change := range changes {
+			// 序號保留同一次比對中變動的順序
+			if err := put(b, fmt.Sprintf("%s/%04d", prefix, i), change); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// InjuryChanges 取得 [from, to) 之間的傷兵名單變動（依時間排序，to 為零值表示不設上限）
+func (s *Store) InjuryChanges(from, to time.Time) ([]models.InjuryChange, error) {
+	var changes []models.InjuryChange
+	var end []byte
+	if !to.IsZero() {
+		end = []byte(to.UTC().Format(keyTimeFormat))
+	}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucketInjuryLog).Cursor()
+		for k, v := c.Seek([]byte(from.UTC().Format(keyTimeFormat))); k != nil; k, v = c.Next() {
+			if end != nil && bytes.Compare(k, end) >= 0 {
+				break
+			}
+			var change models.InjuryChange
+			if err := json.Unmarshal(v, &change); err != nil {
+				return err
+			}
+			changes = append(changes, change)
+		}
+		return nil
+	})
+	return changes, err
+}
+
+// PruneInjuryChanges 刪除指定時間之前的傷兵名單變動，回傳刪除的筆數
+func (s *Store) PruneInjuryChanges(before time.Time) (int, error) {
+	pruned := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketInjuryLog)
+		end := []byte(before.UTC().Format(keyTimeFormat))
+
+		var keys [][]byte
+		c := b.Cursor()
+		for k, _ := c.First(); k != nil && bytes.Compare(k, end) < 0; k, _ = c.Next() {
+			keys = append(keys, append([]byte{}, k...))
+		}
+		for _, k := range keys {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+			pruned++
+		}
+		return nil
+	})
+	return pruned, err
+}
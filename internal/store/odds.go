@@ -0,0 +1,279 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"nba-scanner/internal/models"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// OddsSnapshot 單一莊家在某個時間點的所有盤口
+type OddsSnapshot struct {
+	GameID     string                      `json:"gameId"`
+	HomeTeamID string                      `json:"homeTeamId"`
+	AwayTeamID string                      `json:"awayTeamId"`
+	BookID     string                      `json:"bookId"`
+	BookName   string                      `json:"bookName"`
+	CapturedAt time.Time                   `json:"capturedAt"`
+	Markets    map[string][]models.Outcome `json:"markets"` // market 名稱 -> outcomes
+}
+
+// snapshotsFromGame 將賠率 API 的單場資料拆成每個莊家一筆快照
+func snapshotsFromGame(game models.OddsGame, at time.Time) []OddsSnapshot {
+	byBook := make(map[string]*OddsSnapshot)
+	var order []string
+
+	for _, market := range game.Markets {
+		for _, book := range market.Books {
+			snap, ok := byBook[book.ID]
+			if !ok {
+				snap = &OddsSnapshot{
+					GameID:     game.GameID,
+					HomeTeamID: game.HomeTeamID,
+					AwayTeamID: game.AwayTeamID,
+					BookID:     book.ID,
+					BookName:   book.Name,
+					CapturedAt: at,
+					Markets:    make(map[string][]models.Outcome),
+				}
+				byBook[book.ID] = snap
+				order = append(order, book.ID)
+			}
+			snap.Markets[market.Name] = book.Outcomes
+		}
+	}
+
+	snapshots := make([]OddsSnapshot, 0, len(order))
+	for _, id := range order {
+		snapshots = append(snapshots, *byBook[id])
+	}
+	return snapshots
+}
+
+// SaveOddsSnapshot 保存單場比賽的賠率快照
+// 只有和該莊家上一筆快照不同時才寫入，回傳實際寫入的筆數
+func (s *Store) SaveOddsSnapshot(game models.OddsGame, at time.Time) (int, error) {
+	saved := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketOdds)
+		index := tx.Bucket(bucketOddsTime)
+		latest := tx.Bucket(bucketOddsLast)
+		for _, snap := range snapshotsFromGame(game, at) {
+			prefix := snap.GameID + "/" + snap.BookID
+
+			var last OddsSnapshot
+			found, err := get(latest, prefix, &last)
+			if err != nil {
+				return err
+			}
+			if found && reflect.DeepEqual(last.Markets, snap.Markets) {
+				continue
+			}
+
+			if err := put(b, timeKey(prefix, at), snap); err != nil {
+				return err
+			}
+			if err := index.Put([]byte(oddsTimeKey(prefix, at)), []byte{}); err != nil {
+				return err
+			}
+			if err := put(latest, prefix, snap); err != nil {
+				return err
+			}
+			saved++
+		}
+		return nil
+	})
+	return saved, err
+}
+
+// oddsTimeKey 產生時間索引的 key（"時間/gameID/bookID"）
+func oddsTimeKey(prefix string, t time.Time) string {
+	return t.UTC().Format(keyTimeFormat) + "/" + prefix
+}
+
+// splitOddsTimeKey 由時間索引的 key 取得 gameID 與 odds_snapshots 中對應的 key
+func splitOddsTimeKey(k []byte) (gameID, snapshotKey string, ok bool) {
+	at, prefix, ok := strings.Cut(string(k), "/")
+	if !ok {
+		return "", "", false
+	}
+	gameID, _, ok = strings.Cut(prefix, "/")
+	return gameID, prefix + "/" + at, ok
+}
+
+// PruneOddsHistory 刪除指定時間之前的賠率快照，回傳刪除的筆數
+// 各莊家最新的快照會保留（歷史比賽的收盤大小分仍需要）
+func (s *Store) PruneOddsHistory(before time.Time) (int, error) {
+	pruned := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketOdds)
+		index := tx.Bucket(bucketOddsTime)
+		end := []byte(before.UTC().Format(keyTimeFormat))
+
+		// 先收集再刪除，避免邊走訪 cursor 邊刪除時跳過資料
+		var keys [][]byte
+		c := index.Cursor()
+		for k, _ := c.First(); k != nil && bytes.Compare(k, end) < 0; k, _ = c.Next() {
+			keys = append(keys, append([]byte{}, k...))
+		}
+
+		for _, k := range keys {
+			if _, snapshotKey, ok := splitOddsTimeKey(k); ok {
+				if err := b.Delete([]byte(snapshotKey)); err != nil {
+					return err
+				}
+			}
+			if err := index.Delete(k); err != nil {
+				return err
+			}
+			pruned++
+		}
+		return nil
+	})
+	return pruned, err
+}
+
+// OddsHistory 取得單場比賽所有莊家的賠率快照（依時間排序）
+func (s *Store) OddsHistory(gameID string) ([]OddsSnapshot, error) {
+	var snapshots []OddsSnapshot
+	prefix := []byte(gameID + "/")
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucketOdds).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var snap OddsSnapshot
+			if err := json.Unmarshal(v, &snap); err != nil {
+				return err
+			}
+			snapshots = append(snapshots, snap)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].CapturedAt.Before(snapshots[j].CapturedAt)
+	})
+	return snapshots, nil
+}
+
+// LatestOdds 以各莊家最新的快照組回賠率 API 格式
+func (s *Store) LatestOdds(gameID string) (models.OddsGame, bool, error) {
+	var snapshots []OddsSnapshot
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		snapshots, err = latestSnapshots(tx, gameID)
+		return err
+	})
+	if err != nil || len(snapshots) == 0 {
+		return models.OddsGame{}, false, err
+	}
+	return buildOddsGame(snapshots), true, nil
+}
+
+// RecentOddsGames 取得指定時間之後有賠率快照的比賽（各莊家取最新一筆）
+// 用於 odds_todaysGames.json 換日後仍能顯示前一天的盤口
+func (s *Store) RecentOddsGames(since time.Time) ([]models.OddsGame, error) {
+	var games []models.OddsGame
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		// 時間索引從 since 開始往後找，不需要走訪所有快照
+		recent := make(map[string]bool)
+		c := tx.Bucket(bucketOddsTime).Cursor()
+		for k, _ := c.Seek([]byte(since.UTC().Format(keyTimeFormat))); k != nil; k, _ = c.Next() {
+			if gameID, _, ok := splitOddsTimeKey(k); ok {
+				recent[gameID] = true
+			}
+		}
+
+		for gameID := range recent {
+			snapshots, err := latestSnapshots(tx, gameID)
+			if err != nil {
+				return err
+			}
+			if len(snapshots) > 0 {
+				games = append(games, buildOddsGame(snapshots))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(games, func(i, j int) bool {
+		return games[i].GameID < games[j].GameID
+	})
+	return games, nil
+}
+
+// latestSnapshots 取得單場比賽各莊家最新的快照
+func latestSnapshots(tx *bolt.Tx, gameID string) ([]OddsSnapshot, error) {
+	var snapshots []OddsSnapshot
+	prefix := []byte(gameID + "/")
+
+	c := tx.Bucket(bucketOddsLast).Cursor()
+	for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+		var snap OddsSnapshot
+		if err := json.Unmarshal(v, &snap); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snap)
+	}
+	return snapshots, nil
+}
+
+// buildOddsGame 以各莊家最新的快照組回 models.OddsGame
+func buildOddsGame(snapshots []OddsSnapshot) models.OddsGame {
+	latest := make(map[string]OddsSnapshot)
+	var books []string
+	for _, snap := range snapshots {
+		prev, ok := latest[snap.BookID]
+		if !ok {
+			books = append(books, snap.BookID)
+		}
+		if !ok || !snap.CapturedAt.Before(prev.CapturedAt) {
+			latest[snap.BookID] = snap
+		}
+	}
+
+	game := models.OddsGame{
+		GameID:     snapshots[0].GameID,
+		HomeTeamID: snapshots[0].HomeTeamID,
+		AwayTeamID: snapshots[0].AwayTeamID,
+	}
+
+	marketIndex := make(map[string]int)
+	for _, bookID := range books {
+		snap := latest[bookID]
+
+		names := make([]string, 0, len(snap.Markets))
+		for name := range snap.Markets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			idx, ok := marketIndex[name]
+			if !ok {
+				idx = len(game.Markets)
+				marketIndex[name] = idx
+				game.Markets = append(game.Markets, models.OddsMarket{Name: name})
+			}
+			game.Markets[idx].Books = append(game.Markets[idx].Books, models.Bookmaker{
+				ID:       snap.BookID,
+				Name:     snap.BookName,
+				Outcomes: snap.Markets[name],
+			})
+		}
+	}
+
+	return game
+}
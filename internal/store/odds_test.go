@@ -0,0 +1,173 @@
+package store
+
+import (
+	"nba-scanner/internal/models"
+	"slices"
+	"testing"
+	"time"
+)
+
+// oddsGame 建立只有讓分盤的測試資料，lines 為各莊家的主隊讓分
+func oddsGame(gameID string, lines map[string]string) models.OddsGame {
+	market := models.OddsMarket{Name: "spread"}
+	for _, bookID := range []string{"sr:book:818", "sr:book:1"} {
+		line, ok := lines[bookID]
+		if !ok {
+			continue
+		}
+		market.Books = append(market.Books, models.Bookmaker{
+			ID:   bookID,
+			Name: bookID,
+			Outcomes: []models.Outcome{
+				{Type: "home", Odds: "1.91", Spread: line},
+				{Type: "away", Odds: "1.91", Spread: negate(line)},
+			},
+		})
+	}
+	return models.OddsGame{GameID: gameID, HomeTeamID: "sr:competitor:1", AwayTeamID: "sr:competitor:2", Markets: []models.OddsMarket{market}}
+}
+
+func negate(line string) string {
+	if line[0] == '-' {
+		return "+" + line[1:]
+	}
+	return "-" + line[1:]
+}
+
+func TestSaveOddsSnapshotDedupe(t *testing.T) {
+	t0 := time.Date(2026, 1, 14, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		snapshots []map[string]string // 依序保存的各莊家讓分
+		wantSaved []int
+		wantLines map[string]string // 各莊家最新的主隊讓分
+		wantCount int               // OddsHistory 的快照數
+	}{
+		{
+			name:      "相同盤口不重複寫入",
+			snapshots: []map[string]string{{"sr:book:818": "-4.5"}, {"sr:book:818": "-4.5"}},
+			wantSaved: []int{1, 0},
+			wantLines: map[string]string{"sr:book:818": "-4.5"},
+			wantCount: 1,
+		},
+		{
+			name:      "盤口變動才新增一筆",
+			snapshots: []map[string]string{{"sr:book:818": "-4.5"}, {"sr:book:818": "-5.5"}, {"sr:book:818": "-5.5"}},
+			wantSaved: []int{1, 1, 0},
+			wantLines: map[string]string{"sr:book:818": "-5.5"},
+			wantCount: 2,
+		},
+		{
+			name:      "各莊家分開比對",
+			snapshots: []map[string]string{{"sr:book:818": "-4.5", "sr:book:1": "-4"}, {"sr:book:818": "-4.5", "sr:book:1": "-5"}},
+			wantSaved: []int{2, 1},
+			wantLines: map[string]string{"sr:book:818": "-4.5", "sr:book:1": "-5"},
+			wantCount: 3,
+		},
+		{
+			name:      "莊家暫時沒有報價時保留最後的盤口",
+			snapshots: []map[string]string{{"sr:book:818": "-4.5", "sr:book:1": "-4"}, {"sr:book:818": "-3.5"}},
+			wantSaved: []int{2, 1},
+			wantLines: map[string]string{"sr:book:818": "-3.5", "sr:book:1": "-4"},
+			wantCount: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := openTestStore(t)
+			for i, lines := range tt.snapshots {
+				saved, err := s.SaveOddsSnapshot(oddsGame("g1", lines), t0.Add(time.Duration(i)*time.Minute))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if saved != tt.wantSaved[i] {
+					t.Errorf("第 %d 次保存寫入 %d 筆，預期 %d", i+1, saved, tt.wantSaved[i])
+				}
+			}
+
+			history, err := s.OddsHistory("g1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(history) != tt.wantCount {
+				t.Errorf("OddsHistory() 有 %d 筆，預期 %d", len(history), tt.wantCount)
+			}
+
+			game, found, err := s.LatestOdds("g1")
+			if err != nil || !found {
+				t.Fatalf("LatestOdds() found=%v err=%v", found, err)
+			}
+			for bookID, want := range tt.wantLines {
+				if got := game.GetSpread(bookID).HomeSpread; got != want {
+					t.Errorf("%s 最新讓分 = %q，預期 %q", bookID, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPruneOddsHistory(t *testing.T) {
+	t0 := time.Date(2026, 1, 14, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		cutoff     time.Time
+		wantPruned int
+		wantLeft   int // g1 剩下的快照數
+		wantRecent []string
+	}{
+		{"截止時間早於所有快照", t0, 0, 3, []string{"g1", "g2"}},
+		// 截止時間不包含在刪除範圍內
+		{"剛好等於第二筆的時間", t0.Add(time.Hour), 1, 2, []string{"g1", "g2"}},
+		{"刪除 g1 全部的舊快照", t0.Add(3 * time.Hour), 3, 0, []string{"g2"}},
+		{"全部刪除", t0.Add(24 * time.Hour), 4, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := openTestStore(t)
+			for i, line := range []string{"-4.5", "-5.5", "-6.5"} {
+				if _, err := s.SaveOddsSnapshot(oddsGame("g1", map[string]string{"sr:book:818": line}), t0.Add(time.Duration(i)*time.Hour)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if _, err := s.SaveOddsSnapshot(oddsGame("g2", map[string]string{"sr:book:818": "+2"}), t0.Add(5*time.Hour)); err != nil {
+				t.Fatal(err)
+			}
+
+			pruned, err := s.PruneOddsHistory(tt.cutoff)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pruned != tt.wantPruned {
+				t.Errorf("PruneOddsHistory() = %d，預期 %d", pruned, tt.wantPruned)
+			}
+			history, err := s.OddsHistory("g1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(history) != tt.wantLeft {
+				t.Errorf("g1 剩下 %d 筆快照，預期 %d", len(history), tt.wantLeft)
+			}
+
+			// 各莊家最新的盤口不會被刪除
+			game, found, err := s.LatestOdds("g1")
+			if err != nil || !found || game.GetSpread("sr:book:818").HomeSpread != "-6.5" {
+				t.Errorf("清除後 LatestOdds() = %+v, %v, %v，預期保留 -6.5", game, found, err)
+			}
+
+			// RecentOddsGames 以時間索引找出最近有快照的比賽
+			recent, err := s.RecentOddsGames(t0.Add(time.Hour))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, game := range recent {
+				ids = append(ids, game.GameID)
+			}
+			if !slices.Equal(ids, tt.wantRecent) {
+				t.Errorf("RecentOddsGames() = %v，預期 %v", ids, tt.wantRecent)
+			}
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package store
+
+import (
+	"context"
+	"log"
+	"nba-scanner/internal/crawler"
+	"nba-scanner/internal/models"
+	"nba-scanner/internal/season"
+	"sync"
+	"time"
+)
+
+// recentOddsWindow odds_todaysGames.json 換日後，從資料庫補回多久內的盤口
+const recentOddsWindow = 36 * time.Hour
+
+// injuryReportRetention 傷兵名單紀錄保留的時間
+const injuryReportRetention = 14 * 24 * time.Hour
+
+// Persist 包裝資料來源，把每次抓到的資料寫入資料庫
+// 上游失敗時，傷兵與 titan007 盤口會改用資料庫中最後一次的資料
+func Persist(src *crawler.Sources, s *Store) *crawler.Sources {
+	schedule := &persistSchedule{ScheduleSource: src.Schedule, store: s}
+	persisted := &crawler.Sources{
+		Schedule: schedule,
+		Odds:     &persistOdds{OddsSource: src.Odds, store: s},
+		Boxscore: crawler.WrapBoxscoreUpstream(src.Boxscore, func(upstream crawler.BoxscoreSource) crawler.BoxscoreSource {
+			return &persistBoxscore{BoxscoreSource: upstream, store: s, rosterSaved: make(map[string]int)}
+		}),
+		Injury:   &persistInjury{InjurySource: src.Injury, store: s},
+		Handicap: &persistHandicap{HandicapSource: src.Handicap, store: s},
+		Roster:   &persistRoster{RosterSource: src.Roster, store: s},
+		Monitor:  src.Monitor,
+	}
+	persisted.CarrySeasons(src)
+	return persisted
+}
+
+// persistSchedule 保存整季賽程
+type persistSchedule struct {
+	crawler.ScheduleSource
+	store *Store
+}
+
+// FetchFullSchedule 抓取並保存整季賽程
+func (p *persistSchedule) FetchFullSchedule(ctx context.Context) (*models.FullSchedule, error) {
+	schedule, err := p.ScheduleSource.FetchFullSchedule(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.save(schedule)
+	return schedule, nil
+}
+
+// FetchSeasonSchedule 抓取並保存指定賽季的賽程
+func (p *persistSchedule) FetchSeasonSchedule(ctx context.Context, sn season.Season) (*models.FullSchedule, error) {
+	schedule, err := p.ScheduleSource.FetchSeasonSchedule(ctx, sn)
+	if err != nil {
+		return nil, err
+	}
+	p.save(schedule)
+	return schedule, nil
+}
+
+// FetchFullScheduleConditional 上游支援條件式請求時沿用，否則退回完整抓取
+func (p *persistSchedule) FetchFullScheduleConditional(ctx context.Context, v crawler.CacheValidators) (*models.FullSchedule, crawler.CacheValidators, error) {
+	cs, ok := p.ScheduleSource.(crawler.ConditionalScheduleSource)
+	if !ok {
+		schedule, err := p.FetchFullSchedule(ctx)
+		return schedule, v, err
+	}
+
+	schedule, v, err := cs.FetchFullScheduleConditional(ctx, v)
+	if err != nil || schedule == nil {
+		return schedule, v, err
+	}
+	p.save(schedule)
+	return schedule, v, nil
+}
+
+// save 寫入所有比賽
+func (p *persistSchedule) save(schedule *models.FullSchedule) {
+	var games []models.ScheduledGame
+	for _, gameDate := range schedule.LeagueSchedule.GameDates {
+		games = append(games, gameDate.Games...)
+	}
+	if err := p.store.SaveScheduledGames(games); err != nil {
+		log.Printf("保存賽程失敗: %v", err)
+	}
+}
+
+// persistOdds 保存賠率快照
+type persistOdds struct {
+	crawler.OddsSource
+	store *Store
+}
+
+// FetchOdds 抓取賠率、保存快照，並補上已從今日賠率移除的近期比賽
+func (p *persistOdds) FetchOdds(ctx context.Context) (*models.NBAOdds, error) {
+	odds, err := p.OddsSource.FetchOdds(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	live := make(map[string]bool, len(odds.Games))
+	changed := 0
+	for _, game := range odds.Games {
+		live[game.GameID] = true
+		n, err := p.store.SaveOddsSnapshot(game, now)
+		if err != nil {
+			log.Printf("保存賠率快照失敗 (GameID: %s): %v", game.GameID, err)
+			continue
+		}
+		changed += n
+	}
+	if changed > 0 {
+		log.Printf("賠率快照：%d 筆盤口變動", changed)
+	}
+
+	recent, err := p.store.RecentOddsGames(now.Add(-recentOddsWindow))
+	if err != nil {
+		log.Printf("讀取歷史賠率失敗: %v", err)
+		return odds, nil
+	}
+	for _, game := range recent {
+		if !live[game.GameID] {
+			odds.Games = append(odds.Games, game)
+		}
+	}
+
+	return odds, nil
+}
+
+// ClosingTotal 從保存的賠率快照取得比賽最後的大小分盤口
+func (p *persistOdds) ClosingTotal(gameID string) (float64, bool) {
+	game, found, err := p.store.LatestOdds(gameID)
+	if err != nil {
+		log.Printf("讀取歷史賠率失敗 (GameID: %s): %v", gameID, err)
+		return 0, false
+	}
+	if !found {
+		return 0, false
+	}
+
+	line := game.GetPreferredTotal(models.SupermatchBookID)
+	return line.Total, line.Found
+}
+
+// persistBoxscore 保存已結束比賽的比分與 boxscore
+// 包在 boxscore 快取之下，記憶體快取命中時不會讀寫資料庫
+type persistBoxscore struct {
+	crawler.BoxscoreSource
+	store *Store
+
+	mu          sync.Mutex
+	rosterSaved map[string]int // GameID -> 上次保存球員名單時的比賽狀態
+}
+
+// FetchBoxscore 只在記憶體快取未命中時呼叫：已結束的比賽直接從資料庫讀取，
+// 否則向上游抓取，比賽狀態改變時更新兩隊的球員名單，第一次抓到最終結果時寫入比分與 boxscore
+func (p *persistBoxscore) FetchBoxscore(ctx context.Context, gameID string) (*models.BoxscoreResponse, error) {
+	if stored, found, err := p.store.FinalBoxscore(gameID); err != nil {
+		log.Printf("讀取 boxscore 失敗 (GameID: %s): %v", gameID, err)
+	} else if found {
+		return stored, nil
+	}
+
+	boxscore, err := p.BoxscoreSource.FetchBoxscore(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.rosterChanged(gameID, boxscore.Game.GameStatus) {
+		for _, team := range []models.BoxscoreTeam{boxscore.Game.HomeTeam, boxscore.Game.AwayTeam} {
+			if err := p.store.SaveRoster(team); err != nil {
+				log.Printf("保存球員名單失敗 (TeamID: %d): %v", team.TeamID, err)
+			}
+		}
+	}
+
+	if boxscore.Game.GameStatus == 3 {
+		if err := p.store.SaveFinalBoxscore(boxscore); err != nil {
+			log.Printf("保存 boxscore 失敗 (GameID: %s): %v", gameID, err)
+		}
+		err := p.store.SaveFinalScore(FinalScore{
+			GameID:      boxscore.Game.GameID,
+			HomeTeamID:  boxscore.Game.HomeTeam.TeamID,
+			AwayTeamID:  boxscore.Game.AwayTeam.TeamID,
+			HomeScore:   boxscore.Game.HomeTeam.Score,
+			AwayScore:   boxscore.Game.AwayTeam.Score,
+			HomePeriods: boxscore.Game.HomeTeam.Periods,
+			AwayPeriods: boxscore.Game.AwayTeam.Periods,
+		})
+		if err != nil {
+			log.Printf("保存比分失敗 (GameID: %s): %v", gameID, err)
+		}
+	}
+
+	return boxscore, nil
+}
+
+// rosterChanged 回報這場比賽的球員名單是否需要保存（每場比賽每個狀態只保存一次）
+// 已結束的比賽之後會直接從資料庫讀取，不再保留記錄
+func (p *persistBoxscore) rosterChanged(gameID string, status int) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if saved, ok := p.rosterSaved[gameID]; ok && saved == status {
+		return false
+	}
+	if status == 3 {
+		delete(p.rosterSaved, gameID)
+	} else {
+		p.rosterSaved[gameID] = status
+	}
+	return true
+}
+
+// persistInjury 保存傷兵名單
+type persistInjury struct {
+	crawler.InjurySource
+	store *Store
+}
+
+// FetchInjuryMap 抓取傷兵名單，ESPN 失敗時改用最後一次保存的名單並回傳 *crawler.StaleError
+// 資料庫的名單不是新的抓取結果，呼叫端不可拿來比對傷兵變動
+func (p *persistInjury) FetchInjuryMap(ctx context.Context) (map[string][]models.Injury, error) {
+	injuryMap, err := p.InjurySource.FetchInjuryMap(ctx)
+	if err != nil {
+		stored, reportedAt, storeErr := p.store.LatestInjuries()
+		if storeErr != nil || len(stored) == 0 {
+			return nil, err
+		}
+		log.Printf("傷兵抓取失敗，改用資料庫資料: %v", err)
+		return stored, &crawler.StaleError{Err: err, FetchedAt: reportedAt}
+	}
+
+	now := time.Now()
+	if err := p.store.SaveInjuryReports(injuryMap, now); err != nil {
+		log.Printf("保存傷兵名單失敗: %v", err)
+	}
+	if _, err := p.store.PruneInjuryReports(now.Add(-injuryReportRetention)); err != nil {
+		log.Printf("清除過期傷兵名單失敗: %v", err)
+	}
+	return injuryMap, nil
+}
+
+// persistHandicap 保存 titan007 盤口戰績
+type persistHandicap struct {
+	crawler.HandicapSource
+	store *Store
+}
+
+// FetchHandicapDetail 抓取盤口戰績，titan007 失敗時改用最後一次保存的資料
+func (p *persistHandicap) FetchHandicapDetail(ctx context.Context, teamID int, sn season.Season) ([]crawler.HandicapGame, error) {
+	games, err := p.HandicapSource.FetchHandicapDetail(ctx, teamID, sn)
+	if err != nil {
+		rows, found, storeErr := p.store.HandicapRows(teamID, sn)
+		if storeErr != nil || !found {
+			return nil, err
+		}
+		log.Printf("titan007 盤口抓取失敗，改用資料庫資料 (%s): %v", rows.FetchedAt.Format(time.RFC3339), err)
+		return rows.Games, nil
+	}
+
+	if err := p.store.SaveHandicapRows(teamID, sn, games, time.Now()); err != nil {
+		log.Printf("保存盤口戰績失敗 (TeamID: %d): %v", teamID, err)
+	}
+	return games, nil
+}
+
+// persistRoster 從資料庫讀取球員名單（包含之前執行時抓過的 boxscore）
+type persistRoster struct {
+	crawler.RosterSource
+	store *Store
+}
+
+// Roster 取得球隊的球員名單，資料庫失敗時改用記憶體中的名單
+func (p *persistRoster) Roster(ctx context.Context, teamID int) (map[string]int, error) {
+	roster, err := p.store.Roster(teamID)
+	if err != nil {
+		log.Printf("讀取球員名單失敗 (TeamID: %d): %v", teamID, err)
+		return p.RosterSource.Roster(ctx, teamID)
+	}
+	return roster, nil
+}
@@ -0,0 +1,41 @@
+package store
+
+import (
+	"nba-scanner/internal/models"
+	"strconv"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// SaveRoster 將 boxscore 中的球員加入球隊的球員名單（傷兵對應 NBA personId）
+func (s *Store) SaveRoster(team models.BoxscoreTeam) error {
+	if team.TeamID == 0 || len(team.Players) == 0 {
+		return nil
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return mergeRoster(tx.Bucket(bucketRosters), team)
+	})
+}
+
+// Roster 取得球隊的球員名單（球員姓名 -> personId，沒有資料時為空的 map）
+func (s *Store) Roster(teamID int) (map[string]int, error) {
+	roster := make(map[string]int)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		_, err := get(tx.Bucket(bucketRosters), strconv.Itoa(teamID), &roster)
+		return err
+	})
+	return roster, err
+}
+
+// mergeRoster 把球員加入既有的名單（同名球員以新的 personId 為準）
+func mergeRoster(b *bolt.Bucket, team models.BoxscoreTeam) error {
+	key := strconv.Itoa(team.TeamID)
+	roster := make(map[string]int)
+	if _, err := get(b, key, &roster); err != nil {
+		return err
+	}
+	for _, p := range team.Players {
+		roster[p.Name] = p.PersonID
+	}
+	return put(b, key, roster)
+}
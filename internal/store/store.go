@@ -0,0 +1,89 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// 資料表（bbolt bucket）
+var (
+	bucketGames     = []byte("games")           // gameID -> models.ScheduledGame
+	bucketFinals    = []byte("finals")          // gameID -> FinalScore
+	bucketBoxscores = []byte("boxscores")       // gameID -> models.BoxscoreResponse（只保存已結束的比賽）
+	bucketOdds      = []byte("odds_snapshots")  // gameID/bookID/時間 -> OddsSnapshot
+	bucketOddsTime  = []byte("odds_by_time")    // 時間/gameID/bookID -> 空值（odds_snapshots 依時間排序的索引）
+	bucketOddsLast  = []byte("odds_latest")     // gameID/bookID -> 該莊家最新的 OddsSnapshot
+	bucketInjuries  = []byte("injuries")        // 球隊/時間 -> InjuryReport
+	bucketInjuryNow = []byte("injuries_latest") // 球隊 -> 該隊最新的 InjuryReport
+	bucketInjuryLog = []byte("injury_changes")  // 時間/序號 -> models.InjuryChange
+	bucketHandicap  = []byte("handicap")        // titan007 teamID -> []crawler.HandicapGame
+	bucketRosters   = []byte("rosters")         // NBA teamID -> map[球員姓名]personId
+
+	allBuckets = [][]byte{bucketGames, bucketFinals, bucketBoxscores, bucketOdds, bucketOddsTime, bucketOddsLast, bucketInjuries, bucketInjuryNow, bucketInjuryLog, bucketHandicap, bucketRosters}
+)
+
+// keyTimeFormat 時間戳記 key 格式（字典序即時間順序）
+const keyTimeFormat = "20060102T150405.000000000"
+
+// Store 內嵌式資料庫，保存賽程、比分、賠率快照、傷兵、盤口與球員名單
+type Store struct {
+	db *bolt.DB
+}
+
+// Open 開啟（或建立）資料庫檔案
+func Open(path string) (*Store, error) {
+	// 其他程序（例如正在執行的 server）持有檔案鎖時，不要無限等待
+	db, err := bolt.Open(path, 0o644, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("無法開啟資料庫 %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range allBuckets {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("無法初始化資料表: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close 關閉資料庫
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// put 以 JSON 寫入一筆資料
+func put(b *bolt.Bucket, key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return b.Put([]byte(key), data)
+}
+
+// get 讀取一筆 JSON 資料，不存在時回傳 false
+func get(b *bolt.Bucket, key string, v interface{}) (bool, error) {
+	data := b.Get([]byte(key))
+	if data == nil {
+		return false, nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// timeKey 產生 "prefix/時間" 格式的 key
+func timeKey(prefix string, t time.Time) string {
+	return prefix + "/" + t.UTC().Format(keyTimeFormat)
+}
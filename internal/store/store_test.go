@@ -0,0 +1,247 @@
+package store
+
+import (
+	"nba-scanner/internal/crawler"
+	"nba-scanner/internal/models"
+	"nba-scanner/internal/season"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// openTestStore 在暫存目錄開啟資料庫，測試結束時關閉
+func openTestStore(t *testing.T) (*Store, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.db")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s, path
+}
+
+func TestGamesRoundTrip(t *testing.T) {
+	s, _ := openTestStore(t)
+
+	games := []models.ScheduledGame{
+		{GameID: "0022500101", GameStatus: 3, GameDateTimeEst: "2025-11-01T22:30:00Z",
+			HomeTeam: models.ScheduledTeam{TeamID: 1610612747, Score: 112},
+			AwayTeam: models.ScheduledTeam{TeamID: 1610612744, Score: 108}},
+		{GameID: "0022500102", GameStatus: 1, GameDateTimeEst: "2025-11-02T19:00:00Z"},
+	}
+	if err := s.SaveScheduledGames(games); err != nil {
+		t.Fatal(err)
+	}
+
+	got, found, err := s.Game("0022500101")
+	if err != nil || !found {
+		t.Fatalf("Game() found=%v err=%v", found, err)
+	}
+	if !reflect.DeepEqual(got, games[0]) {
+		t.Errorf("Game() = %+v，預期 %+v", got, games[0])
+	}
+	if _, found, _ := s.Game("0022500999"); found {
+		t.Error("不存在的比賽不應找到")
+	}
+
+	on, err := s.GamesOn("2025-11-02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(on) != 1 || on[0].GameID != "0022500102" {
+		t.Errorf("GamesOn(2025-11-02) = %+v，預期只有 0022500102", on)
+	}
+
+	final := FinalScore{
+		GameID: "0022500101", HomeTeamID: 1610612747, AwayTeamID: 1610612744, HomeScore: 112, AwayScore: 108,
+		HomePeriods: []models.PeriodScore{{Period: 1, Score: 30}},
+		AwayPeriods: []models.PeriodScore{{Period: 1, Score: 27}},
+		RecordedAt:  time.Date(2025, 11, 2, 5, 0, 0, 0, time.UTC),
+	}
+	if err := s.SaveFinalScore(final); err != nil {
+		t.Fatal(err)
+	}
+	gotFinal, found, err := s.FinalScore("0022500101")
+	if err != nil || !found {
+		t.Fatalf("FinalScore() found=%v err=%v", found, err)
+	}
+	if !reflect.DeepEqual(gotFinal, final) {
+		t.Errorf("FinalScore() = %+v，預期 %+v", gotFinal, final)
+	}
+
+	boxscore := &models.BoxscoreResponse{Game: models.BoxscoreGame{
+		GameID: "0022500101", GameStatus: 3,
+		HomeTeam: models.BoxscoreTeam{TeamID: 1610612747, Score: 112,
+			Players: []models.BoxscorePlayer{{PersonID: 2544, Name: "LeBron James"}}},
+	}}
+	if err := s.SaveFinalBoxscore(boxscore); err != nil {
+		t.Fatal(err)
+	}
+	gotBox, found, err := s.FinalBoxscore("0022500101")
+	if err != nil || !found {
+		t.Fatalf("FinalBoxscore() found=%v err=%v", found, err)
+	}
+	if !reflect.DeepEqual(gotBox, boxscore) {
+		t.Errorf("FinalBoxscore() = %+v，預期 %+v", gotBox, boxscore)
+	}
+	if box, found, _ := s.FinalBoxscore("0022500102"); found || box != nil {
+		t.Error("沒有保存的 boxscore 不應找到")
+	}
+}
+
+func TestHandicapRoundTrip(t *testing.T) {
+	s, _ := openTestStore(t)
+
+	sn := season.Season{StartYear: 2025}
+	games := []crawler.HandicapGame{
+		{GameID: 1, GameType: 1, GameTime: "2025/10/22 08:00", HomeTeamID: 5, AwayTeamID: 9, HomeScore: 110, AwayScore: 100, Spread: -4.5, SpreadResult: 1},
+	}
+	at := time.Date(2025, 10, 23, 0, 0, 0, 0, time.UTC)
+	if err := s.SaveHandicapRows(5, sn, games, at); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, found, err := s.HandicapRows(5, sn)
+	if err != nil || !found {
+		t.Fatalf("HandicapRows() found=%v err=%v", found, err)
+	}
+	want := HandicapRows{TeamID: 5, Season: "2025-26", Games: games, FetchedAt: at}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("HandicapRows() = %+v，預期 %+v", rows, want)
+	}
+	// 不同賽季分開保存
+	if _, found, _ := s.HandicapRows(5, sn.Previous()); found {
+		t.Error("上一季沒有保存，不應找到")
+	}
+}
+
+func TestRosterMerge(t *testing.T) {
+	s, _ := openTestStore(t)
+
+	team := models.BoxscoreTeam{TeamID: 1610612747, Players: []models.BoxscorePlayer{
+		{PersonID: 2544, Name: "LeBron James"},
+		{PersonID: 1629029, Name: "Luka Doncic"},
+	}}
+	if err := s.SaveRoster(team); err != nil {
+		t.Fatal(err)
+	}
+	// 之後的 boxscore 只有部分球員時，名單會累加而不是覆蓋
+	team.Players = []models.BoxscorePlayer{{PersonID: 1630559, Name: "Austin Reaves"}}
+	if err := s.SaveRoster(team); err != nil {
+		t.Fatal(err)
+	}
+
+	roster, err := s.Roster(1610612747)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"LeBron James": 2544, "Luka Doncic": 1629029, "Austin Reaves": 1630559}
+	if !reflect.DeepEqual(roster, want) {
+		t.Errorf("Roster() = %v，預期 %v", roster, want)
+	}
+
+	empty, err := s.Roster(1610612744)
+	if err != nil || len(empty) != 0 {
+		t.Errorf("沒有資料的球隊 Roster() = %v, %v，預期空的名單", empty, err)
+	}
+}
+
+func TestInjuryReports(t *testing.T) {
+	s, _ := openTestStore(t)
+
+	t0 := time.Date(2026, 1, 14, 12, 0, 0, 0, time.UTC)
+	out := models.Injury{PlayerName: "LeBron James", PersonID: 2544, Status: models.InjuryOut, StatusText: "Out"}
+	gtd := models.Injury{PlayerName: "Stephen Curry", PersonID: 201939, Status: models.InjuryDayToDay, StatusText: "Day-To-Day"}
+
+	steps := []struct {
+		at        time.Time
+		injuryMap map[string][]models.Injury
+	}{
+		{t0, map[string][]models.Injury{"Los Angeles Lakers": {out}, "Golden State Warriors": {gtd}}},
+		// 名單相同，不新增紀錄
+		{t0.Add(time.Hour), map[string][]models.Injury{"Los Angeles Lakers": {out}, "Golden State Warriors": {gtd}}},
+		// 勇士從頁面上消失，保存為空的名單
+		{t0.Add(2 * time.Hour), map[string][]models.Injury{"Los Angeles Lakers": {out}}},
+	}
+	for _, step := range steps {
+		if err := s.SaveInjuryReports(step.injuryMap, step.at); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lakers, err := s.InjuryHistory("Los Angeles Lakers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lakers) != 1 || !lakers[0].ReportedAt.Equal(t0) {
+		t.Errorf("湖人名單沒有變動，預期 1 筆紀錄，實際 %+v", lakers)
+	}
+	warriors, err := s.InjuryHistory("Golden State Warriors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warriors) != 2 || len(warriors[1].Injuries) != 0 {
+		t.Errorf("勇士預期 2 筆紀錄且最後一筆為空，實際 %+v", warriors)
+	}
+
+	latest, reportedAt, err := s.LatestInjuries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]models.Injury{"Los Angeles Lakers": {out}}
+	if !reflect.DeepEqual(latest, want) {
+		t.Errorf("LatestInjuries() = %v，預期 %v", latest, want)
+	}
+	if !reportedAt.Equal(t0.Add(2 * time.Hour)) {
+		t.Errorf("LatestInjuries() 時間 = %v，預期 %v", reportedAt, t0.Add(2*time.Hour))
+	}
+
+	// 清除舊紀錄後，各隊最新的名單仍保留
+	pruned, err := s.PruneInjuryReports(t0.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pruned != 2 {
+		t.Errorf("PruneInjuryReports() = %d，預期 2", pruned)
+	}
+	after, _, err := s.LatestInjuries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(after, want) {
+		t.Errorf("清除後 LatestInjuries() = %v，預期 %v", after, want)
+	}
+}
+
+func TestReopen(t *testing.T) {
+	s, path := openTestStore(t)
+
+	game := models.ScheduledGame{GameID: "0022500101", GameDateTimeEst: "2025-11-01T22:30:00Z"}
+	if err := s.SaveScheduledGames([]models.ScheduledGame{game}); err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2026, 1, 14, 12, 0, 0, 0, time.UTC)
+	injuries := map[string][]models.Injury{"Los Angeles Lakers": {{PlayerName: "LeBron James", StatusText: "Out"}}}
+	if err := s.SaveInjuryReports(injuries, at); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("重新開啟既有的資料庫失敗: %v", err)
+	}
+	defer reopened.Close()
+
+	if got, found, err := reopened.Game(game.GameID); err != nil || !found || !reflect.DeepEqual(got, game) {
+		t.Errorf("重新開啟後 Game() = %+v, %v, %v", got, found, err)
+	}
+	latest, reportedAt, err := reopened.LatestInjuries()
+	if err != nil || !reflect.DeepEqual(latest, injuries) || !reportedAt.Equal(at) {
+		t.Errorf("重新開啟後 LatestInjuries() = %v, %v, %v", latest, reportedAt, err)
+	}
+}
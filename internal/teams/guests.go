@@ -0,0 +1,92 @@
+package teams
+
+import "strings"
+
+// Kind 球隊類型
+type Kind string
+
+const (
+	KindNBA           Kind = "nba"           // NBA 球隊
+	KindInternational Kind = "international" // 海外球隊（季前賽對手，如廣州龍獅）
+	KindExhibition    Kind = "exhibition"    // 表演賽隊伍（明星賽、新秀挑戰賽、G League 等）
+)
+
+// Guest 季前賽曾出現的非 NBA 球隊（只用於顯示中文隊名，沒有 titan007 盤口資料）
+type Guest struct {
+	Name    string // NBA 賽程中的完整隊名（teamCity + teamName）
+	NameTW  string
+	NameCN  string
+	Aliases []string
+}
+
+// guests 已知的海外球隊
+var guests = []Guest{
+	{Name: "Guangzhou Loong-Lions", NameTW: "廣州龍獅", NameCN: "广州龙狮", Aliases: []string{"Guangzhou Loong Lions"}},
+	{Name: "Beijing Ducks", NameTW: "北京首鋼", NameCN: "北京首钢"},
+	{Name: "Shanghai Sharks", NameTW: "上海大鯊魚", NameCN: "上海大鲨鱼"},
+	{Name: "Real Madrid", NameTW: "皇家馬德里", NameCN: "皇家马德里"},
+	{Name: "FC Barcelona", NameTW: "巴塞隆納", NameCN: "巴塞罗那", Aliases: []string{"Barcelona"}},
+	{Name: "ALBA Berlin", NameTW: "柏林阿爾巴", NameCN: "柏林阿尔巴"},
+	{Name: "ratiopharm Ulm", NameTW: "烏爾姆", NameCN: "乌尔姆"},
+	{Name: "Maccabi Ra'anana", NameTW: "拉阿納納馬卡比", NameCN: "拉阿纳纳马卡比"},
+	{Name: "Melbourne United", NameTW: "墨爾本聯", NameCN: "墨尔本联"},
+	{Name: "South East Melbourne Phoenix", NameTW: "東南墨爾本鳳凰", NameCN: "东南墨尔本凤凰"},
+	{Name: "Adelaide 36ers", NameTW: "阿德雷德36人", NameCN: "阿德莱德36人"},
+	{Name: "Perth Wildcats", NameTW: "伯斯野貓", NameCN: "珀斯野猫"},
+	{Name: "Sydney Kings", NameTW: "雪梨國王", NameCN: "悉尼国王"},
+	{Name: "New Zealand Breakers", NameTW: "紐西蘭破壞者", NameCN: "新西兰破坏者"},
+	{Name: "Flamengo", NameTW: "佛朗明哥", NameCN: "弗拉门戈"},
+}
+
+// exhibitionKeywords 表演賽隊伍名稱中常見的字
+var exhibitionKeywords = []string{
+	"all-star", "all star", "rising stars", "g league", "ignite", "global stars", "young stars", "'s ogs",
+}
+
+// guestByAlias 海外球隊的別名索引
+var guestByAlias = make(map[string]int)
+
+func init() {
+	for i, g := range guests {
+		for _, name := range append([]string{g.Name, g.NameTW, g.NameCN}, g.Aliases...) {
+			guestByAlias[normalize(name)] = i
+		}
+	}
+}
+
+// IsNBA 是否為 NBA 球隊
+func IsNBA(id int) bool {
+	_, ok := byID[id]
+	return ok
+}
+
+// LookupGuest 以隊名查詢已知的海外球隊
+func LookupGuest(name string) (Guest, bool) {
+	i, ok := guestByAlias[normalize(name)]
+	if !ok {
+		return Guest{}, false
+	}
+	return guests[i], true
+}
+
+// Classify 判斷球隊類型；不在 NBA 球隊表的隊伍，依名稱分為表演賽隊伍或海外球隊
+func Classify(id int, name string) Kind {
+	if IsNBA(id) {
+		return KindNBA
+	}
+	// 沒有 teamId 時改用隊名判斷
+	if _, ok := Lookup(name); ok && id == 0 {
+		return KindNBA
+	}
+
+	lower := normalize(name)
+	if strings.HasPrefix(lower, "team ") {
+		return KindExhibition
+	}
+	for _, keyword := range exhibitionKeywords {
+		if strings.Contains(lower, keyword) {
+			return KindExhibition
+		}
+	}
+	return KindInternational
+}
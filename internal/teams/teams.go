@@ -0,0 +1,158 @@
+// Package teams NBA 球隊資料表（以 NBA teamId 為主鍵）
+//
+// 各資料來源對同一支球隊的寫法不同（NBA API 為 "LA Clippers"、titan007 為 "Los Angeles Clippers"、
+// 舊版資料有 "Portland Trail_blazers"），一律透過此套件以 teamId 或別名查詢，不再各自修正隊名。
+package teams
+
+import (
+	"sort"
+	"strings"
+)
+
+// Team 單一球隊的各種名稱與外部 ID
+type Team struct {
+	ID         int      // NBA teamId（1610612747）
+	Tricode    string   // 三碼縮寫（LAL）
+	City       string   // NBA API 的 teamCity（LA、Los Angeles）
+	Nickname   string   // NBA API 的 teamName（Clippers、Trail Blazers）
+	ESPNName   string   // ESPN 傷兵頁面的隊名
+	Titan007ID int      // titan007 的球隊 ID
+	NameTW     string   // 繁體中文隊名（台灣慣用）
+	NameCN     string   // 簡體中文隊名（titan007 使用）
+	NameEN     string   // 英文全名
+	Aliases    []string // 其他寫法（舊隊名、titan007 英文名等）
+}
+
+// FullName NBA API 的完整隊名（City + Nickname）
+func (t Team) FullName() string {
+	return t.City + " " + t.Nickname
+}
+
+// all 30 支球隊
+var all = []Team{
+	{ID: 1610612737, Tricode: "ATL", City: "Atlanta", Nickname: "Hawks", ESPNName: "Atlanta Hawks", Titan007ID: 13, NameTW: "老鷹", NameCN: "老鹰", NameEN: "Atlanta Hawks"},
+	{ID: 1610612738, Tricode: "BOS", City: "Boston", Nickname: "Celtics", ESPNName: "Boston Celtics", Titan007ID: 2, NameTW: "提克", NameCN: "凯尔特人", NameEN: "Boston Celtics", Aliases: []string{"塞爾提克"}},
+	{ID: 1610612751, Tricode: "BKN", City: "Brooklyn", Nickname: "Nets", ESPNName: "Brooklyn Nets", Titan007ID: 4, NameTW: "籃網", NameCN: "篮网", NameEN: "Brooklyn Nets", Aliases: []string{"BRK", "New Jersey Nets"}},
+	{ID: 1610612766, Tricode: "CHA", City: "Charlotte", Nickname: "Hornets", ESPNName: "Charlotte Hornets", Titan007ID: 30, NameTW: "黃蜂", NameCN: "黄蜂", NameEN: "Charlotte Hornets", Aliases: []string{"CHO"}},
+	{ID: 1610612741, Tricode: "CHI", City: "Chicago", Nickname: "Bulls", ESPNName: "Chicago Bulls", Titan007ID: 14, NameTW: "公牛", NameCN: "公牛", NameEN: "Chicago Bulls"},
+	{ID: 1610612739, Tricode: "CLE", City: "Cleveland", Nickname: "Cavaliers", ESPNName: "Cleveland Cavaliers", Titan007ID: 16, NameTW: "騎士", NameCN: "骑士", NameEN: "Cleveland Cavaliers"},
+	{ID: 1610612742, Tricode: "DAL", City: "Dallas", Nickname: "Mavericks", ESPNName: "Dallas Mavericks", Titan007ID: 17, NameTW: "小牛", NameCN: "独行侠", NameEN: "Dallas Mavericks", Aliases: []string{"獨行俠"}},
+	{ID: 1610612743, Tricode: "DEN", City: "Denver", Nickname: "Nuggets", ESPNName: "Denver Nuggets", Titan007ID: 23, NameTW: "金塊", NameCN: "掘金", NameEN: "Denver Nuggets"},
+	{ID: 1610612765, Tricode: "DET", City: "Detroit", Nickname: "Pistons", ESPNName: "Detroit Pistons", Titan007ID: 9, NameTW: "活塞", NameCN: "活塞", NameEN: "Detroit Pistons"},
+	{ID: 1610612744, Tricode: "GSW", City: "Golden State", Nickname: "Warriors", ESPNName: "Golden State Warriors", Titan007ID: 27, NameTW: "勇士", NameCN: "勇士", NameEN: "Golden State Warriors", Aliases: []string{"GS"}},
+	{ID: 1610612745, Tricode: "HOU", City: "Houston", Nickname: "Rockets", ESPNName: "Houston Rockets", Titan007ID: 21, NameTW: "火箭", NameCN: "火箭", NameEN: "Houston Rockets"},
+	{ID: 1610612754, Tricode: "IND", City: "Indiana", Nickname: "Pacers", ESPNName: "Indiana Pacers", Titan007ID: 10, NameTW: "溜馬", NameCN: "步行者", NameEN: "Indiana Pacers"},
+	{ID: 1610612746, Tricode: "LAC", City: "LA", Nickname: "Clippers", ESPNName: "LA Clippers", Titan007ID: 29, NameTW: "快艇", NameCN: "快船", NameEN: "LA Clippers", Aliases: []string{"Los Angeles Clippers", "L.A. Clippers"}},
+	{ID: 1610612747, Tricode: "LAL", City: "Los Angeles", Nickname: "Lakers", ESPNName: "Los Angeles Lakers", Titan007ID: 1, NameTW: "湖人", NameCN: "湖人", NameEN: "Los Angeles Lakers", Aliases: []string{"LA Lakers", "L.A. Lakers"}},
+	{ID: 1610612763, Tricode: "MEM", City: "Memphis", Nickname: "Grizzlies", ESPNName: "Memphis Grizzlies", Titan007ID: 22, NameTW: "灰熊", NameCN: "灰熊", NameEN: "Memphis Grizzlies"},
+	{ID: 1610612748, Tricode: "MIA", City: "Miami", Nickname: "Heat", ESPNName: "Miami Heat", Titan007ID: 3, NameTW: "熱火", NameCN: "热火", NameEN: "Miami Heat"},
+	{ID: 1610612749, Tricode: "MIL", City: "Milwaukee", Nickname: "Bucks", ESPNName: "Milwaukee Bucks", Titan007ID: 12, NameTW: "公鹿", NameCN: "雄鹿", NameEN: "Milwaukee Bucks"},
+	{ID: 1610612750, Tricode: "MIN", City: "Minnesota", Nickname: "Timberwolves", ESPNName: "Minnesota Timberwolves", Titan007ID: 19, NameTW: "灰狼", NameCN: "森林狼", NameEN: "Minnesota Timberwolves"},
+	{ID: 1610612740, Tricode: "NOP", City: "New Orleans", Nickname: "Pelicans", ESPNName: "New Orleans Pelicans", Titan007ID: 11, NameTW: "鵜鶘", NameCN: "鹈鹕", NameEN: "New Orleans Pelicans", Aliases: []string{"NO"}},
+	{ID: 1610612752, Tricode: "NYK", City: "New York", Nickname: "Knicks", ESPNName: "New York Knicks", Titan007ID: 5, NameTW: "尼克", NameCN: "尼克斯", NameEN: "New York Knicks", Aliases: []string{"NY"}},
+	{ID: 1610612760, Tricode: "OKC", City: "Oklahoma City", Nickname: "Thunder", ESPNName: "Oklahoma City Thunder", Titan007ID: 28, NameTW: "雷霆", NameCN: "雷霆", NameEN: "Oklahoma City Thunder"},
+	{ID: 1610612753, Tricode: "ORL", City: "Orlando", Nickname: "Magic", ESPNName: "Orlando Magic", Titan007ID: 6, NameTW: "魔術", NameCN: "魔术", NameEN: "Orlando Magic"},
+	{ID: 1610612755, Tricode: "PHI", City: "Philadelphia", Nickname: "76ers", ESPNName: "Philadelphia 76ers", Titan007ID: 7, NameTW: "76人", NameCN: "76人", NameEN: "Philadelphia 76ers", Aliases: []string{"Sixers"}},
+	{ID: 1610612756, Tricode: "PHX", City: "Phoenix", Nickname: "Suns", ESPNName: "Phoenix Suns", Titan007ID: 26, NameTW: "太陽", NameCN: "太阳", NameEN: "Phoenix Suns", Aliases: []string{"PHO"}},
+	{ID: 1610612757, Tricode: "POR", City: "Portland", Nickname: "Trail Blazers", ESPNName: "Portland Trail Blazers", Titan007ID: 25, NameTW: "拓荒", NameCN: "开拓者", NameEN: "Portland Trail Blazers", Aliases: []string{"Portland Trail_blazers", "Portland Trailblazers", "拓荒者"}},
+	{ID: 1610612758, Tricode: "SAC", City: "Sacramento", Nickname: "Kings", ESPNName: "Sacramento Kings", Titan007ID: 24, NameTW: "國王", NameCN: "国王", NameEN: "Sacramento Kings"},
+	{ID: 1610612759, Tricode: "SAS", City: "San Antonio", Nickname: "Spurs", ESPNName: "San Antonio Spurs", Titan007ID: 18, NameTW: "馬刺", NameCN: "马刺", NameEN: "San Antonio Spurs", Aliases: []string{"SA"}},
+	{ID: 1610612761, Tricode: "TOR", City: "Toronto", Nickname: "Raptors", ESPNName: "Toronto Raptors", Titan007ID: 15, NameTW: "暴龍", NameCN: "猛龙", NameEN: "Toronto Raptors"},
+	{ID: 1610612762, Tricode: "UTA", City: "Utah", Nickname: "Jazz", ESPNName: "Utah Jazz", Titan007ID: 20, NameTW: "爵士", NameCN: "爵士", NameEN: "Utah Jazz", Aliases: []string{"UTAH"}},
+	{ID: 1610612764, Tricode: "WAS", City: "Washington", Nickname: "Wizards", ESPNName: "Washington Wizards", Titan007ID: 8, NameTW: "巫師", NameCN: "奇才", NameEN: "Washington Wizards", Aliases: []string{"WSH"}},
+}
+
+// 查詢索引（套件初始化時建立）
+var (
+	byID       = make(map[int]int)
+	byTitan007 = make(map[int]int)
+	byAlias    = make(map[string]int)
+)
+
+func init() {
+	for i, t := range all {
+		byID[t.ID] = i
+		byTitan007[t.Titan007ID] = i
+
+		names := []string{t.Tricode, t.Nickname, t.FullName(), t.ESPNName, t.NameTW, t.NameCN, t.NameEN}
+		names = append(names, t.Aliases...)
+		for _, name := range names {
+			byAlias[normalize(name)] = i
+		}
+	}
+}
+
+// normalize 正規化隊名：忽略大小寫、底線、句點與多餘空白
+func normalize(name string) string {
+	name = strings.ToLower(name)
+	name = strings.NewReplacer("_", " ", ".", "").Replace(name)
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// All 所有球隊（依英文隊名排序）
+func All() []Team {
+	list := append([]Team(nil), all...)
+	sort.Slice(list, func(i, j int) bool { return list[i].NameEN < list[j].NameEN })
+	return list
+}
+
+// ByID 以 NBA teamId 查詢
+func ByID(id int) (Team, bool) {
+	i, ok := byID[id]
+	if !ok {
+		return Team{}, false
+	}
+	return all[i], true
+}
+
+// ByTitan007ID 以 titan007 球隊 ID 查詢
+func ByTitan007ID(id int) (Team, bool) {
+	i, ok := byTitan007[id]
+	if !ok {
+		return Team{}, false
+	}
+	return all[i], true
+}
+
+// Lookup 以任一名稱查詢（三碼縮寫、隊名、全名、ESPN/titan007 寫法、中文隊名）
+func Lookup(name string) (Team, bool) {
+	i, ok := byAlias[normalize(name)]
+	if !ok {
+		return Team{}, false
+	}
+	return all[i], true
+}
+
+// NameTW 取得球隊的繁體中文隊名，已知的海外球隊使用其中文隊名，其他隊伍回傳 fallback
+func NameTW(id int, fallback string) string {
+	if t, ok := ByID(id); ok {
+		return t.NameTW
+	}
+	if t, ok := Lookup(fallback); ok {
+		return t.NameTW
+	}
+	if g, ok := LookupGuest(fallback); ok {
+		return g.NameTW
+	}
+	return fallback
+}
+
+// Find 在以隊名為鍵的資料（傷兵名單、過盤資料）中找出指定球隊的值，隊名寫法不同也能對應
+func Find[V any](m map[string]V, id int) (V, bool) {
+	var zero V
+	t, ok := ByID(id)
+	if !ok {
+		return zero, false
+	}
+	for _, name := range []string{t.ESPNName, t.NameEN, t.FullName()} {
+		if v, ok := m[name]; ok {
+			return v, true
+		}
+	}
+	for name, v := range m {
+		if other, ok := Lookup(name); ok && other.ID == id {
+			return v, true
+		}
+	}
+	return zero, false
+}
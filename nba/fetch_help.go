@@ -11,6 +11,9 @@ import (
 	"strings"
 	"time"
 
+	nbaseason "nba-scanner/internal/season"
+	"nba-scanner/internal/teams"
+
 	"github.com/PuerkitoBio/goquery"
 )
 
@@ -19,12 +22,8 @@ func getInjuryFetch(searchTeam string) []map[string]string {
 
 	var results []map[string]string // 切片，存儲每個球員的信息
 
-	if searchTeam == "Los Angeles Clippers" {
-		searchTeam = "LA Clippers"
-	}
-
-	if searchTeam == "Portland Trail_blazers" {
-		searchTeam = "Portland Trail Blazers"
+	if team, ok := teams.Lookup(searchTeam); ok {
+		searchTeam = team.ESPNName
 	}
 
 	res, err := http.Get("https://www.espn.com/nba/injuries")
@@ -90,22 +89,8 @@ func getDishFetch(searchTeam string) []string {
 		season string
 	)
 
-	if searchTeam == "LA Clippers" {
-		searchTeam = "Los Angeles Clippers"
-	}
-
-	// if searchTeam == "Portland Trail Blazers" {
-	// 	searchTeam = "Portland Trail_blazers"
-	// }
-
 	year := time.Now()
-	month := time.Now().Format("01")
-	monthR, _ := strconv.ParseInt(month, 10, 64)
-	if monthR < 7 {
-		season = year.AddDate(-1, 0, 0).Format("06") + "-" + year.Format("06")
-	} else {
-		season = year.Format("06") + "-" + year.AddDate(1, 0, 0).Format("06")
-	}
+	season = nbaseason.Current().LetGoal()
 	yearR := year.Format("2006010215")
 
 	// http://nba.titan007.com/cn/LetGoal.aspx?SclassID=1&matchSeason=2022-2023
@@ -143,7 +128,7 @@ func getDishFetch(searchTeam string) []string {
 		matchR := match.FindAllStringSubmatchIndex(TeamData, -1)
 		TeamNumber, _ := strconv.ParseInt(TeamData[1:matchR[0][0]], 10, 64) //TeamData[1:TeamData[matchR[0][0]-1]]
 
-		if searchTeam == TeamData[matchR[2][1]+1:matchR[3][0]-1] {
+		if sameTeam(searchTeam, TeamData[matchR[2][1]+1:matchR[3][0]-1]) {
 			matchMap[TeamNumber] = TeamData[matchR[2][1]+1 : matchR[3][0]-1]
 		}
 	}
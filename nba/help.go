@@ -10,6 +10,8 @@ import (
 	"strings"
 	"time"
 
+	"nba-scanner/internal/teams"
+
 	"github.com/PuerkitoBio/goquery"
 )
 
@@ -17,36 +19,9 @@ import (
 func TeamInit() map[string]string {
 
 	teamMap := make(map[string]string)
-	teamMap["Atlanta Hawks"] = "老鷹"
-	teamMap["Boston Celtics"] = "提克"
-	teamMap["Brooklyn Nets"] = "籃網"
-	teamMap["Cleveland Cavaliers"] = "騎士"
-	teamMap["Charlotte Hornets"] = "黃蜂"
-	teamMap["Chicago Bulls"] = "公牛"
-	teamMap["Dallas Mavericks"] = "小牛"
-	teamMap["Denver Nuggets"] = "金塊"
-	teamMap["Detroit Pistons"] = "活塞"
-	teamMap["Golden State Warriors"] = "勇士"
-	teamMap["Houston Rockets"] = "火箭"
-	teamMap["Indiana Pacers"] = "溜馬"
-	teamMap["Los Angeles Lakers"] = "湖人"
-	teamMap["LA Clippers"] = "快艇"
-	teamMap["Memphis Grizzlies"] = "灰熊"
-	teamMap["Miami Heat"] = "熱火"
-	teamMap["Milwaukee Bucks"] = "公鹿"
-	teamMap["Minnesota Timberwolves"] = "灰狼"
-	teamMap["New Orleans Pelicans"] = "鵜鶘"
-	teamMap["New York Knicks"] = "尼克"
-	teamMap["Oklahoma City Thunder"] = "雷霆"
-	teamMap["Orlando Magic"] = "魔術"
-	teamMap["Philadelphia 76ers"] = "76人"
-	teamMap["Phoenix Suns"] = "太陽"
-	teamMap["Portland Trail Blazers"] = "拓荒"
-	teamMap["Sacramento Kings"] = "國王"
-	teamMap["San Antonio Spurs"] = "馬刺"
-	teamMap["Toronto Raptors"] = "暴龍"
-	teamMap["Utah Jazz"] = "爵士"
-	teamMap["Washington Wizards"] = "巫師"
+	for _, team := range teams.All() {
+		teamMap[team.FullName()] = team.NameTW
+	}
 
 	return teamMap
 }
@@ -54,16 +29,8 @@ func TeamInit() map[string]string {
 // Get the injuriers of the nba team
 func getInjury(searchTeam string) (result []string) {
 
-	if searchTeam == "Los Angeles Clippers" {
-		searchTeam = "LA Clippers"
-	}
-
-	if searchTeam == "Los Angeles Clippers" {
-		searchTeam = "LA Clippers"
-	}
-
-	if searchTeam == "Portland Trail_blazers" {
-		searchTeam = "Portland Trail Blazers"
+	if team, ok := teams.Lookup(searchTeam); ok {
+		searchTeam = team.ESPNName
 	}
 
 	res, err := http.Get("https://www.espn.com/nba/injuries")
@@ -170,7 +137,7 @@ func getDish(searchTeam string) map[string]string {
 		matchR := match.FindAllStringSubmatchIndex(TeamData, -1)
 		TeamNumber, _ := strconv.ParseInt(TeamData[1:matchR[0][0]], 10, 64) //TeamData[1:TeamData[matchR[0][0]-1]]
 
-		if searchTeam == TeamData[matchR[2][1]+1:matchR[3][0]-1] {
+		if sameTeam(searchTeam, TeamData[matchR[2][1]+1:matchR[3][0]-1]) {
 			matchMap[TeamNumber] = TeamData[matchR[2][1]+1 : matchR[3][0]-1]
 		}
 	}
@@ -219,4 +186,12 @@ func formatDish(dish map[string]string, team string) string {
 	return "無可用數據"
 }
 
-
+// sameTeam 兩個隊名是否為同一支球隊（各網站寫法不同，例如 LA Clippers / Los Angeles Clippers）
+func sameTeam(a, b string) bool {
+	ta, okA := teams.Lookup(a)
+	tb, okB := teams.Lookup(b)
+	if okA && okB {
+		return ta.ID == tb.ID
+	}
+	return a == b
+}